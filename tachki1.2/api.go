@@ -0,0 +1,501 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiServer exposes the repository as JSON resources:
+//
+//	/api/categories          GET
+//	/api/owners[/{id}]       GET, POST, PUT, DELETE
+//	/api/cars[/{id}]         GET, POST, PUT, DELETE
+//	/api/brands[/{id}]       GET, PUT (logo only)
+//
+// row_version is sent as ETag; PUT requires a matching If-Match header,
+// DELETE checks it when present. PUT /api/cars/{id} keeps owner_id: a
+// different owner is 409, because a transfer must go to ownership_history.
+//
+// Every request must carry "Authorization: Bearer <token>". The token only
+// admits the client: all requests run as the database user of the server,
+// with that user's role, so clients cannot be told apart in audit_log.
+type apiServer struct {
+	repo    Repository
+	timeout time.Duration // ограничение времени запроса к базе данных
+	token   string
+}
+
+func newAPIServer(repo Repository, timeout time.Duration, token string) *apiServer {
+	return &apiServer{repo: repo, timeout: timeout, token: token}
+}
+
+// apiError carries the HTTP status for an error returned by a handler
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &apiError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+// --- JSON resources ---
+
+type ownerJSON struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Phone     string `json:"phone"`
+	Email     string `json:"email"`
+	Category  string `json:"category"`
+}
+
+type carJSON struct {
+	ID           int     `json:"id"`
+	OwnerID      int     `json:"owner_id"`
+	BrandID      int     `json:"brand_id"`
+	Model        string  `json:"model"`
+	Year         int     `json:"year"`
+	Color        string  `json:"color"`
+	VIN          string  `json:"vin"`
+	Price        float64 `json:"price"`
+	CurrentPrice float64 `json:"current_price"`
+}
+
+type brandJSON struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	ImageData []byte `json:"image_data"` // base64 PNG/JPEG
+}
+
+type categoryJSON struct {
+	ID   int    `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+func toOwnerJSON(o Owner) ownerJSON {
+	return ownerJSON{o.ID, o.FirstName, o.LastName, o.Phone, o.Email, o.Category}
+}
+
+func toCarJSON(c Car) carJSON {
+	return carJSON{c.ID, c.OwnerID, c.BrandID, c.Model, c.Year, c.Color, c.VIN, c.Price, c.CurrentPrice}
+}
+
+// --- Routing ---
+
+func (s *apiServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="tachki"`)
+		writeError(w, &apiError{http.StatusUnauthorized, "требуется заголовок Authorization: Bearer <токен>"})
+		return
+	}
+
+	// Запрос к базе прерывается по таймауту или при разрыве соединения клиентом
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	r = r.WithContext(ctx)
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api"), "/"), "/")
+
+	id := 0
+	if len(parts) == 2 {
+		var err error
+		id, err = strconv.Atoi(parts[1])
+		if err != nil {
+			writeError(w, badRequest("ID должен быть числом"))
+			return
+		}
+	} else if len(parts) != 1 {
+		writeError(w, &apiError{http.StatusNotFound, "ресурс не найден"})
+		return
+	}
+
+	var err error
+	switch parts[0] {
+	case "categories":
+		err = s.handleCategories(w, r, id)
+	case "owners":
+		err = s.handleOwners(w, r, id)
+	case "cars":
+		err = s.handleCars(w, r, id)
+	case "brands":
+		err = s.handleBrands(w, r, id)
+	default:
+		err = &apiError{http.StatusNotFound, "ресурс не найден"}
+	}
+
+	if err != nil {
+		writeError(w, err)
+	}
+}
+
+// authorized checks the bearer token in constant time
+func (s *apiServer) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && s.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers with the status of err. The text of unexpected errors
+// (database messages) is only logged: it may reveal the schema.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var apiErr *apiError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.status
+	case errors.Is(err, sql.ErrNoRows):
+		status = http.StatusNotFound
+		err = errors.New("запись не найдена")
+	case errors.Is(err, errConcurrentUpdate):
+		status = http.StatusPreconditionFailed
+	case errors.Is(err, errPermissionDenied):
+		status = http.StatusForbidden
+	case errors.Is(err, errOwnerChange):
+		status = http.StatusConflict
+	case errors.Is(err, errDuplicate):
+		status = http.StatusConflict
+		err = errDuplicate // сообщение базы данных раскрывает схему
+	case errors.Is(err, errInvalidReference):
+		status = http.StatusBadRequest
+		err = errInvalidReference
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+		err = errors.New("превышено время ожидания ответа базы данных")
+	default:
+		log.Printf("API: %v", err)
+		err = errors.New("внутренняя ошибка сервера")
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func methodNotAllowed(allowed string) error {
+	return &apiError{http.StatusMethodNotAllowed, "метод не поддерживается, допустимы: " + allowed}
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return badRequest("некорректный JSON: %v", err)
+	}
+	return nil
+}
+
+// --- Optimistic locking via ETag / If-Match ---
+
+func etag(rowVersion []byte) string {
+	return `"` + hex.EncodeToString(rowVersion) + `"`
+}
+
+// ifMatch returns the row_version from If-Match, or nil when the header is absent
+func ifMatch(r *http.Request) ([]byte, error) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return nil, nil
+	}
+	version, err := hex.DecodeString(strings.Trim(strings.TrimPrefix(header, "W/"), `"`))
+	if err != nil {
+		return nil, badRequest("некорректный If-Match")
+	}
+	return version, nil
+}
+
+// requireIfMatch is used by PUT: updating without a known version is not allowed
+func requireIfMatch(r *http.Request) ([]byte, error) {
+	version, err := ifMatch(r)
+	if err == nil && version == nil {
+		err = &apiError{http.StatusPreconditionRequired, "требуется заголовок If-Match с ETag записи"}
+	}
+	return version, err
+}
+
+// --- Handlers ---
+
+func (s *apiServer) handleCategories(w http.ResponseWriter, r *http.Request, id int) error {
+	if id != 0 {
+		return &apiError{http.StatusNotFound, "ресурс не найден"}
+	}
+	if r.Method != http.MethodGet {
+		return methodNotAllowed("GET /api/categories")
+	}
+
+	categories, err := s.repo.GetDriverCategories(r.Context())
+	if err != nil {
+		return err
+	}
+	result := make([]categoryJSON, 0, len(categories))
+	for _, cat := range categories {
+		result = append(result, categoryJSON{cat.ID, cat.Code, cat.Name})
+	}
+	writeJSON(w, http.StatusOK, result)
+	return nil
+}
+
+func validateOwnerJSON(o ownerJSON) error {
+	for _, err := range []error{validateFirstName(o.FirstName), validateLastName(o.LastName), validatePhone(o.Phone), validateEmail(o.Email)} {
+		if err != nil {
+			return badRequest("%v", err)
+		}
+	}
+	return nil
+}
+
+func (s *apiServer) handleOwners(w http.ResponseWriter, r *http.Request, id int) error {
+	switch {
+	case r.Method == http.MethodGet && id == 0:
+		owners, err := s.repo.GetOwners(r.Context())
+		if err != nil {
+			return err
+		}
+		result := make([]ownerJSON, 0, len(owners))
+		for _, o := range owners {
+			result = append(result, toOwnerJSON(o))
+		}
+		writeJSON(w, http.StatusOK, result)
+
+	case r.Method == http.MethodGet:
+		owner, err := s.repo.GetOwnerByID(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("ETag", etag(owner.RowVersion))
+		writeJSON(w, http.StatusOK, toOwnerJSON(*owner))
+
+	case r.Method == http.MethodPost && id == 0:
+		var in ownerJSON
+		if err := readJSON(r, &in); err != nil {
+			return err
+		}
+		if err := validateOwnerJSON(in); err != nil {
+			return err
+		}
+		categoryID, err := findCategoryID(r.Context(), s.repo, in.Category)
+		if err != nil {
+			return badRequest("%v", err)
+		}
+		newID, err := s.repo.AddOwner(r.Context(), in.FirstName, in.LastName, in.Phone, in.Email, categoryID)
+		if err != nil {
+			return err
+		}
+		return s.respondOwner(r.Context(), w, http.StatusCreated, newID)
+
+	case r.Method == http.MethodPut && id != 0:
+		version, err := requireIfMatch(r)
+		if err != nil {
+			return err
+		}
+		var in ownerJSON
+		if err := readJSON(r, &in); err != nil {
+			return err
+		}
+		if err := validateOwnerJSON(in); err != nil {
+			return err
+		}
+		categoryID, err := findCategoryID(r.Context(), s.repo, in.Category)
+		if err != nil {
+			return badRequest("%v", err)
+		}
+		if err := s.repo.UpdateOwner(r.Context(), id, in.FirstName, in.LastName, in.Phone, in.Email, categoryID, version); err != nil {
+			return err
+		}
+		return s.respondOwner(r.Context(), w, http.StatusOK, id)
+
+	case r.Method == http.MethodDelete && id != 0:
+		// row_version проверяется в самом UPDATE, а не отдельным чтением
+		version, err := ifMatch(r)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.DeleteOwner(r.Context(), id, version); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		return methodNotAllowed("GET, POST /api/owners; GET, PUT, DELETE /api/owners/{id}")
+	}
+	return nil
+}
+
+// respondOwner re-reads the owner so the response carries the new ETag
+func (s *apiServer) respondOwner(ctx context.Context, w http.ResponseWriter, status, id int) error {
+	owner, err := s.repo.GetOwnerByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("ETag", etag(owner.RowVersion))
+	if status == http.StatusCreated {
+		w.Header().Set("Location", fmt.Sprintf("/api/owners/%d", id))
+	}
+	writeJSON(w, status, toOwnerJSON(*owner))
+	return nil
+}
+
+func validateCarJSON(c carJSON) error {
+	year := ""
+	if c.Year != 0 {
+		year = strconv.Itoa(c.Year)
+	}
+	for _, err := range []error{validateModel(c.Model), validateYear(year), validateVIN(c.VIN)} {
+		if err != nil {
+			return badRequest("%v", err)
+		}
+	}
+	if c.OwnerID == 0 || c.BrandID == 0 {
+		return badRequest("owner_id и brand_id обязательны")
+	}
+	if c.Price < 0 {
+		return badRequest("цена не может быть отрицательной")
+	}
+	return nil
+}
+
+func (s *apiServer) handleCars(w http.ResponseWriter, r *http.Request, id int) error {
+	switch {
+	case r.Method == http.MethodGet && id == 0:
+		cars, err := s.repo.GetCars(r.Context())
+		if err != nil {
+			return err
+		}
+		result := make([]carJSON, 0, len(cars))
+		for _, c := range cars {
+			result = append(result, toCarJSON(c))
+		}
+		writeJSON(w, http.StatusOK, result)
+
+	case r.Method == http.MethodGet:
+		car, err := s.repo.GetCarByID(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("ETag", etag(car.RowVersion))
+		writeJSON(w, http.StatusOK, toCarJSON(*car))
+
+	case r.Method == http.MethodPost && id == 0:
+		var in carJSON
+		if err := readJSON(r, &in); err != nil {
+			return err
+		}
+		if err := validateCarJSON(in); err != nil {
+			return err
+		}
+		newID, err := s.repo.AddCar(r.Context(), in.OwnerID, in.BrandID, in.Model, in.Year, in.Color, in.VIN, in.Price)
+		if err != nil {
+			return err
+		}
+		return s.respondCar(r.Context(), w, http.StatusCreated, newID)
+
+	case r.Method == http.MethodPut && id != 0:
+		version, err := requireIfMatch(r)
+		if err != nil {
+			return err
+		}
+		var in carJSON
+		if err := readJSON(r, &in); err != nil {
+			return err
+		}
+		if err := validateCarJSON(in); err != nil {
+			return err
+		}
+		if err := s.repo.UpdateCar(r.Context(), id, in.OwnerID, in.BrandID, in.Model, in.Year, in.Color, in.VIN, in.Price, version); err != nil {
+			return err
+		}
+		return s.respondCar(r.Context(), w, http.StatusOK, id)
+
+	case r.Method == http.MethodDelete && id != 0:
+		version, err := ifMatch(r)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.DeleteCar(r.Context(), id, version); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		return methodNotAllowed("GET, POST /api/cars; GET, PUT, DELETE /api/cars/{id}")
+	}
+	return nil
+}
+
+// respondCar re-reads the car so the response carries the new ETag
+func (s *apiServer) respondCar(ctx context.Context, w http.ResponseWriter, status, id int) error {
+	car, err := s.repo.GetCarByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("ETag", etag(car.RowVersion))
+	if status == http.StatusCreated {
+		w.Header().Set("Location", fmt.Sprintf("/api/cars/%d", id))
+	}
+	writeJSON(w, status, toCarJSON(*car))
+	return nil
+}
+
+func (s *apiServer) handleBrands(w http.ResponseWriter, r *http.Request, id int) error {
+	switch {
+	case r.Method == http.MethodGet && id == 0:
+		brands, err := s.repo.GetCarBrands(r.Context())
+		if err != nil {
+			return err
+		}
+		result := make([]brandJSON, 0, len(brands))
+		for _, b := range brands {
+			result = append(result, brandJSON{b.ID, b.Name, b.ImageData})
+		}
+		writeJSON(w, http.StatusOK, result)
+		return nil
+
+	case r.Method == http.MethodGet:
+		return s.respondBrand(r.Context(), w, id)
+
+	case r.Method == http.MethodPut && id != 0:
+		version, err := requireIfMatch(r)
+		if err != nil {
+			return err
+		}
+		var in brandJSON
+		if err := readJSON(r, &in); err != nil {
+			return err
+		}
+		if err := s.repo.UpdateBrandImage(r.Context(), id, in.ImageData, version); err != nil {
+			return err
+		}
+		return s.respondBrand(r.Context(), w, id)
+
+	default:
+		return methodNotAllowed("GET /api/brands; GET, PUT /api/brands/{id}")
+	}
+}
+
+// respondBrand re-reads the brand so the response carries the new ETag
+func (s *apiServer) respondBrand(ctx context.Context, w http.ResponseWriter, id int) error {
+	brand, err := s.repo.GetCarBrand(ctx, id)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("ETag", etag(brand.RowVersion))
+	writeJSON(w, http.StatusOK, brandJSON{brand.ID, brand.Name, brand.ImageData})
+	return nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testAPIToken = "secret"
+
+// apiRequest sends a request with the test token and optional If-Match
+func apiRequest(t *testing.T, server http.Handler, method, path, body, version string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+testAPIToken)
+	if version != "" {
+		r.Header.Set("If-Match", version)
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, r)
+	return w
+}
+
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("статус %d, ожидался %d: %s", w.Code, status, w.Body.String())
+	}
+}
+
+func TestAPIAuthorization(t *testing.T) {
+	server := newAPIServer(newMemoryRepository(), time.Second, testAPIToken)
+
+	for _, header := range []string{"", "Bearer wrong", testAPIToken} {
+		r := httptest.NewRequest(http.MethodGet, "/api/owners", nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, r)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: статус %d, ожидался 401", header, w.Code)
+		}
+	}
+
+	expectStatus(t, apiRequest(t, server, http.MethodGet, "/api/owners", "", ""), http.StatusOK)
+}
+
+// TestAPIOwnerETag walks an owner through POST, GET, PUT and DELETE with
+// stale and current ETags
+func TestAPIOwnerETag(t *testing.T) {
+	server := newAPIServer(newMemoryRepository(), time.Second, testAPIToken)
+	body := `{"first_name":"Иван","last_name":"Петров","category":"B"}`
+
+	w := apiRequest(t, server, http.MethodPost, "/api/owners", body, "")
+	expectStatus(t, w, http.StatusCreated)
+	created := w.Header().Get("ETag")
+	if created == "" || w.Header().Get("Location") != "/api/owners/1" {
+		t.Fatalf("заголовки ответа POST: %v", w.Header())
+	}
+
+	w = apiRequest(t, server, http.MethodGet, "/api/owners/1", "", "")
+	expectStatus(t, w, http.StatusOK)
+	if w.Header().Get("ETag") != created {
+		t.Errorf("ETag GET %s, ожидался %s", w.Header().Get("ETag"), created)
+	}
+
+	changed := `{"first_name":"Иван","last_name":"Сидоров","category":"B"}`
+	expectStatus(t, apiRequest(t, server, http.MethodPut, "/api/owners/1", changed, ""), http.StatusPreconditionRequired)
+	expectStatus(t, apiRequest(t, server, http.MethodPut, "/api/owners/1", changed, `"zz"`), http.StatusBadRequest)
+
+	w = apiRequest(t, server, http.MethodPut, "/api/owners/1", changed, created)
+	expectStatus(t, w, http.StatusOK)
+	updated := w.Header().Get("ETag")
+	if updated == created {
+		t.Error("ETag не изменился после PUT")
+	}
+	var owner ownerJSON
+	if err := json.Unmarshal(w.Body.Bytes(), &owner); err != nil || owner.LastName != "Сидоров" {
+		t.Errorf("ответ PUT: %s", w.Body.String())
+	}
+
+	expectStatus(t, apiRequest(t, server, http.MethodPut, "/api/owners/1", changed, created), http.StatusPreconditionFailed)
+	expectStatus(t, apiRequest(t, server, http.MethodDelete, "/api/owners/1", "", created), http.StatusPreconditionFailed)
+	expectStatus(t, apiRequest(t, server, http.MethodDelete, "/api/owners/1", "", updated), http.StatusNoContent)
+	expectStatus(t, apiRequest(t, server, http.MethodGet, "/api/owners/1", "", ""), http.StatusNotFound)
+}
+
+func TestAPICarDelete(t *testing.T) {
+	repo := newMemoryRepository()
+	server := newAPIServer(repo, time.Second, testAPIToken)
+	ctx := context.Background()
+
+	ownerID, err := repo.AddOwner(ctx, "Иван", "Петров", "", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	carID, err := repo.AddCar(ctx, ownerID, 1, "Camry", 2020, "", "", 25000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherID, err := repo.AddOwner(ctx, "Анна", "Смирнова", "", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	car, err := repo.GetCarByID(ctx, carID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := fmt.Sprintf(`{"owner_id":%d,"brand_id":1,"model":"Camry","year":2020,"price":25000}`, otherID)
+	expectStatus(t, apiRequest(t, server, http.MethodPut, "/api/cars/1", body, etag(car.RowVersion)), http.StatusConflict)
+
+	// Без If-Match автомобиль удаляется безусловно
+	expectStatus(t, apiRequest(t, server, http.MethodDelete, "/api/cars/1", "", ""), http.StatusNoContent)
+	if _, err := repo.GetCarByID(ctx, carID); err == nil {
+		t.Error("автомобиль не удалён")
+	}
+	expectStatus(t, apiRequest(t, server, http.MethodDelete, "/api/cars/1", "", ""), http.StatusNotFound)
+	expectStatus(t, apiRequest(t, server, http.MethodDelete, "/api/cars/99", "", ""), http.StatusNotFound)
+}
+
+// TestAPICarConstraints checks that data the schema rejects is answered with
+// a client error, not 500, by both the memory and SQLite repositories
+func TestAPICarConstraints(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		server := newAPIServer(repo, time.Second, testAPIToken)
+		ownerID, err := repo.AddOwner(context.Background(), "Иван", "Петров", "", "", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		car := func(owner, brand int, vin string, price float64) string {
+			return fmt.Sprintf(`{"owner_id":%d,"brand_id":%d,"model":"Camry","year":2020,"vin":%q,"price":%g}`, owner, brand, vin, price)
+		}
+
+		for _, tt := range []struct {
+			check  string
+			body   string
+			status int
+		}{
+			{"новый автомобиль", car(ownerID, 1, "JT2BF22K1W0123456", 25000), http.StatusCreated},
+			{"отрицательная цена", car(ownerID, 1, "JT2BF22K1W0654321", -1), http.StatusBadRequest},
+			{"повторный VIN", car(ownerID, 1, "JT2BF22K1W0123456", 25000), http.StatusConflict},
+			{"неизвестный владелец", car(99, 1, "JT2BF22K1W0111111", 25000), http.StatusBadRequest},
+			{"неизвестная марка", car(ownerID, 99, "JT2BF22K1W0222222", 25000), http.StatusBadRequest},
+		} {
+			w := apiRequest(t, server, http.MethodPost, "/api/cars", tt.body, "")
+			if w.Code != tt.status {
+				t.Errorf("%s, %s: статус %d, ожидался %d: %s", name, tt.check, w.Code, tt.status, w.Body.String())
+			}
+			if strings.Contains(w.Body.String(), "constraint") {
+				t.Errorf("%s, %s: ответ раскрывает сообщение базы данных: %s", name, tt.check, w.Body.String())
+			}
+		}
+	}
+}
+
+func TestAPIBrands(t *testing.T) {
+	server := newAPIServer(newMemoryRepository(), time.Second, testAPIToken)
+
+	w := apiRequest(t, server, http.MethodGet, "/api/brands/1", "", "")
+	expectStatus(t, w, http.StatusOK)
+	version := w.Header().Get("ETag")
+	if version == "" {
+		t.Fatal("GET /api/brands/1 без ETag")
+	}
+
+	logo := `{"id":1,"name":"Toyota","image_data":"iVBORw0KGgo="}`
+	expectStatus(t, apiRequest(t, server, http.MethodPut, "/api/brands/1", logo, ""), http.StatusPreconditionRequired)
+
+	w = apiRequest(t, server, http.MethodPut, "/api/brands/1", logo, version)
+	expectStatus(t, w, http.StatusOK)
+	if w.Header().Get("ETag") == version {
+		t.Error("ETag марки не изменился после PUT")
+	}
+	var brand brandJSON
+	if err := json.Unmarshal(w.Body.Bytes(), &brand); err != nil || len(brand.ImageData) == 0 {
+		t.Errorf("ответ PUT: %s", w.Body.String())
+	}
+
+	expectStatus(t, apiRequest(t, server, http.MethodPut, "/api/brands/1", logo, version), http.StatusPreconditionFailed)
+	expectStatus(t, apiRequest(t, server, http.MethodGet, "/api/brands/999", "", ""), http.StatusNotFound)
+}
+
+func TestAPIRouting(t *testing.T) {
+	server := newAPIServer(newMemoryRepository(), time.Second, testAPIToken)
+
+	expectStatus(t, apiRequest(t, server, http.MethodGet, "/api/categories", "", ""), http.StatusOK)
+	expectStatus(t, apiRequest(t, server, http.MethodGet, "/api/categories/1", "", ""), http.StatusNotFound)
+	expectStatus(t, apiRequest(t, server, http.MethodPost, "/api/categories", "{}", ""), http.StatusMethodNotAllowed)
+	expectStatus(t, apiRequest(t, server, http.MethodGet, "/api/garages", "", ""), http.StatusNotFound)
+	expectStatus(t, apiRequest(t, server, http.MethodGet, "/api/owners/x", "", ""), http.StatusBadRequest)
+}
+
+// failingRepository fails every category query with a database message
+type failingRepository struct {
+	Repository
+}
+
+func (failingRepository) GetDriverCategories(ctx context.Context) ([]DriverCategory, error) {
+	return nil, errors.New("Invalid object name 'dbo.driver_categories'")
+}
+
+func TestAPIInternalErrorHidden(t *testing.T) {
+	server := newAPIServer(failingRepository{newMemoryRepository()}, time.Second, testAPIToken)
+
+	w := apiRequest(t, server, http.MethodGet, "/api/categories", "", "")
+	expectStatus(t, w, http.StatusInternalServerError)
+	if strings.Contains(w.Body.String(), "dbo") {
+		t.Errorf("текст ошибки базы данных в ответе: %s", w.Body.String())
+	}
+}
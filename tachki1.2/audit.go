@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"os/user"
+	"sort"
+	"time"
+)
+
+// Audit operations stored in audit_log.operation
+const (
+	auditInsert = "INSERT"
+	auditUpdate = "UPDATE"
+	auditDelete = "DELETE"
+)
+
+// auditedTable lists the columns of a table saved in audit_log
+type auditedTable struct {
+	key     string
+	columns []string
+}
+
+// auditedTables are the tables whose writes are logged.
+// row_version is left out: it changes with every update anyway.
+var auditedTables = map[string]auditedTable{
+	"owners":     {"owner_id", []string{"owner_id", "first_name", "last_name", "phone", "email", "license_category_id", "deleted_at"}},
+	"cars":       {"car_id", []string{"car_id", "owner_id", "brand_id", "model", "year", "color", "vin_code", "price", "deleted_at"}},
+	"car_brands": {"brand_id", []string{"brand_id", "brand_name", "image_data"}},
+}
+
+// auditValue turns a column value into the text kept in audit_log.
+// Images are replaced by their size and checksum.
+func auditValue(column string, value interface{}) string {
+	if data, ok := value.([]byte); ok && column == "image_data" {
+		if len(data) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("<%d байт, crc32 %08x>", len(data), crc32.ChecksumIEEE(data))
+	}
+	if t, ok := value.(time.Time); ok && column == "deleted_at" {
+		return t.Format("2006-01-02 15:04:05")
+	}
+	return formatCellValue(value)
+}
+
+// auditOperation compares two states of a record; before is nil for a new
+// record, after is nil for a deleted one. ok is false when nothing changed.
+func auditOperation(before, after map[string]string) (operation string, ok bool) {
+	switch {
+	case before == nil && after == nil:
+		return "", false
+	case before == nil:
+		return auditInsert, true
+	case after == nil:
+		return auditDelete, true
+	}
+
+	for column, value := range after {
+		if before[column] != value {
+			return auditUpdate, true
+		}
+	}
+	return "", false
+}
+
+// auditJSON encodes the record state for old_values/new_values (NULL for nil)
+func auditJSON(values map[string]string) (interface{}, error) {
+	if values == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// parseAuditJSON decodes old_values/new_values
+func parseAuditJSON(text string) (map[string]string, error) {
+	if text == "" {
+		return nil, nil
+	}
+	var values map[string]string
+	if err := json.Unmarshal([]byte(text), &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// parseAuditTime reads audit_log.changed_at (time.Time or "2006-01-02 15:04:05" text)
+func parseAuditTime(value interface{}) time.Time {
+	switch v := value.(type) {
+	case time.Time:
+		return v
+	case []byte:
+		return parseAuditTime(string(v))
+	case string:
+		for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339Nano} {
+			if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// sortedKeys returns the record IDs in ascending order, so entries are logged deterministically
+func sortedKeys(ids map[int]bool) []int {
+	keys := make([]int, 0, len(ids))
+	for id := range ids {
+		keys = append(keys, id)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// osUserName is the audit user for backends without database logins
+func osUserName() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
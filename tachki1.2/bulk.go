@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// bulkTables are the View tab tables that support row selection and bulk actions
+var bulkTables = map[string]bool{"owners": true, "cars": true}
+
+// rowSelection keeps the record IDs selected in the View tab.
+// IDs survive sorting, filtering and paging.
+type rowSelection struct {
+	ids    map[int]bool
+	anchor int // строка последнего щелчка для Shift+щелчка; -1 — нет
+}
+
+func newRowSelection() *rowSelection {
+	return &rowSelection{ids: make(map[int]bool), anchor: -1}
+}
+
+func (s *rowSelection) has(id int) bool {
+	return s.ids[id]
+}
+
+func (s *rowSelection) count() int {
+	return len(s.ids)
+}
+
+// sorted returns the selected IDs in ascending order
+func (s *rowSelection) sorted() []int {
+	return sortedKeys(s.ids)
+}
+
+func (s *rowSelection) clear() {
+	s.ids = make(map[int]bool)
+	s.anchor = -1
+}
+
+// click toggles the row; with extend it selects every loaded row from the
+// previous click to this one
+func (s *rowSelection) click(pager *tablePager, row int, extend bool) {
+	if extend && s.anchor >= 0 {
+		from, to := s.anchor, row
+		if from > to {
+			from, to = to, from
+		}
+		for i := from; i <= to; i++ {
+			if id, ok := pagerRowID(pager, i); ok {
+				s.ids[id] = true
+			}
+		}
+		s.anchor = row
+		return
+	}
+
+	if id, ok := pagerRowID(pager, row); ok {
+		if s.ids[id] {
+			delete(s.ids, id)
+		} else {
+			s.ids[id] = true
+		}
+		s.anchor = row
+	}
+}
+
+// pagerRowID returns the ID (first column) of a loaded row
+func pagerRowID(pager *tablePager, row int) (int, bool) {
+	if pager == nil {
+		return 0, false
+	}
+	values, ok := pager.row(row)
+	if !ok || len(values) == 0 {
+		return 0, false
+	}
+	id, ok := cellNumber(values[0])
+	return int(id), ok
+}
+
+// shiftPressed reports whether Shift is held (desktop drivers only)
+func shiftPressed() bool {
+	if drv, ok := fyne.CurrentApp().Driver().(desktop.Driver); ok {
+		return drv.CurrentKeyModifiers()&fyne.KeyModifierShift != 0
+	}
+	return false
+}
+
+// confirmBulkDelete moves the selected owners or cars to the recycle bin in
+// one transaction and shows how many rows were affected
+func (d *DatabaseApp) confirmBulkDelete(table string, ids []int, onDone func()) {
+	if table != "owners" {
+		d.showBulkDeleteConfirm(table, ids, fmt.Sprintf("Переместить в корзину выбранные автомобили (%d)?", len(ids)), onDone)
+		return
+	}
+
+	// Автомобили выбранных владельцев считаются одним запросом
+	cars := 0
+	d.runInBackground("Подсчёт автомобилей", func(ctx context.Context) error {
+		all, err := d.repository().GetCars(ctx)
+		if err != nil {
+			return err
+		}
+		selected := make(map[int]bool, len(ids))
+		for _, id := range ids {
+			selected[id] = true
+		}
+		for _, car := range all {
+			if selected[car.OwnerID] {
+				cars++
+			}
+		}
+		return nil
+	}, func(err error) {
+		if err != nil {
+			d.showMessage("Ошибка", fmt.Sprintf("Ошибка получения автомобилей владельцев: %v", err))
+			return
+		}
+		d.showBulkDeleteConfirm(table, ids, fmt.Sprintf("Переместить в корзину выбранных владельцев (%d)?\n"+
+			"Вместе с ними в корзину будут перемещены их автомобили (%d).", len(ids), cars), onDone)
+	})
+}
+
+func (d *DatabaseApp) showBulkDeleteConfirm(table string, ids []int, message string, onDone func()) {
+	dialog.ShowConfirm("Массовое удаление", message, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		var result BulkResult
+		d.runWithProgress("Массовое удаление", func(ctx context.Context) error {
+			var err error
+			result, err = d.bulkDelete(ctx, table, ids)
+			return err
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Удаление отменено, ни одна запись не изменена: %v", err))
+				return
+			}
+			d.showMessage("Успех", "Перемещено в корзину:\n"+bulkSummary(result))
+			onDone()
+		})
+	}, d.window)
+}
+
+// bulkSummary lists the non-zero counts of a bulk operation
+func bulkSummary(result BulkResult) string {
+	var lines []string
+	if result.Owners > 0 {
+		lines = append(lines, fmt.Sprintf("владельцев — %d", result.Owners))
+	}
+	if result.Cars > 0 || len(lines) == 0 {
+		lines = append(lines, fmt.Sprintf("автомобилей — %d", result.Cars))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// showBulkEditCarsDialog changes owner, brand and/or color of the selected cars in one transaction
+func (d *DatabaseApp) showBulkEditCarsDialog(ids []int, onDone func()) {
+	var owners []Owner
+	var brands []CarBrand
+	d.runInBackground("Загрузка справочников", func(ctx context.Context) error {
+		var err error
+		if owners, err = d.repository().GetOwners(ctx); err != nil {
+			return fmt.Errorf("Ошибка получения владельцев: %w", err)
+		}
+		if brands, err = d.repository().GetCarBrands(ctx); err != nil {
+			return fmt.Errorf("Ошибка получения марок: %w", err)
+		}
+		return nil
+	}, func(err error) {
+		if err != nil {
+			d.showMessage("Ошибка", err.Error())
+			return
+		}
+		d.showBulkEditCarsForm(ids, owners, brands, onDone)
+	})
+}
+
+func (d *DatabaseApp) showBulkEditCarsForm(ids []int, owners []Owner, brands []CarBrand, onDone func()) {
+	const keep = "Не менять"
+
+	ownerOptions := []string{keep}
+	ownerByOption := make(map[string]int, len(owners))
+	for _, owner := range owners {
+		option := fmt.Sprintf("%d: %s %s", owner.ID, owner.FirstName, owner.LastName)
+		ownerOptions = append(ownerOptions, option)
+		ownerByOption[option] = owner.ID
+	}
+	ownerSelect := widget.NewSelect(ownerOptions, nil)
+	ownerSelect.SetSelected(keep)
+
+	brandOptions := []string{keep}
+	brandByOption := make(map[string]int, len(brands))
+	for _, brand := range brands {
+		brandOptions = append(brandOptions, brand.Name)
+		brandByOption[brand.Name] = brand.ID
+	}
+	brandSelect := widget.NewSelect(brandOptions, nil)
+	brandSelect.SetSelected(keep)
+
+	colorEntry := widget.NewEntry()
+	colorEntry.SetPlaceHolder(keep)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Выбрано автомобилей:", widget.NewLabel(fmt.Sprint(len(ids)))),
+		widget.NewFormItem("Новый владелец:", ownerSelect),
+		widget.NewFormItem("Новая марка:", brandSelect),
+		widget.NewFormItem("Новый цвет:", colorEntry),
+	}
+
+	form := dialog.NewForm("Изменение выбранных автомобилей", "Применить", "Отмена", items, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		var changes CarChanges
+		if id, found := ownerByOption[ownerSelect.Selected]; found {
+			changes.OwnerID = &id
+		}
+		if id, found := brandByOption[brandSelect.Selected]; found {
+			changes.BrandID = &id
+		}
+		if color := strings.TrimSpace(colorEntry.Text); color != "" {
+			changes.Color = &color
+		}
+
+		var updated int
+		d.runWithProgress("Изменение автомобилей", func(ctx context.Context) error {
+			var err error
+			updated, err = d.repository().BulkUpdateCars(ctx, ids, changes)
+			return err
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Изменения отменены, ни одна запись не изменена: %v", err))
+				return
+			}
+			d.showMessage("Успех", fmt.Sprintf("Изменено автомобилей: %d из %d", updated, len(ids)))
+			onDone()
+		})
+	}, d.window)
+	form.Resize(fyne.NewSize(500, 300))
+	form.Show()
+}
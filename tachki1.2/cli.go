@@ -0,0 +1,776 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// Headless mode: `tachki <group> <action> [flags]`.
+// The connection is taken from --backend/--dsn or TACHKI_BACKEND/TACHKI_DSN.
+
+const cliUsage = `Использование: tachki <команда> [параметры]
+
+Без команды запускается графический интерфейс.
+
+Команды:
+  owners list
+  owners show <id>
+  owners add --first <имя> --last <фамилия> --category <код|id> [--phone ..] [--email ..]
+  owners update <id> [--first ..] [--last ..] [--phone ..] [--email ..] [--category ..]
+  owners delete <id>               (в корзину)
+  owners restore <id>              (вместе с автомобилями, удалёнными с владельцем)
+  owners purge <id>                (навсегда, из корзины)
+  cars list
+  cars show <id>
+  cars add --owner <id> --brand <марка|id> --model <модель> [--year ..] [--color ..] [--vin ..] [--price ..]
+  cars update <id> [--brand ..] [--model ..] [--year ..] [--color ..] [--vin ..] [--price ..]
+  cars delete <id>
+  cars restore <id>
+  cars purge <id>
+  cars transfer <id> --to <id владельца> [--price ..] [--date ГГГГ-ММ-ДД]
+  cars history <id>
+  trash list
+  brands list
+  categories list
+  prices index --brand <марка|id> --percent <процент>
+  users list
+  users set <пользователь> viewer|operator|admin
+  users delete <пользователь>
+  migrate
+  serve --token <токен> [--addr 127.0.0.1:8080]
+                                   REST/JSON API (/api/owners, /api/cars, /api/brands, /api/categories);
+                                   клиенты передают заголовок Authorization: Bearer <токен>
+                                   (TACHKI_API_TOKEN), запросы выполняются с ролью пользователя
+                                   базы данных сервера
+
+Параметры подключения (для всех команд):
+  --backend "SQL Server" | SQLite   (TACHKI_BACKEND, по умолчанию SQL Server)
+  --dsn <строка подключения | путь к файлу SQLite>   (TACHKI_DSN)
+  --timeout 30s   ограничение времени команды или запроса API (TACHKI_TIMEOUT)
+
+Команды выполняются с правами роли пользователя базы данных (users list).
+`
+
+// errUsage marks errors caused by wrong command-line arguments (exit code 2)
+var errUsage = errors.New("неверные аргументы")
+
+type cliCommand func(c *cliContext) error
+
+var cliCommands = map[string]cliCommand{
+	"owners list":     cliListTable("owners"),
+	"owners show":     cliShowOwner,
+	"owners add":      cliAddOwner,
+	"owners update":   cliUpdateOwner,
+	"owners delete":   cliDelete("owners"),
+	"owners restore":  cliRestore("owners"),
+	"owners purge":    cliPurge("owners"),
+	"cars list":       cliListTable("cars"),
+	"cars show":       cliShowCar,
+	"cars add":        cliAddCar,
+	"cars update":     cliUpdateCar,
+	"cars delete":     cliDelete("cars"),
+	"cars restore":    cliRestore("cars"),
+	"cars purge":      cliPurge("cars"),
+	"cars transfer":   cliTransferCar,
+	"cars history":    cliCarHistory,
+	"trash list":      cliTrashList,
+	"brands list":     cliListTable("car_brands"),
+	"categories list": cliListTable("driver_categories"),
+	"prices index":    cliPriceIndex,
+	"users list":      cliUsersList,
+	"users set":       cliUsersSet,
+	"users delete":    cliUsersDelete,
+	"migrate":         cliMigrate,
+	"serve":           cliServe,
+}
+
+// cliContext holds the parsed flags and the open repository of one command
+type cliContext struct {
+	flags   *flag.FlagSet
+	args    []string
+	backend string
+	dsn     string
+	timeout time.Duration
+	repo    Repository
+	ctx     context.Context // ограничен --timeout; задаётся в parse
+	cancel  context.CancelFunc
+	out     io.Writer
+	// schema is set by migrate: it runs before app_users exists, so the
+	// role of the user is not checked
+	schema bool
+}
+
+// runCLI executes a headless command and returns the process exit code
+func runCLI(args []string) int {
+	return execCLI(args, nil, os.Stdout, os.Stderr)
+}
+
+// execCLI runs a command on repo, or on the connection given by the flags
+// when repo is nil, and returns the exit code
+func execCLI(args []string, repo Repository, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(stdout, cliUsage)
+		return 0
+	}
+
+	name := args[0]
+	rest := args[1:]
+	if _, ok := cliCommands[name]; !ok && len(args) > 1 {
+		name = args[0] + " " + args[1]
+		rest = args[2:]
+	}
+
+	command, ok := cliCommands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "Неизвестная команда: %s\n\n%s", strings.Join(args, " "), cliUsage)
+		return 2
+	}
+
+	c := &cliContext{
+		flags: flag.NewFlagSet(name, flag.ContinueOnError),
+		args:  rest,
+		repo:  repo,
+		out:   stdout,
+	}
+	c.flags.SetOutput(stderr)
+	c.flags.StringVar(&c.backend, "backend", envOr("TACHKI_BACKEND", backendMSSQL), "тип базы данных")
+	c.flags.StringVar(&c.dsn, "dsn", os.Getenv("TACHKI_DSN"), "строка подключения или путь к файлу SQLite")
+	c.flags.DurationVar(&c.timeout, "timeout", envDuration("TACHKI_TIMEOUT", defaultQueryTimeout), "ограничение времени запросов к базе данных")
+
+	err := command(c)
+	if c.cancel != nil {
+		c.cancel()
+	}
+	if c.repo != nil {
+		c.repo.Close()
+	}
+
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(stderr, "Ошибка: %v\n", err)
+		return 2
+	case errors.Is(err, context.DeadlineExceeded):
+		fmt.Fprintf(stderr, "Ошибка: превышено время ожидания (%v), увеличьте --timeout\n", c.timeout)
+		return 1
+	default:
+		fmt.Fprintf(stderr, "Ошибка: %v\n", err)
+		return 1
+	}
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// envDuration reads a duration such as "30s" from the environment
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(name)); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+// parse parses flags (allowed before and after positional arguments) and connects to the database
+func (c *cliContext) parse() ([]string, error) {
+	var positional []string
+	args := c.args
+	for {
+		if err := c.flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = c.flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	// Репозиторий, переданный execCLI, используется вместо подключения
+	app := &DatabaseApp{repo: c.repo}
+	if c.repo == nil {
+		if c.dsn == "" {
+			return nil, fmt.Errorf("%w: не задано подключение (--dsn или TACHKI_DSN)", errUsage)
+		}
+		if err := app.connectDB(c.backend, c.dsn); err != nil {
+			return nil, err
+		}
+		c.repo = app.repo
+	}
+	c.ctx, c.cancel = context.WithTimeout(context.Background(), c.timeout)
+
+	if !c.schema {
+		if err := app.signIn(c.ctx); err != nil {
+			return nil, err
+		}
+		c.repo = app.repo
+	}
+	return positional, nil
+}
+
+// parseID parses the single <id> argument of show/update/delete commands
+func (c *cliContext) parseID() (int, error) {
+	positional, err := c.parse()
+	if err != nil {
+		return 0, err
+	}
+	if len(positional) != 1 {
+		return 0, fmt.Errorf("%w: ожидается один ID записи", errUsage)
+	}
+	id, err := strconv.Atoi(positional[0])
+	if err != nil {
+		return 0, fmt.Errorf("%w: ID должен быть числом", errUsage)
+	}
+	return id, nil
+}
+
+// isSet reports whether a flag was given explicitly
+func (c *cliContext) isSet(name string) bool {
+	set := false
+	c.flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// printTable writes rows as aligned columns with the same headers as the View tab
+func (c *cliContext) printTable(columns []string, rows [][]interface{}) {
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(columns, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			cells[i] = exportCellText(columns[i], value)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+}
+
+// --- Lookups by name or ID ---
+
+func findCategoryID(ctx context.Context, repo Repository, value string) (int, error) {
+	categories, err := repo.GetDriverCategories(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, cat := range categories {
+		if strings.EqualFold(cat.Code, value) || strconv.Itoa(cat.ID) == value {
+			return cat.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("категория прав %q не найдена", value)
+}
+
+func findBrandID(ctx context.Context, repo Repository, value string) (int, error) {
+	brands, err := repo.GetCarBrands(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, brand := range brands {
+		if strings.EqualFold(brand.Name, value) || strconv.Itoa(brand.ID) == value {
+			return brand.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("марка %q не найдена", value)
+}
+
+// firstError returns the first validation error
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return fmt.Errorf("%w: %v", errUsage, err)
+		}
+	}
+	return nil
+}
+
+// --- Commands ---
+
+func cliListTable(tableName string) cliCommand {
+	return func(c *cliContext) error {
+		if _, err := c.parse(); err != nil {
+			return err
+		}
+		rows, err := c.repo.GetTableRows(c.ctx, tableName)
+		if err != nil {
+			return err
+		}
+		c.printTable(tableColumnNames(tableName), rows)
+		return nil
+	}
+}
+
+func cliDelete(tableName string) cliCommand {
+	return func(c *cliContext) error {
+		id, err := c.parseID()
+		if err != nil {
+			return err
+		}
+		app := &DatabaseApp{repo: c.repo}
+		if err := app.deleteRecord(c.ctx, tableName, id); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "Запись с ID %d из таблицы %s перемещена в корзину\n", id, tableName)
+		return nil
+	}
+}
+
+func cliRestore(tableName string) cliCommand {
+	return func(c *cliContext) error {
+		id, err := c.parseID()
+		if err != nil {
+			return err
+		}
+		app := &DatabaseApp{repo: c.repo}
+		if err := app.restoreRecord(c.ctx, tableName, id); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "Запись с ID %d из таблицы %s восстановлена\n", id, tableName)
+		return nil
+	}
+}
+
+func cliPurge(tableName string) cliCommand {
+	return func(c *cliContext) error {
+		id, err := c.parseID()
+		if err != nil {
+			return err
+		}
+		app := &DatabaseApp{repo: c.repo}
+		if err := app.purgeRecord(c.ctx, tableName, id); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "Запись с ID %d удалена из таблицы %s навсегда\n", id, tableName)
+		return nil
+	}
+}
+
+func cliTrashList(c *cliContext) error {
+	if _, err := c.parse(); err != nil {
+		return err
+	}
+	records, err := c.repo.GetDeleted(c.ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, 0, len(records))
+	for _, rec := range records {
+		rows = append(rows, []interface{}{rec.Table, rec.ID, rec.Title,
+			rec.DeletedAt.Format("2006-01-02 15:04:05"), rec.DeletedBy})
+	}
+	c.printTable([]string{"Таблица", "ID", "Запись", "Удалена", "Пользователь"}, rows)
+	return nil
+}
+
+func cliShowOwner(c *cliContext) error {
+	id, err := c.parseID()
+	if err != nil {
+		return err
+	}
+	owner, err := c.repo.GetOwnerByID(c.ctx, id)
+	if err != nil {
+		return err
+	}
+	c.printTable([]string{"ID", "Имя", "Фамилия", "Телефон", "Email", "Категория"},
+		[][]interface{}{{owner.ID, owner.FirstName, owner.LastName, owner.Phone, owner.Email, owner.Category}})
+	return nil
+}
+
+func cliShowCar(c *cliContext) error {
+	id, err := c.parseID()
+	if err != nil {
+		return err
+	}
+	car, err := c.repo.GetCarByID(c.ctx, id)
+	if err != nil {
+		return err
+	}
+	c.printTable([]string{"ID", "Владелец", "Марка", "Модель", "Год", "Цвет", "VIN", "Цена покупки", "Тек. цена (~)"},
+		[][]interface{}{{car.ID, car.OwnerID, car.BrandID, car.Model, car.Year, car.Color, car.VIN, car.Price, car.CurrentPrice}})
+	return nil
+}
+
+func cliAddOwner(c *cliContext) error {
+	first := c.flags.String("first", "", "имя")
+	last := c.flags.String("last", "", "фамилия")
+	phone := c.flags.String("phone", "", "телефон")
+	email := c.flags.String("email", "", "email")
+	category := c.flags.String("category", "", "категория прав (код или ID)")
+	if _, err := c.parse(); err != nil {
+		return err
+	}
+
+	if *category == "" {
+		return fmt.Errorf("%w: укажите --category", errUsage)
+	}
+	if err := firstError(validateFirstName(*first), validateLastName(*last), validatePhone(*phone), validateEmail(*email)); err != nil {
+		return err
+	}
+
+	categoryID, err := findCategoryID(c.ctx, c.repo, *category)
+	if err != nil {
+		return err
+	}
+
+	id, err := c.repo.AddOwner(c.ctx, *first, *last, *phone, *email, categoryID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Владелец успешно добавлен (ID %d)\n", id)
+	return nil
+}
+
+func cliUpdateOwner(c *cliContext) error {
+	first := c.flags.String("first", "", "имя")
+	last := c.flags.String("last", "", "фамилия")
+	phone := c.flags.String("phone", "", "телефон")
+	email := c.flags.String("email", "", "email")
+	category := c.flags.String("category", "", "категория прав (код или ID)")
+	id, err := c.parseID()
+	if err != nil {
+		return err
+	}
+
+	owner, err := c.repo.GetOwnerByID(c.ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Не переданные флаги сохраняют текущие значения
+	if !c.isSet("first") {
+		*first = owner.FirstName
+	}
+	if !c.isSet("last") {
+		*last = owner.LastName
+	}
+	if !c.isSet("phone") {
+		*phone = owner.Phone
+	}
+	if !c.isSet("email") {
+		*email = owner.Email
+	}
+	if !c.isSet("category") {
+		*category = owner.Category
+	}
+
+	if err := firstError(validateFirstName(*first), validateLastName(*last), validatePhone(*phone), validateEmail(*email)); err != nil {
+		return err
+	}
+
+	categoryID, err := findCategoryID(c.ctx, c.repo, *category)
+	if err != nil {
+		return err
+	}
+
+	if err := c.repo.UpdateOwner(c.ctx, id, *first, *last, *phone, *email, categoryID, owner.RowVersion); err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, "Владелец успешно обновлен")
+	return nil
+}
+
+func cliAddCar(c *cliContext) error {
+	owner := c.flags.Int("owner", 0, "ID владельца")
+	brand := c.flags.String("brand", "", "марка (название или ID)")
+	model := c.flags.String("model", "", "модель")
+	year := c.flags.String("year", "", "год выпуска")
+	color := c.flags.String("color", "", "цвет")
+	vin := c.flags.String("vin", "", "VIN код")
+	price := c.flags.String("price", "", "цена")
+	if _, err := c.parse(); err != nil {
+		return err
+	}
+
+	if *owner == 0 || *brand == "" {
+		return fmt.Errorf("%w: укажите --owner и --brand", errUsage)
+	}
+	if err := firstError(validateModel(*model), validateYear(*year), validateVIN(*vin), validatePrice(*price)); err != nil {
+		return err
+	}
+
+	brandID, err := findBrandID(c.ctx, c.repo, *brand)
+	if err != nil {
+		return err
+	}
+
+	yearValue, _ := strconv.Atoi(*year)
+	priceValue, _ := strconv.ParseFloat(*price, 64)
+
+	id, err := c.repo.AddCar(c.ctx, *owner, brandID, *model, yearValue, *color, *vin, priceValue)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Автомобиль успешно добавлен (ID %d)\n", id)
+	return nil
+}
+
+// cliUpdateCar keeps the owner: it is changed by `cars transfer`, which
+// records ownership_history
+func cliUpdateCar(c *cliContext) error {
+	brand := c.flags.String("brand", "", "марка (название или ID)")
+	model := c.flags.String("model", "", "модель")
+	year := c.flags.String("year", "", "год выпуска")
+	color := c.flags.String("color", "", "цвет")
+	vin := c.flags.String("vin", "", "VIN код")
+	price := c.flags.String("price", "", "цена")
+	id, err := c.parseID()
+	if err != nil {
+		return err
+	}
+
+	car, err := c.repo.GetCarByID(c.ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Не переданные флаги сохраняют текущие значения
+	if !c.isSet("brand") {
+		*brand = strconv.Itoa(car.BrandID)
+	}
+	if !c.isSet("model") {
+		*model = car.Model
+	}
+	if !c.isSet("year") {
+		*year = strconv.Itoa(car.Year)
+	}
+	if !c.isSet("color") {
+		*color = car.Color
+	}
+	if !c.isSet("vin") {
+		*vin = car.VIN
+	}
+	if !c.isSet("price") {
+		*price = strconv.FormatFloat(car.Price, 'f', -1, 64)
+	}
+
+	if err := firstError(validateModel(*model), validateYear(*year), validateVIN(*vin), validatePrice(*price)); err != nil {
+		return err
+	}
+
+	brandID, err := findBrandID(c.ctx, c.repo, *brand)
+	if err != nil {
+		return err
+	}
+
+	yearValue, _ := strconv.Atoi(*year)
+	priceValue, _ := strconv.ParseFloat(*price, 64)
+
+	if err := c.repo.UpdateCar(c.ctx, id, car.OwnerID, brandID, *model, yearValue, *color, *vin, priceValue, car.RowVersion); err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, "Автомобиль успешно обновлен")
+	return nil
+}
+
+func cliTransferCar(c *cliContext) error {
+	to := c.flags.Int("to", 0, "ID нового владельца")
+	price := c.flags.String("price", "0", "цена продажи")
+	date := c.flags.String("date", time.Now().Format("2006-01-02"), "дата передачи (ГГГГ-ММ-ДД)")
+	id, err := c.parseID()
+	if err != nil {
+		return err
+	}
+
+	if !c.isSet("to") {
+		return fmt.Errorf("%w: укажите --to", errUsage)
+	}
+	if err := validatePrice(*price); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	transferDate, err := time.Parse("2006-01-02", *date)
+	if err != nil {
+		return fmt.Errorf("%w: дата должна быть в формате ГГГГ-ММ-ДД", errUsage)
+	}
+
+	car, err := c.repo.GetCarByID(c.ctx, id)
+	if err != nil {
+		return err
+	}
+
+	priceValue, _ := strconv.ParseFloat(*price, 64)
+	if err := c.repo.TransferOwnership(c.ctx, id, *to, transferDate, priceValue, car.RowVersion); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Автомобиль %d передан владельцу %d\n", id, *to)
+	return nil
+}
+
+func cliCarHistory(c *cliContext) error {
+	id, err := c.parseID()
+	if err != nil {
+		return err
+	}
+	history, err := c.repo.GetOwnershipHistory(c.ctx, id)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, 0, len(history))
+	for _, t := range history {
+		rows = append(rows, []interface{}{t.Date, t.FromOwner, t.ToOwner, t.SalePrice})
+	}
+	c.printTable([]string{"Дата", "Прежний владелец", "Новый владелец", "Цена продажи"}, rows)
+	return nil
+}
+
+func cliPriceIndex(c *cliContext) error {
+	brand := c.flags.String("brand", "", "марка (название или ID)")
+	percent := c.flags.Float64("percent", 0, "процент изменения (например: 10 или -5)")
+	if _, err := c.parse(); err != nil {
+		return err
+	}
+
+	if *brand == "" || !c.isSet("percent") {
+		return fmt.Errorf("%w: укажите --brand и --percent", errUsage)
+	}
+
+	brandID, err := findBrandID(c.ctx, c.repo, *brand)
+	if err != nil {
+		return err
+	}
+
+	if err := c.repo.MassPriceUpdate(c.ctx, brandID, *percent); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Цены для %s успешно изменены на %.1f%%\n", *brand, *percent)
+	return nil
+}
+
+func cliUsersList(c *cliContext) error {
+	if _, err := c.parse(); err != nil {
+		return err
+	}
+	users, err := c.repo.GetAppUsers(c.ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, 0, len(users))
+	for _, u := range users {
+		rows = append(rows, []interface{}{u.Name, u.Role})
+	}
+	c.printTable([]string{"Пользователь", "Роль"}, rows)
+	return nil
+}
+
+func cliUsersSet(c *cliContext) error {
+	positional, err := c.parse()
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return fmt.Errorf("%w: ожидаются пользователь и роль", errUsage)
+	}
+	if roleRank(positional[1]) < 0 {
+		return fmt.Errorf("%w: роль должна быть одной из: %s", errUsage, strings.Join(roles, ", "))
+	}
+
+	if err := c.repo.SetAppUser(c.ctx, positional[0], positional[1]); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Пользователю %s назначена роль %s\n", positional[0], positional[1])
+	return nil
+}
+
+func cliUsersDelete(c *cliContext) error {
+	positional, err := c.parse()
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("%w: ожидается имя пользователя", errUsage)
+	}
+
+	if err := c.repo.DeleteAppUser(c.ctx, positional[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Пользователь %s удалён, теперь у него роль viewer\n", positional[0])
+	return nil
+}
+
+func cliMigrate(c *cliContext) error {
+	c.schema = true
+	if _, err := c.parse(); err != nil {
+		return err
+	}
+
+	migrator, ok := c.repo.(SchemaMigrator)
+	if !ok {
+		return fmt.Errorf("база данных не поддерживает миграции")
+	}
+
+	pending, err := migrator.PendingMigrations(c.ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		fmt.Fprintln(c.out, "Схема актуальна")
+		return nil
+	}
+
+	if err := migrator.ApplyMigrations(c.ctx, pending); err != nil {
+		return err
+	}
+	for _, m := range pending {
+		fmt.Fprintf(c.out, "Применена миграция %04d - %s\n", m.Version, m.Name)
+	}
+	return nil
+}
+
+func cliServe(c *cliContext) error {
+	addr := c.flags.String("addr", "127.0.0.1:8080", "адрес HTTP-сервера")
+	token := c.flags.String("token", os.Getenv("TACHKI_API_TOKEN"), "токен доступа клиентов API")
+	if _, err := c.parse(); err != nil {
+		return err
+	}
+	if *token == "" {
+		return fmt.Errorf("%w: укажите --token или TACHKI_API_TOKEN", errUsage)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           newAPIServer(c.repo, c.timeout, *token),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+	fmt.Fprintf(c.out, "API доступен на http://%s/api/ (Ctrl+C для остановки)\n", *addr)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestCLI runs the commands one after another on the same in-memory
+// database; each step sees the changes of the previous ones
+func TestCLI(t *testing.T) {
+	repo := newMemoryRepository()
+
+	steps := []struct {
+		name   string
+		args   []string
+		code   int
+		output string // ожидаемая подстрока вывода (stdout или stderr)
+	}{
+		{"справка", []string{"help"}, 0, "Использование"},
+		{"неизвестная команда", []string{"owners fly"}, 2, "Неизвестная команда"},
+		{"добавить владельца", []string{"owners", "add", "--first", "Иван", "--last", "Петров", "--category", "B"}, 0, "ID 1"},
+		{"добавить второго владельца", []string{"owners", "add", "--first", "Анна", "--last", "Смирнова", "--category", "C", "--email", "anna@example.com"}, 0, "ID 2"},
+		{"владелец без категории", []string{"owners", "add", "--first", "Иван", "--last", "Петров"}, 2, "--category"},
+		{"некорректный email", []string{"owners", "add", "--first", "Иван", "--last", "Петров", "--category", "B", "--email", "nope"}, 2, "@"},
+		{"неизвестная категория", []string{"owners", "add", "--first", "Иван", "--last", "Петров", "--category", "Z"}, 1, "не найдена"},
+		{"список владельцев", []string{"owners", "list"}, 0, "Смирнова"},
+		{"изменить владельца", []string{"owners", "update", "1", "--phone", "+7 900 000-00-00"}, 0, "обновлен"},
+		{"показать владельца", []string{"owners", "show", "1"}, 0, "+7 900 000-00-00"},
+		{"ID не число", []string{"owners", "show", "x"}, 2, "ID должен быть числом"},
+		{"добавить автомобиль", []string{"cars", "add", "--owner", "1", "--brand", "Toyota", "--model", "Camry", "--year", "2020", "--vin", "JT2BF22K1W0123456", "--price", "25000"}, 0, "ID 1"},
+		{"цена не число", []string{"cars", "add", "--owner", "1", "--brand", "Toyota", "--model", "Camry", "--price", "дорого"}, 2, "цена"},
+		{"неизвестная марка", []string{"cars", "add", "--owner", "1", "--brand", "Zaporozhets", "--model", "968"}, 1, "не найдена"},
+		{"изменить автомобиль", []string{"cars", "update", "1", "--color", "Белый"}, 0, "обновлен"},
+		{"показать автомобиль", []string{"cars", "show", "1"}, 0, "Белый"},
+		{"передать автомобиль", []string{"cars", "transfer", "1", "--to", "2", "--price", "20000", "--date", "2025-05-01"}, 0, "передан владельцу 2"},
+		{"передача без получателя", []string{"cars", "transfer", "1"}, 2, "--to"},
+		{"цена продажи не число", []string{"cars", "transfer", "1", "--to", "1", "--price", "дорого"}, 2, "цена должна быть числом"},
+		{"отрицательная цена продажи", []string{"cars", "transfer", "1", "--to", "1", "--price", "-100"}, 2, "отрицательной"},
+		{"история владения", []string{"cars", "history", "1"}, 0, "2025-05-01"},
+		{"переоценка", []string{"prices", "index", "--brand", "Toyota", "--percent", "10"}, 0, "10.0%"},
+		{"удалить автомобиль", []string{"cars", "delete", "1"}, 0, "в корзину"},
+		{"корзина", []string{"trash", "list"}, 0, "Camry"},
+		{"восстановить автомобиль", []string{"cars", "restore", "1"}, 0, "восстановлена"},
+		{"пользователи", []string{"users", "list"}, 0, roleAdmin},
+		{"назначить роль", []string{"users", "set", "DOMAIN\\ivanov", "operator"}, 0, "operator"},
+		{"неизвестная роль", []string{"users", "set", "DOMAIN\\ivanov", "root"}, 2, "роль"},
+		{"удалить пользователя", []string{"users", "delete", "DOMAIN\\ivanov"}, 0, "удалён"},
+	}
+
+	for _, step := range steps {
+		var stdout, stderr bytes.Buffer
+		code := execCLI(step.args, repo, &stdout, &stderr)
+		output := stdout.String() + stderr.String()
+		if code != step.code {
+			t.Fatalf("%s: код выхода %d, ожидался %d\n%s", step.name, code, step.code, output)
+		}
+		if !strings.Contains(output, step.output) {
+			t.Fatalf("%s: в выводе нет %q\n%s", step.name, step.output, output)
+		}
+	}
+}
+
+// TestCLIViewer checks that commands run with the role of the user
+func TestCLIViewer(t *testing.T) {
+	repo := newMemoryRepository()
+	repo.appUsers = map[string]string{"DOMAIN\\admin": roleAdmin} // текущий пользователь не в списке — наблюдатель
+
+	var stdout, stderr bytes.Buffer
+	if code := execCLI([]string{"owners", "list"}, repo, &stdout, &stderr); code != 0 {
+		t.Fatalf("owners list: код выхода %d\n%s", code, stderr.String())
+	}
+
+	stderr.Reset()
+	code := execCLI([]string{"owners", "add", "--first", "Иван", "--last", "Петров", "--category", "B"}, repo, &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), errPermissionDenied.Error()) {
+		t.Fatalf("owners add: код выхода %d, ожидался отказ в доступе\n%s", code, stderr.String())
+	}
+}
@@ -1,223 +1,183 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
+	"time"
+)
 
-	_ "github.com/microsoft/go-mssqldb"
+// Supported database backends (also used as labels on the login screen)
+const (
+	backendMSSQL  = "SQL Server"
+	backendSQLite = "SQLite"
 )
 
-func (d *DatabaseApp) connectDB(connStr string) error {
-	var err error
-	// Close existing connection if we are reconnecting
-	if d.db != nil {
-		d.db.Close()
-	}
+// defaultQueryTimeout limits a database call unless configured otherwise
+const defaultQueryTimeout = 30 * time.Second
 
-	d.db, err = sql.Open("sqlserver", connStr)
-	if err != nil {
-		return err
-	}
+// queryTimeoutKey stores the query timeout of the UI (seconds) in app preferences
+const queryTimeoutKey = "db.queryTimeout"
 
-	return d.db.Ping()
+// queryTimeout returns the configured time limit of one database call
+func (d *DatabaseApp) queryTimeout() time.Duration {
+	seconds := d.app.Preferences().IntWithFallback(queryTimeoutKey, int(defaultQueryTimeout/time.Second))
+	return time.Duration(seconds) * time.Second
 }
 
-// --- Reads ---
-
-func (d *DatabaseApp) getDriverCategories() ([]DriverCategory, error) {
-	query := "SELECT category_id, category_code, category_name FROM driver_categories"
-	rows, err := d.db.Query(query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var categories []DriverCategory
-	for rows.Next() {
-		var cat DriverCategory
-		err := rows.Scan(&cat.ID, &cat.Code, &cat.Name)
-		if err != nil {
-			return nil, err
-		}
-		categories = append(categories, cat)
-	}
-	return categories, nil
+// queryContext returns the context for the database calls of one UI action
+func (d *DatabaseApp) queryContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), d.queryTimeout())
 }
 
-func (d *DatabaseApp) getOwners() ([]Owner, error) {
-	query := "SELECT owner_id, first_name, last_name FROM owners"
-	rows, err := d.db.Query(query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var owners []Owner
-	for rows.Next() {
-		var owner Owner
-		err := rows.Scan(&owner.ID, &owner.FirstName, &owner.LastName)
-		if err != nil {
-			return nil, err
-		}
-		owners = append(owners, owner)
-	}
-	return owners, nil
+// repository returns the current connection. Without one — before login,
+// while the session is locked or after switching user — it returns a
+// repository whose calls fail with errNotConnected, so that operations still
+// running in the background end with an error rather than a nil dereference.
+func (d *DatabaseApp) repository() Repository {
+	d.repoMu.RLock()
+	defer d.repoMu.RUnlock()
+	if d.repo == nil {
+		return closedRepository{}
+	}
+	return d.repo
 }
 
-func (d *DatabaseApp) getCarBrands() ([]CarBrand, error) {
-	query := "SELECT brand_id, brand_name, image_data FROM car_brands"
-	rows, err := d.db.Query(query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var brands []CarBrand
-	for rows.Next() {
-		var brand CarBrand
-		var imageData []byte
-		err := rows.Scan(&brand.ID, &brand.Name, &imageData)
-		if err != nil {
-			return nil, err
-		}
-		brand.ImageData = imageData
-		brands = append(brands, brand)
-	}
-	return brands, nil
+// setRepository replaces the connection and returns the previous one, which
+// the caller closes
+func (d *DatabaseApp) setRepository(repo Repository) Repository {
+	d.repoMu.Lock()
+	defer d.repoMu.Unlock()
+	old := d.repo
+	d.repo = repo
+	return old
 }
 
-func (d *DatabaseApp) searchOwnerByID(id int) (*Owner, error) {
-	query := `SELECT o.owner_id, o.first_name, o.last_name, o.phone, o.email, dc.category_code, 
-			  o.row_version
-			  FROM owners o 
-			  JOIN driver_categories dc ON o.license_category_id = dc.category_id 
-			  WHERE o.owner_id = @p1`
-
-	row := d.db.QueryRow(query, id)
-
-	var owner Owner
-	err := row.Scan(&owner.ID, &owner.FirstName, &owner.LastName, &owner.Phone, &owner.Email, &owner.Category, &owner.RowVersion)
-	if err != nil {
-		return nil, err
-	}
-	return &owner, nil
+// monitor returns the health monitor of the main window; nil without it
+func (d *DatabaseApp) monitor() *healthMonitor {
+	d.repoMu.RLock()
+	defer d.repoMu.RUnlock()
+	return d.health
 }
 
-func (d *DatabaseApp) searchCarByID(id int) (*Car, error) {
-	// Добавили вызов dbo.fn_GetCarDepreciatedValue(price, year) в запрос
-	query := `SELECT car_id, owner_id, brand_id, model, year, color, vin_code, price, 
-			  dbo.fn_GetCarDepreciatedValue(price, year), 
-			  row_version 
-			  FROM cars WHERE car_id = @p1`
-
-	row := d.db.QueryRow(query, id)
+// setMonitor replaces the health monitor and returns the previous one
+func (d *DatabaseApp) setMonitor(m *healthMonitor) *healthMonitor {
+	d.repoMu.Lock()
+	defer d.repoMu.Unlock()
+	old := d.health
+	d.health = m
+	return old
+}
 
-	var car Car
-	// Добавили &car.CurrentPrice в scan
-	err := row.Scan(&car.ID, &car.OwnerID, &car.BrandID, &car.Model, &car.Year, &car.Color,
-		&car.VIN, &car.Price, &car.CurrentPrice, &car.RowVersion)
+// connectDB opens a repository for the backend. For SQL Server connStr is a
+// go-mssqldb connection string, for SQLite it is the path to the database file.
+func (d *DatabaseApp) connectDB(backend, connStr string) error {
+	// Close existing connection if we are reconnecting
+	if old := d.setRepository(nil); old != nil {
+		old.Close()
+	}
 
+	repo, err := openRepository(backend, connStr)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return &car, nil
-}
 
-// --- Writes (Create/Update/Delete) ---
-
-func (d *DatabaseApp) addOwner(firstName, lastName, phone, email string, categoryID int) error {
-	query := `INSERT INTO owners (first_name, last_name, phone, email, license_category_id) 
-              VALUES (@p1, @p2, @p3, @p4, @p5)`
-	_, err := d.db.Exec(query, firstName, lastName, phone, email, categoryID)
-	return err
+	d.repoMu.Lock()
+	d.repo = repo
+	d.backend = backend
+	d.connStr = connStr
+	d.repoMu.Unlock()
+	return nil
 }
 
-func (d *DatabaseApp) addCar(ownerID, brandID int, model string, year int, color, vin string, price float64) error {
-	query := `INSERT INTO cars (owner_id, brand_id, model, year, color, vin_code, price) 
-              VALUES (@p1, @p2, @p3, @p4, @p5, @p6, @p7)`
-	_, err := d.db.Exec(query, ownerID, brandID, model, year, color, vin, price)
-	return err
+// connection returns the backend, the connection string and the user of the
+// last login. connectDB and signIn set them off the UI goroutine.
+func (d *DatabaseApp) connection() (backend, connStr string, user AppUser) {
+	d.repoMu.RLock()
+	defer d.repoMu.RUnlock()
+	return d.backend, d.connStr, d.user
 }
 
-func (d *DatabaseApp) updateOwner(id int, firstName, lastName, phone, email string, categoryID int, rowVersion []byte) error {
-	query := `UPDATE owners 
-			  SET first_name = @p1, last_name = @p2, phone = @p3, email = @p4, 
-			      license_category_id = @p5, row_version = NEWID()
-			  WHERE owner_id = @p6 AND row_version = @p7`
-
-	result, err := d.db.Exec(query, firstName, lastName, phone, email, categoryID, id, rowVersion)
-	if err != nil {
-		return err
+// openRepository opens the repository of a backend
+func openRepository(backend, connStr string) (Repository, error) {
+	var repo *sqlRepository
+	var err error
+	switch backend {
+	case backendMSSQL:
+		repo, err = openMSSQL(connStr)
+	case backendSQLite:
+		repo, err = openSQLite(connStr)
+	default:
+		err = fmt.Errorf("неизвестный тип базы данных: %s", backend)
 	}
-
-	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return err
-	}
-
-	if rowsAffected == 0 {
-		return fmt.Errorf("запись была изменена другим пользователем")
+		return nil, err
 	}
-	return nil
+	return repo, nil
 }
 
-func (d *DatabaseApp) updateCar(id int, ownerID, brandID int, model string, year int, color, vin string, price float64, rowVersion []byte) error {
-	query := `UPDATE cars 
-			  SET owner_id = @p1, brand_id = @p2, model = @p3, year = @p4, color = @p5, 
-			      vin_code = @p6, price = @p7, row_version = NEWID()
-			  WHERE car_id = @p8 AND row_version = @p9`
-
-	result, err := d.db.Exec(query, ownerID, brandID, model, year, color, vin, price, id, rowVersion)
-	if err != nil {
-		return err
+// reconnect restores the connection of the last login. The open tabs keep
+// their contents: they take the repository anew on every call. It runs in the
+// background, so the connection is swapped under the lock.
+func (d *DatabaseApp) reconnect(ctx context.Context) error {
+	if d.repository().Ping(ctx) == nil {
+		return nil
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	backend, connStr, user := d.connection()
+	repo, err := openRepository(backend, connStr)
 	if err != nil {
 		return err
 	}
-
-	if rowsAffected == 0 {
-		return fmt.Errorf("запись была изменена другим пользователем")
+	if old := d.setRepository(withRole(repo, user.Role)); old != nil {
+		old.Close()
 	}
 	return nil
 }
 
-func (d *DatabaseApp) deleteRecord(table string, id int) error {
-	query := fmt.Sprintf("DELETE FROM %s WHERE %s_id = @p1", table, table[:len(table)-1])
-	_, err := d.db.Exec(query, id)
-	return err
+// deleteRecord moves a row of owners or cars to the recycle bin
+func (d *DatabaseApp) deleteRecord(ctx context.Context, table string, id int) error {
+	switch table {
+	case "owners":
+		return d.repository().DeleteOwner(ctx, id, nil)
+	case "cars":
+		return d.repository().DeleteCar(ctx, id, nil)
+	default:
+		return fmt.Errorf("неизвестная таблица: %s", table)
+	}
 }
-func (d *DatabaseApp) massPriceUpdate(brandID int, percentage float64) error {
-	query := "EXEC sp_MassPriceUpdate @BrandID = @p1, @Percentage = @p2"
 
-	result, err := d.db.Exec(query, brandID, percentage)
-	if err != nil {
-		return err
-	}
-	// Можно даже проверить, сколько записей затронуто
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return fmt.Errorf("автомобили данного бренда не найдены")
+// restoreRecord returns a row of owners or cars from the recycle bin
+func (d *DatabaseApp) restoreRecord(ctx context.Context, table string, id int) error {
+	switch table {
+	case "owners":
+		return d.repository().RestoreOwner(ctx, id)
+	case "cars":
+		return d.repository().RestoreCar(ctx, id)
+	default:
+		return fmt.Errorf("неизвестная таблица: %s", table)
 	}
-
-	return nil
 }
-func (d *DatabaseApp) updateBrandImage(brandID int, imageData []byte) error {
-	query := "UPDATE car_brands SET image_data = @p1 WHERE brand_id = @p2"
-	_, err := d.db.Exec(query, imageData, brandID)
-	return err
-}
-func (d *DatabaseApp) getBrandImage(brandID int) ([]byte, error) {
-	query := "SELECT image_data FROM car_brands WHERE brand_id = @p1"
-	row := d.db.QueryRow(query, brandID)
 
-	var imageData []byte
-	// Если в базе NULL, Scan запишет nil в imageData, ошибки не будет
-	err := row.Scan(&imageData)
-	if err != nil {
-		return nil, err
+// purgeRecord removes a row of owners or cars from the recycle bin permanently
+func (d *DatabaseApp) purgeRecord(ctx context.Context, table string, id int) error {
+	switch table {
+	case "owners":
+		return d.repository().PurgeOwner(ctx, id)
+	case "cars":
+		return d.repository().PurgeCar(ctx, id)
+	default:
+		return fmt.Errorf("неизвестная таблица: %s", table)
 	}
+}
 
-	return imageData, nil
+// bulkDelete moves the selected rows of owners or cars to the recycle bin in one transaction
+func (d *DatabaseApp) bulkDelete(ctx context.Context, table string, ids []int) (BulkResult, error) {
+	switch table {
+	case "owners":
+		return d.repository().BulkDeleteOwners(ctx, ids)
+	case "cars":
+		return d.repository().BulkDeleteCars(ctx, ids)
+	default:
+		return BulkResult{}, fmt.Errorf("неизвестная таблица: %s", table)
+	}
 }
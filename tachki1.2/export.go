@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"github.com/xuri/excelize/v2"
+)
+
+// utf8BOM lets Excel detect the encoding of Cyrillic headers
+const utf8BOM = "\uFEFF"
+
+// exportCellText formats a value for text exports; logos are replaced by their size
+func exportCellText(column string, value interface{}) string {
+	if data, ok := value.([]byte); ok && column == "Логотип" {
+		if len(data) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("<%d байт>", len(data))
+	}
+	return formatCellValue(value)
+}
+
+// writeCSV writes the table with the same headers and formatting as the View tab
+func writeCSV(w io.Writer, columns []string, rows [][]interface{}) error {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i := range record {
+			record[i] = ""
+			if i < len(row) {
+				record[i] = exportCellText(columns[i], row[i])
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// exportTableCSV asks for a file name and saves the table as shown in the
+// View tab: all pages, with the current sorting and filters
+func (d *DatabaseApp) exportTableCSV(tableName string, query TableQuery) {
+	columns := tableColumnNames(tableName)
+	if columns == nil {
+		d.showMessage("Ошибка", "Выберите таблицу для экспорта")
+		return
+	}
+
+	query.Offset, query.Limit = 0, 0
+	var rows [][]interface{}
+	d.runWithProgress("Экспорт CSV", func(ctx context.Context) error {
+		var err error
+		rows, err = d.repository().QueryTable(ctx, tableName, query)
+		return err
+	}, func(err error) {
+		if err != nil {
+			d.showMessage("Ошибка", fmt.Sprintf("Ошибка загрузки данных: %v", err))
+			return
+		}
+
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, d.window)
+				return
+			}
+			if writer == nil {
+				return // отмена
+			}
+			if err := finishExport(writer, writeCSV(writer, columns, rows)); err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Ошибка экспорта: %v", err))
+				return
+			}
+			d.showMessage("Успех", fmt.Sprintf("Экспортировано строк: %d\n%s", len(rows), writer.URI().Path()))
+		}, d.window)
+
+		saveDialog.SetFileName(tableName + ".csv")
+		saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".csv"}))
+		saveDialog.Show()
+	})
+}
+
+// finishExport closes the exported file and removes it when writing failed,
+// so a cut-off file is not left behind
+func finishExport(writer fyne.URIWriteCloser, err error) error {
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		if removeErr := storage.Delete(writer.URI()); removeErr != nil {
+			log.Printf("Не удалось удалить файл экспорта %s: %v", writer.URI(), removeErr)
+		}
+	}
+	return err
+}
+
+// --- XLSX ---
+
+// xlsxDateColumns are written as real dates instead of text
+var xlsxDateColumns = map[string]bool{
+	"Дата получения прав": true,
+	"Дата покупки":        true,
+}
+
+// xlsxMoneyColumns get a thousands separator
+var xlsxMoneyColumns = map[string]bool{
+	"Цена покупки":  true,
+	"Тек. цена (~)": true,
+}
+
+// writeXLSX writes every table of the View tab to its own sheet
+func writeXLSX(ctx context.Context, w io.Writer, repo TableRepository) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	styles, err := newXLSXStyles(f)
+	if err != nil {
+		return err
+	}
+
+	for i, t := range viewTables {
+		rows, err := repo.GetTableRows(ctx, t.Name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.Title, err)
+		}
+
+		if i == 0 {
+			err = f.SetSheetName(f.GetSheetName(0), t.Title)
+		} else {
+			_, err = f.NewSheet(t.Title)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := writeXLSXSheet(f, t.Title, tableColumnNames(t.Name), rows, styles); err != nil {
+			return fmt.Errorf("%s: %w", t.Title, err)
+		}
+	}
+
+	return f.Write(w)
+}
+
+type xlsxStyles struct {
+	header, date, money int
+}
+
+func newXLSXStyles(f *excelize.File) (*xlsxStyles, error) {
+	var styles xlsxStyles
+	var err error
+	if styles.header, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}); err != nil {
+		return nil, err
+	}
+	dateFormat := "dd.mm.yyyy"
+	if styles.date, err = f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat}); err != nil {
+		return nil, err
+	}
+	moneyFormat := "#,##0"
+	if styles.money, err = f.NewStyle(&excelize.Style{CustomNumFmt: &moneyFormat}); err != nil {
+		return nil, err
+	}
+	return &styles, nil
+}
+
+func writeXLSXSheet(f *excelize.File, sheet string, columns []string, rows [][]interface{}, styles *xlsxStyles) error {
+	for col, name := range columns {
+		cell, _ := excelize.CoordinatesToCellName(col+1, 1)
+		if err := f.SetCellValue(sheet, cell, name); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(sheet, cell, cell, styles.header); err != nil {
+			return err
+		}
+		colName, _ := excelize.ColumnNumberToName(col + 1)
+		if err := f.SetColWidth(sheet, colName, colName, 18); err != nil {
+			return err
+		}
+	}
+
+	for r, row := range rows {
+		rowNum := r + 2
+		for col, value := range row {
+			if col >= len(columns) {
+				break
+			}
+			cell, _ := excelize.CoordinatesToCellName(col+1, rowNum)
+			column := columns[col]
+
+			if column == "Логотип" {
+				if err := addXLSXLogo(f, sheet, cell, rowNum, value); err != nil {
+					return err
+				}
+				continue
+			}
+
+			typed, style := xlsxCellValue(column, value, styles)
+			if err := f.SetCellValue(sheet, cell, typed); err != nil {
+				return err
+			}
+			if style != 0 {
+				if err := f.SetCellStyle(sheet, cell, cell, style); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+}
+
+// xlsxCellValue converts a scanned value to a typed cell value and its style (0 = default)
+func xlsxCellValue(column string, value interface{}, styles *xlsxStyles) (interface{}, int) {
+	if xlsxDateColumns[column] {
+		if date, ok := parseDateValue(value); ok {
+			return date, styles.date
+		}
+	}
+
+	style := 0
+	if xlsxMoneyColumns[column] {
+		style = styles.money
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, 0
+	case []byte:
+		// DECIMAL/MONEY приходят как текст
+		if number, err := strconv.ParseFloat(string(v), 64); err == nil {
+			return number, style
+		}
+		return string(v), 0
+	case int64, int, int32, float64:
+		return v, style
+	default:
+		return formatCellValue(v), 0
+	}
+}
+
+// parseDateValue accepts time.Time (SQL Server) and date text (SQLite)
+func parseDateValue(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case []byte:
+		return parseDateValue(string(v))
+	case string:
+		// "2006-01-02", "2006-01-02 15:04:05" или RFC 3339 — время суток не нужно
+		if len(v) >= 10 {
+			if t, err := time.Parse("2006-01-02", v[:10]); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// addXLSXLogo embeds a brand logo into the cell and makes the row tall enough
+func addXLSXLogo(f *excelize.File, sheet, cell string, row int, value interface{}) error {
+	data, ok := value.([]byte)
+	if !ok || len(data) == 0 {
+		return nil
+	}
+
+	var extension string
+	switch http.DetectContentType(data) {
+	case "image/png":
+		extension = ".png"
+	case "image/jpeg":
+		extension = ".jpg"
+	case "image/gif":
+		extension = ".gif"
+	default:
+		return f.SetCellValue(sheet, cell, exportCellText("Логотип", data))
+	}
+
+	if err := f.SetRowHeight(sheet, row, 48); err != nil {
+		return err
+	}
+	return f.AddPictureFromBytes(sheet, cell, &excelize.Picture{
+		Extension: extension,
+		File:      data,
+		Format: &excelize.GraphicOptions{
+			AutoFit:         true,
+			LockAspectRatio: true,
+			Positioning:     "oneCell",
+		},
+	})
+}
+
+// exportWorkbookXLSX asks for a file name and saves all tables as one workbook
+func (d *DatabaseApp) exportWorkbookXLSX() {
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, d.window)
+			return
+		}
+		if writer == nil {
+			return // отмена
+		}
+
+		d.runWithProgress("Экспорт XLSX", func(ctx context.Context) error {
+			return finishExport(writer, writeXLSX(ctx, writer, d.repository()))
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Ошибка экспорта: %v", err))
+				return
+			}
+			d.showMessage("Успех", fmt.Sprintf("Книга Excel сохранена\n%s", writer.URI().Path()))
+		})
+	}, d.window)
+
+	saveDialog.SetFileName("tachki.xlsx")
+	saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".xlsx"}))
+	saveDialog.Show()
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/test"
+	"github.com/xuri/excelize/v2"
+)
+
+// testLogo returns a small PNG image
+func testLogo(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// findColumn returns the cell name of column title in row of sheet
+func findColumn(t *testing.T, f *excelize.File, sheet, title string, row int) string {
+	t.Helper()
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for col, name := range rows[0] {
+		if name == title {
+			cell, _ := excelize.CoordinatesToCellName(col+1, row)
+			return cell
+		}
+	}
+	t.Fatalf("%s: нет колонки %q", sheet, title)
+	return ""
+}
+
+// TestWriteXLSX checks that dates and prices are typed cells and that the
+// brand logo is embedded as a picture, for the memory and SQLite repositories
+func TestWriteXLSX(t *testing.T) {
+	logo := testLogo(t)
+	repos := map[string]Repository{"память": newMemoryRepository(), "SQLite": openTestSQLite(t)}
+	for name, repo := range repos {
+		ctx := context.Background()
+		ownerID, err := repo.AddOwner(ctx, "Иван", "Петров", "", "", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.AddCar(ctx, ownerID, 1, "Camry", 2020, "", "", 1234567); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.UpdateBrandImage(ctx, 1, logo, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err := writeXLSX(ctx, &buf, repo); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		f, err := excelize.OpenReader(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if sheets := f.GetSheetList(); len(sheets) != len(viewTables) {
+			t.Errorf("%s: листы %v", name, sheets)
+		}
+
+		// Дата и цена записаны числами с форматом ячейки, а не текстом
+		for _, c := range []struct{ sheet, column string }{
+			{"Владельцы", "Дата получения прав"},
+			{"Автомобили", "Цена покупки"},
+			{"Автомобили", "Дата покупки"},
+		} {
+			cell := findColumn(t, f, c.sheet, c.column, 2)
+			raw, err := f.GetCellValue(c.sheet, cell, excelize.Options{RawCellValue: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := strconv.ParseFloat(raw, 64); err != nil {
+				t.Errorf("%s: %s/%s = %q, ожидалось число", name, c.sheet, c.column, raw)
+			}
+			style, err := f.GetCellStyle(c.sheet, cell)
+			if err != nil || style == 0 {
+				t.Errorf("%s: у ячейки %s/%s нет формата", name, c.sheet, c.column)
+			}
+		}
+		price, _ := f.GetCellValue("Автомобили", findColumn(t, f, "Автомобили", "Цена покупки", 2))
+		if price != "1,234,567" {
+			t.Errorf("%s: цена отформатирована как %q", name, price)
+		}
+
+		pictures, err := f.GetPictures("Марки автомобилей", findColumn(t, f, "Марки автомобилей", "Логотип", 2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pictures) != 1 || !bytes.Equal(pictures[0].File, logo) {
+			t.Errorf("%s: логотип не встроен, картинок %d", name, len(pictures))
+		}
+		f.Close()
+	}
+}
+
+// TestFinishExport checks that a failed export leaves no file behind
+func TestFinishExport(t *testing.T) {
+	test.NewApp()
+	dir := t.TempDir()
+
+	for _, tt := range []struct {
+		name  string
+		err   error
+		keeps bool
+	}{
+		{"ok.xlsx", nil, true},
+		{"failed.xlsx", errors.New("ошибка запроса"), false},
+	} {
+		path := filepath.Join(dir, tt.name)
+		writer, err := storage.Writer(storage.NewFileURI(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte("PK")); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := finishExport(writer, tt.err); !errors.Is(err, tt.err) {
+			t.Errorf("%s: %v, ожидалось %v", tt.name, err, tt.err)
+		}
+		if _, err := os.Stat(path); (err == nil) != tt.keeps {
+			t.Errorf("%s: файл существует = %v, ожидалось %v", tt.name, err == nil, tt.keeps)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// healthState is the state of the database connection shown in the footer
+type healthState int
+
+const (
+	healthConnected    healthState = iota
+	healthDegraded                 // проверки не проходят, идут повторы
+	healthDisconnected             // повторы исчерпаны или ошибка не временная
+)
+
+const (
+	healthInterval    = 15 * time.Second // период проверки при нормальной связи
+	healthPingTimeout = 5 * time.Second
+	healthRetryMin    = time.Second // первая пауза перед повтором, далее вдвое больше
+	healthRetryMax    = 30 * time.Second
+	healthMaxRetries  = 4 // неудачных повторов до признания связи потерянной
+)
+
+// healthMonitor pings the database in the background, shows the state of the
+// connection in the footer and offers to reconnect when it is lost
+type healthMonitor struct {
+	d     *DatabaseApp
+	label *widget.Label
+	check chan struct{} // внеочередная проверка
+	stop  chan struct{}
+
+	mu        sync.Mutex // защищает state, reconnect и paused
+	state     healthState
+	reconnect dialog.Dialog // открытый диалог переподключения
+	paused    bool          // сеанс заблокирован: проверки и диалог не нужны
+}
+
+func newHealthMonitor(d *DatabaseApp) *healthMonitor {
+	m := &healthMonitor{
+		d:     d,
+		label: widget.NewLabel(""),
+		check: make(chan struct{}, 1),
+		stop:  make(chan struct{}),
+	}
+	m.showState(healthConnected, nil, 0)
+	return m
+}
+
+// Start begins the periodic checks
+func (m *healthMonitor) Start() {
+	go m.run()
+}
+
+// Stop ends the checks, e.g. when returning to the login screen
+func (m *healthMonitor) Stop() {
+	close(m.stop)
+}
+
+// Pause suspends the checks while the session is locked and closes the
+// reconnect dialog, so that it cannot appear over the lock screen
+func (m *healthMonitor) Pause() {
+	m.mu.Lock()
+	m.paused = true
+	// После разблокировки потерянная связь снова откроет диалог
+	if m.state == healthDisconnected {
+		m.state = healthDegraded
+	}
+	m.mu.Unlock()
+	m.closeReconnectDialog()
+}
+
+// Resume restarts the checks after the session is unlocked
+func (m *healthMonitor) Resume() {
+	m.mu.Lock()
+	m.paused = false
+	m.mu.Unlock()
+	m.CheckNow()
+}
+
+func (m *healthMonitor) isPaused() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.paused
+}
+
+// CheckNow schedules an immediate check, e.g. after a failed query
+func (m *healthMonitor) CheckNow() {
+	select {
+	case m.check <- struct{}{}:
+	default:
+	}
+}
+
+func (m *healthMonitor) run() {
+	failures := 0
+	timer := time.NewTimer(healthInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-timer.C:
+		case <-m.check:
+			if !timer.Stop() {
+				<-timer.C
+			}
+		}
+
+		if m.isPaused() {
+			timer.Reset(healthInterval)
+			continue
+		}
+
+		err := m.ping()
+		delay := healthInterval
+		switch {
+		case err == nil:
+			failures = 0
+			m.setState(healthConnected, nil, 0)
+		case isTransientError(err) && failures < healthMaxRetries:
+			failures++
+			delay = healthRetryMin << (failures - 1)
+			if delay > healthRetryMax {
+				delay = healthRetryMax
+			}
+			m.setState(healthDegraded, err, delay)
+		default:
+			failures++
+			delay = healthRetryMax
+			m.setState(healthDisconnected, err, delay)
+		}
+		timer.Reset(delay)
+	}
+}
+
+func (m *healthMonitor) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), healthPingTimeout)
+	defer cancel()
+	return m.d.repository().Ping(ctx)
+}
+
+// stopped reports whether Stop was called
+func (m *healthMonitor) stopped() bool {
+	select {
+	case <-m.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// setState shows the new state; losing the connection opens the reconnect
+// dialog, restoring it closes the dialog
+func (m *healthMonitor) setState(state healthState, err error, retry time.Duration) {
+	m.mu.Lock()
+	if m.paused {
+		// Проверка началась до блокировки сеанса
+		m.mu.Unlock()
+		return
+	}
+	previous := m.state
+	m.state = state
+	m.mu.Unlock()
+
+	m.d.onUI(func() {
+		// Проверка могла завершиться уже после выхода к экрану входа
+		// или блокировки сеанса
+		if m.stopped() || m.isPaused() {
+			return
+		}
+		m.showState(state, err, retry)
+		switch {
+		case state == healthDisconnected && previous != healthDisconnected:
+			m.showReconnectDialog(err)
+		case state == healthConnected && previous != healthConnected:
+			m.closeReconnectDialog()
+		}
+	})
+}
+
+// showState updates the footer label
+func (m *healthMonitor) showState(state healthState, err error, retry time.Duration) {
+	switch state {
+	case healthConnected:
+		backend, _, _ := m.d.connection()
+		m.label.SetText(fmt.Sprintf("● Подключено к %s", backend))
+		m.label.Importance = widget.SuccessImportance
+	case healthDegraded:
+		m.label.SetText(fmt.Sprintf("◐ Связь нестабильна, повтор через %v", retry))
+		m.label.Importance = widget.WarningImportance
+	default:
+		m.label.SetText("○ Нет подключения к базе данных")
+		m.label.Importance = widget.DangerImportance
+	}
+	m.label.Refresh()
+}
+
+// showReconnectDialog offers to reconnect. The main window stays as it is,
+// so the contents of open forms are not lost.
+func (m *healthMonitor) showReconnectDialog(cause error) {
+	m.mu.Lock()
+	open := m.reconnect != nil
+	m.mu.Unlock()
+	if open {
+		return
+	}
+
+	message := widget.NewLabel(fmt.Sprintf("Соединение с базой данных потеряно:\n%v\n\n"+
+		"Введённые данные на вкладках сохранятся. Проверка связи продолжается в фоне.", cause))
+	message.Wrapping = fyne.TextWrapWord
+
+	var dlg *dialog.CustomDialog
+	reconnectBtn := widget.NewButtonWithIcon("Переподключиться", theme.ViewRefreshIcon(), nil)
+	reconnectBtn.Importance = widget.HighImportance
+	laterBtn := widget.NewButton("Продолжить без подключения", func() {
+		dlg.Hide()
+	})
+	loginBtn := widget.NewButtonWithIcon("К экрану входа", theme.LogoutIcon(), func() {
+		dlg.Hide()
+		m.d.returnToLogin()
+	})
+
+	reconnectBtn.OnTapped = func() {
+		m.d.runInBackground("Переподключение", m.d.reconnect, func(err error) {
+			if err != nil {
+				message.SetText(fmt.Sprintf("Не удалось переподключиться:\n%v\n\n"+
+					"Проверьте сеть и доступность сервера и повторите попытку.", err))
+				return
+			}
+			m.CheckNow()
+		}, reconnectBtn, laterBtn, loginBtn)
+	}
+
+	dlg = dialog.NewCustomWithoutButtons("Нет подключения", message, m.d.window)
+	dlg.SetButtons([]fyne.CanvasObject{loginBtn, laterBtn, reconnectBtn})
+	dlg.SetOnClosed(func() {
+		m.mu.Lock()
+		m.reconnect = nil
+		m.mu.Unlock()
+	})
+	dlg.Resize(fyne.NewSize(520, 240))
+	m.mu.Lock()
+	m.reconnect = dlg
+	m.mu.Unlock()
+	dlg.Show()
+}
+
+func (m *healthMonitor) closeReconnectDialog() {
+	m.mu.Lock()
+	dlg := m.reconnect
+	m.mu.Unlock()
+	if dlg != nil {
+		dlg.Hide()
+	}
+}
+
+// isTransientError reports network failures and timeouts that may pass on
+// their own, as opposed to e.g. a rejected login
+func isTransientError(err error) bool {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.As(err, &netErr):
+		return true
+	}
+	return false
+}
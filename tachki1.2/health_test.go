@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+)
+
+// TestHealthMonitorReconnectRace swaps the connection from the background
+// while the monitor and background operations use it; run with -race.
+func TestHealthMonitorReconnectRace(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	d := &DatabaseApp{app: a, window: a.NewWindow("test"), activity: newActivityIndicator()}
+	if err := d.connectDB(backendSQLite, filepath.Join(t.TempDir(), "cars.db")); err != nil {
+		t.Fatal(err)
+	}
+	d.user = AppUser{Name: "test", Role: roleAdmin}
+
+	health := newHealthMonitor(d)
+	d.setMonitor(health)
+	health.Start()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				// Закрытое подключение не отвечает на Ping, и reconnect его заменяет
+				if j%5 == 0 {
+					d.repository().Close()
+				}
+				if err := d.reconnect(context.Background()); err != nil {
+					t.Error(err)
+					return
+				}
+				health.CheckNow()
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	for i := 0; i < 20; i++ {
+		d.runInBackground("Проверка", func(ctx context.Context) error {
+			d.reportError(d.repository().Ping(ctx))
+			return nil
+		}, func(error) { done <- struct{}{} })
+	}
+	for i := 0; i < 20; i++ {
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatal("фоновые операции не завершились")
+		}
+	}
+	wg.Wait()
+
+	if m := d.setMonitor(nil); m != nil {
+		m.Stop()
+	}
+	if repo := d.setRepository(nil); repo != nil {
+		repo.Close()
+	}
+}
+
+// TestReconnectDuringConnect runs reconnect while connectDB switches the
+// database; run with -race.
+func TestReconnectDuringConnect(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	d := &DatabaseApp{app: a, window: a.NewWindow("test")}
+	dir := t.TempDir()
+	if err := d.connectDB(backendSQLite, filepath.Join(dir, "a.db")); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			if err := d.connectDB(backendSQLite, filepath.Join(dir, "b.db")); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		// Ошибки ожидаемы: connectDB закрывает подключение, которое проверяет reconnect
+		d.reconnect(context.Background())
+	}
+	wg.Wait()
+
+	if repo := d.setRepository(nil); repo != nil {
+		repo.Close()
+	}
+}
+
+// TestHealthMonitorPause checks that a paused monitor ignores the checks
+// and reports a lost connection again after Resume
+func TestHealthMonitorPause(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	d := &DatabaseApp{app: a, window: a.NewWindow("test")}
+	m := newHealthMonitor(d)
+	m.state = healthDisconnected
+
+	m.Pause()
+	m.setState(healthConnected, nil, 0)
+	m.mu.Lock()
+	state := m.state
+	m.mu.Unlock()
+	if state != healthDegraded {
+		t.Errorf("состояние во время паузы %v, ожидалось healthDegraded", state)
+	}
+
+	m.Resume()
+	if m.isPaused() {
+		t.Error("проверки не возобновлены")
+	}
+	select {
+	case <-m.check:
+	default:
+		t.Error("после Resume не запрошена проверка")
+	}
+}
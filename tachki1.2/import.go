@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// importField describes a target column of the CSV import
+type importField struct {
+	key      string
+	label    string
+	required bool
+	aliases  []string // CSV headers matched automatically (lower case)
+}
+
+var ownerImportFields = []importField{
+	{"first_name", "Имя", true, []string{"имя", "first_name", "first"}},
+	{"last_name", "Фамилия", true, []string{"фамилия", "last_name", "last"}},
+	{"phone", "Телефон", false, []string{"телефон", "phone"}},
+	{"email", "Email", false, []string{"email", "e-mail", "почта"}},
+	{"category", "Категория прав", true, []string{"категория", "категория прав", "category", "category_code", "license_category_id"}},
+}
+
+var carImportFields = []importField{
+	{"owner", "Владелец (ID или имя)", true, []string{"владелец", "owner", "owner_id"}},
+	{"brand", "Марка (название или ID)", true, []string{"марка", "brand", "brand_id"}},
+	{"model", "Модель", true, []string{"модель", "model"}},
+	{"year", "Год выпуска", false, []string{"год", "год выпуска", "year"}},
+	{"color", "Цвет", false, []string{"цвет", "color"}},
+	{"vin", "VIN код", false, []string{"vin", "vin код", "vin_code"}},
+	{"price", "Цена", false, []string{"цена", "цена покупки", "price"}},
+}
+
+func importFieldsFor(target string) []importField {
+	if target == "cars" {
+		return carImportFields
+	}
+	return ownerImportFields
+}
+
+// readCSV reads a whole CSV file. The UTF-8 BOM is skipped and the
+// delimiter (comma or semicolon, as saved by Excel) is detected from the header.
+func readCSV(r io.Reader) (header []string, records [][]string, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte(utf8BOM))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	all, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(all) == 0 {
+		return nil, nil, fmt.Errorf("файл пуст")
+	}
+	return all[0], all[1:], nil
+}
+
+// guessColumn returns the CSV column matching one of the field aliases, or -1
+func guessColumn(header []string, field importField) int {
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for _, alias := range field.aliases {
+			if name == alias {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// importRowError is a problem found in one CSV line
+type importRowError struct {
+	line int
+	err  error
+}
+
+// importPlan holds the validated rows of a CSV file, ready for ImportRepository
+type importPlan struct {
+	target string
+	total  int
+	lines  []int // CSV line of each row in owners/cars
+	owners []OwnerImport
+	cars   []Car
+	errors []importRowError
+}
+
+// planImport validates every record with the add form validators and resolves
+// categories, owners and brands. mapping maps field keys to CSV column indexes.
+func planImport(ctx context.Context, repo Repository, target string, records [][]string, mapping map[string]int) (*importPlan, error) {
+	plan := &importPlan{target: target, total: len(records)}
+
+	cell := func(record []string, key string) string {
+		col, ok := mapping[key]
+		if !ok || col < 0 || col >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[col])
+	}
+
+	var resolve importResolver
+	if err := resolve.load(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	for i, record := range records {
+		line := i + 2 // строка 1 — заголовок
+		var errs []error
+
+		if target == "cars" {
+			ownerID, err := resolve.owner(cell(record, "owner"))
+			errs = append(errs, err)
+			brandID, err := resolve.brand(cell(record, "brand"))
+			errs = append(errs, err)
+
+			model, yearText, vin, priceText := cell(record, "model"), cell(record, "year"), cell(record, "vin"), cell(record, "price")
+			errs = append(errs, validateModel(model), validateYear(yearText), validateVIN(vin), validatePrice(priceText))
+
+			if err := errors.Join(errs...); err != nil {
+				plan.errors = append(plan.errors, importRowError{line, err})
+				continue
+			}
+			year, _ := strconv.Atoi(yearText)
+			price, _ := strconv.ParseFloat(priceText, 64)
+			plan.cars = append(plan.cars, Car{
+				OwnerID: ownerID,
+				BrandID: brandID,
+				Model:   model,
+				Year:    year,
+				Color:   cell(record, "color"),
+				VIN:     vin,
+				Price:   price,
+			})
+		} else {
+			first, last, phone, email := cell(record, "first_name"), cell(record, "last_name"), cell(record, "phone"), cell(record, "email")
+			errs = append(errs, validateFirstName(first), validateLastName(last), validatePhone(phone), validateEmail(email))
+			categoryID, err := resolve.category(cell(record, "category"))
+			errs = append(errs, err)
+
+			if err := errors.Join(errs...); err != nil {
+				plan.errors = append(plan.errors, importRowError{line, err})
+				continue
+			}
+			plan.owners = append(plan.owners, OwnerImport{first, last, phone, email, categoryID})
+		}
+		plan.lines = append(plan.lines, line)
+	}
+
+	return plan, nil
+}
+
+// execute inserts the valid rows in one transaction; commit=false only checks
+// them against the database. Database errors are added to the report.
+func (p *importPlan) execute(ctx context.Context, repo ImportRepository, commit bool) error {
+	var rowErrors map[int]error
+	var err error
+	if p.target == "cars" {
+		rowErrors, err = repo.ImportCars(ctx, p.cars, commit)
+	} else {
+		rowErrors, err = repo.ImportOwners(ctx, p.owners, commit)
+	}
+	if err != nil {
+		return err
+	}
+
+	for i, rowErr := range rowErrors {
+		p.errors = append(p.errors, importRowError{p.lines[i], rowErr})
+	}
+	sort.Slice(p.errors, func(i, j int) bool { return p.errors[i].line < p.errors[j].line })
+	return nil
+}
+
+// importResolver looks up reference values by name or ID
+type importResolver struct {
+	categories []DriverCategory
+	owners     []Owner
+	brands     []CarBrand
+}
+
+func (r *importResolver) load(ctx context.Context, repo Repository) error {
+	var err error
+	if r.categories, err = repo.GetDriverCategories(ctx); err != nil {
+		return err
+	}
+	if r.owners, err = repo.GetOwners(ctx); err != nil {
+		return err
+	}
+	r.brands, err = repo.GetCarBrands(ctx)
+	return err
+}
+
+func (r *importResolver) category(value string) (int, error) {
+	if value == "" {
+		return 0, fmt.Errorf("не указана категория прав")
+	}
+	for _, cat := range r.categories {
+		if strings.EqualFold(cat.Code, value) || strconv.Itoa(cat.ID) == value {
+			return cat.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("категория прав %q не найдена", value)
+}
+
+func (r *importResolver) brand(value string) (int, error) {
+	if value == "" {
+		return 0, fmt.Errorf("не указана марка")
+	}
+	for _, brand := range r.brands {
+		if strings.EqualFold(brand.Name, value) || strconv.Itoa(brand.ID) == value {
+			return brand.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("марка %q не найдена", value)
+}
+
+// owner accepts an owner ID, "Имя Фамилия" or "Фамилия Имя"
+func (r *importResolver) owner(value string) (int, error) {
+	if value == "" {
+		return 0, fmt.Errorf("не указан владелец")
+	}
+	if id, err := strconv.Atoi(value); err == nil {
+		for _, o := range r.owners {
+			if o.ID == id {
+				return id, nil
+			}
+		}
+		return 0, fmt.Errorf("владелец с ID %d не найден", id)
+	}
+
+	name := strings.Join(strings.Fields(value), " ")
+	found := 0
+	for _, o := range r.owners {
+		if strings.EqualFold(name, o.FirstName+" "+o.LastName) || strings.EqualFold(name, o.LastName+" "+o.FirstName) {
+			if found != 0 {
+				return 0, fmt.Errorf("владелец %q неоднозначен, укажите ID", value)
+			}
+			found = o.ID
+		}
+	}
+	if found == 0 {
+		return 0, fmt.Errorf("владелец %q не найден", value)
+	}
+	return found, nil
+}
+
+// --- Wizard UI ---
+
+// showImportWizard imports owners or cars (target "owners"/"cars") from a CSV file:
+// file → column mapping → validation report → commit
+func (d *DatabaseApp) showImportWizard(target string) {
+	fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, d.window)
+			return
+		}
+		if reader == nil {
+			return // отмена
+		}
+		defer reader.Close()
+
+		header, records, err := readCSV(reader)
+		if err != nil {
+			d.showMessage("Ошибка", fmt.Sprintf("Не удалось прочитать CSV: %v", err))
+			return
+		}
+		if len(records) == 0 {
+			d.showMessage("Ошибка", "В файле нет строк с данными")
+			return
+		}
+		d.showImportMapping(target, header, records)
+	}, d.window)
+
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".csv"}))
+	fd.Show()
+}
+
+func (d *DatabaseApp) showImportMapping(target string, header []string, records [][]string) {
+	const skip = "(не импортировать)"
+	options := append([]string{skip}, header...)
+
+	fields := importFieldsFor(target)
+	selects := make([]*widget.Select, len(fields))
+	form := widget.NewForm()
+	for i, field := range fields {
+		sel := widget.NewSelect(options, nil)
+		sel.SetSelectedIndex(guessColumn(header, field) + 1)
+		selects[i] = sel
+
+		label := field.label + ":"
+		if field.required {
+			label = field.label + " *:"
+		}
+		form.Append(label, sel)
+	}
+
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Строк в файле: %d. Сопоставьте колонки CSV с полями (* — обязательные).", len(records))),
+		form,
+	)
+
+	dialog.ShowCustomConfirm("Импорт CSV: колонки", "Проверить", "Отмена", content, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		mapping := make(map[string]int)
+		for i, field := range fields {
+			col := selects[i].SelectedIndex() - 1
+			if field.required && col < 0 {
+				d.showMessage("Ошибка", fmt.Sprintf("Выберите колонку для поля «%s»", field.label))
+				return
+			}
+			mapping[field.key] = col
+		}
+
+		var plan *importPlan
+		d.runWithProgress("Импорт CSV: проверка", func(ctx context.Context) error {
+			var err error
+			plan, err = planImport(ctx, d.repository(), target, records, mapping)
+			if err == nil {
+				err = plan.execute(ctx, d.repository(), false)
+			}
+			return err
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Ошибка проверки: %v", err))
+				return
+			}
+			d.showImportReport(plan)
+		})
+	}, d.window)
+}
+
+// showImportReport lists the row errors; the import is offered only when there are none
+func (d *DatabaseApp) showImportReport(plan *importPlan) {
+	summary := widget.NewLabel(fmt.Sprintf("Строк: %d, с ошибками: %d", plan.total, len(plan.errors)))
+
+	if len(plan.errors) > 0 {
+		list := widget.NewList(
+			func() int { return len(plan.errors) },
+			func() fyne.CanvasObject { return widget.NewLabel("") },
+			func(i widget.ListItemID, o fyne.CanvasObject) {
+				rowErr := plan.errors[i]
+				text := strings.ReplaceAll(rowErr.err.Error(), "\n", "; ")
+				o.(*widget.Label).SetText(fmt.Sprintf("Строка %d: %s", rowErr.line, text))
+			},
+		)
+		content := container.NewBorder(
+			container.NewVBox(summary, widget.NewLabel("Исправьте файл и повторите импорт. Ничего не сохранено.")),
+			nil, nil, nil, list)
+
+		report := dialog.NewCustom("Импорт CSV: ошибки", "Закрыть", content, d.window)
+		report.Resize(fyne.NewSize(700, 450))
+		report.Show()
+		return
+	}
+
+	content := container.NewVBox(summary, widget.NewLabel("Все строки прошли проверку. Импортировать?"))
+	dialog.ShowCustomConfirm("Импорт CSV: проверка", "Импортировать", "Отмена", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		d.runWithProgress("Импорт CSV", func(ctx context.Context) error {
+			return plan.execute(ctx, d.repository(), true)
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Не удалось выполнить импорт: %v", err))
+				return
+			}
+			if len(plan.errors) > 0 {
+				// Данные изменились после проверки — транзакция откатилась
+				d.showImportReport(plan)
+				return
+			}
+			d.showMessage("Успех", fmt.Sprintf("Импортировано строк: %d", plan.total))
+		})
+	}, d.window)
+}
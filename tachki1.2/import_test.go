@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name, data string
+		header     []string
+		records    int
+	}{
+		{"запятая", "first_name,last_name\nИван,Петров\n", []string{"first_name", "last_name"}, 1},
+		{"точка с запятой и BOM", utf8BOM + "Имя;Фамилия;Категория\nИван;Петров;B\nАнна;Смирнова;C\n", []string{"Имя", "Фамилия", "Категория"}, 2},
+		{"кавычки с разделителем", "Имя;Фамилия\n\"Иван; мл.\";Петров\n", []string{"Имя", "Фамилия"}, 1},
+	}
+	for _, tt := range tests {
+		header, records, err := readCSV(strings.NewReader(tt.data))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if strings.Join(header, "|") != strings.Join(tt.header, "|") || len(records) != tt.records {
+			t.Errorf("%s: заголовок %q, строк %d", tt.name, header, len(records))
+		}
+	}
+
+	if _, _, err := readCSV(strings.NewReader("")); err == nil {
+		t.Error("пустой файл прочитан без ошибки")
+	}
+}
+
+func TestGuessColumn(t *testing.T) {
+	header := []string{"Фамилия", " Имя ", "E-mail", "Примечание"}
+	want := map[string]int{"first_name": 1, "last_name": 0, "phone": -1, "email": 2, "category": -1}
+	for _, field := range ownerImportFields {
+		if got := guessColumn(header, field); got != want[field.key] {
+			t.Errorf("%s: колонка %d, ожидалась %d", field.key, got, want[field.key])
+		}
+	}
+}
+
+// ownerMapping maps the owner fields to the columns of ownerCSV
+var ownerMapping = map[string]int{"first_name": 0, "last_name": 1, "phone": 2, "email": 3, "category": 4}
+
+func TestPlanImportRowErrors(t *testing.T) {
+	repo := newMemoryRepository()
+	records := [][]string{
+		{"Иван", "Петров", "", "ivan@example.com", "B"},
+		{"", "Смирнова", "", "", "C"},
+		{"Анна", "Смирнова", "", "не почта", "Z"},
+		{"Олег", "Сидоров", "+7 900 000-00-00", "", "c"},
+	}
+
+	plan, err := planImport(context.Background(), repo, "owners", records, ownerMapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.total != 4 || len(plan.owners) != 2 {
+		t.Fatalf("строк %d, принято %d", plan.total, len(plan.owners))
+	}
+	// Номера строк считаются с заголовка, строки данных начинаются со второй
+	if len(plan.lines) != 2 || plan.lines[0] != 2 || plan.lines[1] != 5 {
+		t.Errorf("строки принятых записей: %v", plan.lines)
+	}
+	if len(plan.errors) != 2 || plan.errors[0].line != 3 || plan.errors[1].line != 4 {
+		t.Fatalf("ошибки: %+v", plan.errors)
+	}
+	// Все ошибки строки собираются вместе
+	if text := plan.errors[1].err.Error(); !strings.Contains(text, "@") || !strings.Contains(text, `"Z"`) {
+		t.Errorf("ошибки строки 4: %s", text)
+	}
+}
+
+func TestPlanImportCars(t *testing.T) {
+	repo := newMemoryRepository()
+	ctx := context.Background()
+	if _, err := repo.AddOwner(ctx, "Иван", "Петров", "", "", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	mapping := map[string]int{"owner": 0, "brand": 1, "model": 2, "year": 3, "color": -1, "vin": 4, "price": 5}
+	records := [][]string{
+		{"Петров Иван", "toyota", "Camry", "2020", "JT2BF22K1W0123456", "25000"},
+		{"1", "Zaporozhets", "968", "1975", "", ""},
+		{"Сидоров Олег", "BMW", "X5", "два", "", "-1"},
+	}
+	plan, err := planImport(ctx, repo, "cars", records, mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.cars) != 1 || plan.cars[0].OwnerID != 1 || plan.cars[0].BrandID != 1 || plan.cars[0].Price != 25000 {
+		t.Fatalf("принятые автомобили: %+v", plan.cars)
+	}
+	if len(plan.errors) != 2 || plan.errors[0].line != 3 || plan.errors[1].line != 4 {
+		t.Errorf("ошибки: %+v", plan.errors)
+	}
+}
+
+// TestImportDryRun checks that a dry run reports database errors without
+// saving anything, and that the commit saves every row
+func TestImportDryRun(t *testing.T) {
+	repos := map[string]Repository{"память": newMemoryRepository(), "SQLite": openTestSQLite(t)}
+	for name, repo := range repos {
+		ctx := context.Background()
+		records := [][]string{
+			{"Иван", "Петров", "", "", "B"},
+			{"Анна", "Смирнова", "", "", "C"},
+		}
+
+		plan, err := planImport(ctx, repo, "owners", records, ownerMapping)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := plan.execute(ctx, repo, false); err != nil {
+			t.Fatalf("%s: проверка: %v", name, err)
+		}
+		if len(plan.errors) != 0 {
+			t.Fatalf("%s: ошибки проверки: %+v", name, plan.errors)
+		}
+		owners, err := repo.GetOwners(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(owners) != 0 {
+			t.Fatalf("%s: проверка сохранила %d владельцев", name, len(owners))
+		}
+
+		if err := plan.execute(ctx, repo, true); err != nil {
+			t.Fatalf("%s: импорт: %v", name, err)
+		}
+		owners, err = repo.GetOwners(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(owners) != 2 {
+			t.Errorf("%s: импортировано %d владельцев, ожидалось 2", name, len(owners))
+		}
+	}
+}
+
+// TestImportDatabaseError reports a database error at its CSV line and
+// rolls back the other rows
+func TestImportDatabaseError(t *testing.T) {
+	repos := map[string]Repository{"память": newMemoryRepository(), "SQLite": openTestSQLite(t)}
+	for name, repo := range repos {
+		ctx := context.Background()
+		ownerID, err := repo.AddOwner(ctx, "Иван", "Петров", "", "", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		plan := &importPlan{
+			target: "cars",
+			total:  2,
+			lines:  []int{2, 3},
+			cars: []Car{
+				{OwnerID: ownerID, BrandID: 1, Model: "Camry", Year: 2020, VIN: "JT2BF22K1W0123456"},
+				{OwnerID: ownerID, BrandID: 1, Model: "Corolla", Year: 2021, VIN: "JT2BF22K1W0123456"},
+			},
+		}
+		if err := plan.execute(ctx, repo, true); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(plan.errors) != 1 || plan.errors[0].line != 3 {
+			t.Fatalf("%s: ошибки: %+v", name, plan.errors)
+		}
+		cars, err := repo.GetCarsByOwner(ctx, ownerID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cars) != 0 {
+			t.Errorf("%s: после ошибки сохранено %d автомобилей", name, len(cars))
+		}
+	}
+}
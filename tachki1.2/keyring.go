@@ -0,0 +1,192 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// keyring stores the passwords of the connection profiles in a local file
+// encrypted with AES-256-GCM. The key is generated on first use and kept in
+// keyring.key next to it, readable only by the user (0600). This keeps the
+// passwords out of the preferences and away from a casual look, but anyone who
+// can read the user's files, or a backup holding both files, can decrypt them:
+// the protection is that of the user's account, not of a keychain.
+//
+// When the key is lost or does not match keyring.dat, the saved passwords
+// cannot be recovered: the store is reset and the passwords are asked again.
+// The reset is kept until Cleared reports it, so that the user is told.
+type keyring struct {
+	path    string // зашифрованные пароли
+	keyPath string // ключ шифрования
+	cleared error  // причина сброса, о которой ещё не сообщили
+}
+
+// keyringKeySize is the AES-256 key length in bytes
+const keyringKeySize = 32
+
+// errKeyringKeyLost means keyring.key is missing or damaged
+var errKeyringKeyLost = errors.New("ключ файла паролей утерян или повреждён")
+
+// newKeyring opens the keyring in dir (the app storage folder)
+func newKeyring(dir string) *keyring {
+	return &keyring{
+		path:    filepath.Join(dir, "keyring.dat"),
+		keyPath: filepath.Join(dir, "keyring.key"),
+	}
+}
+
+// Get returns the saved password of a profile
+func (k *keyring) Get(profile string) (string, bool, error) {
+	secrets, err := k.load()
+	if err != nil {
+		return "", false, err
+	}
+	password, ok := secrets[profile]
+	return password, ok, nil
+}
+
+// Set saves the password of a profile
+func (k *keyring) Set(profile, password string) error {
+	secrets, err := k.load()
+	if err != nil {
+		return err
+	}
+	secrets[profile] = password
+	return k.save(secrets)
+}
+
+// Delete forgets the password of a profile
+func (k *keyring) Delete(profile string) error {
+	secrets, err := k.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[profile]; !ok {
+		return nil
+	}
+	delete(secrets, profile)
+	return k.save(secrets)
+}
+
+// Check opens the store, resetting it if the passwords cannot be decrypted
+func (k *keyring) Check() error {
+	_, err := k.load()
+	return err
+}
+
+// Cleared returns the cause of a reset of the saved passwords, once; nil if
+// the store was not reset since the last call
+func (k *keyring) Cleared() error {
+	cause := k.cleared
+	k.cleared = nil
+	return cause
+}
+
+func (k *keyring) load() (map[string]string, error) {
+	secrets := make(map[string]string)
+
+	sealed, err := os.ReadFile(k.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := k.cipher(false)
+	if errors.Is(err, errKeyringKeyLost) {
+		return secrets, k.reset(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return secrets, k.reset(fmt.Errorf("файл паролей повреждён"))
+	}
+	nonce, data := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		// Ключ не подходит к файлу: пароли уже не расшифровать
+		return secrets, k.reset(fmt.Errorf("не удалось расшифровать файл паролей: %w", err))
+	}
+
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("файл паролей повреждён: %w", err)
+	}
+	return secrets, nil
+}
+
+// reset removes the passwords that can no longer be decrypted, and the key,
+// so that the next save starts a new store
+func (k *keyring) reset(cause error) error {
+	log.Printf("Сохранённые пароли сброшены: %v", cause)
+	for _, path := range []string{k.path, k.keyPath} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	k.cleared = cause
+	return nil
+}
+
+func (k *keyring) save(secrets map[string]string) error {
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	aead, err := k.cipher(true)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	sealed := aead.Seal(nonce, nonce, plain, nil)
+
+	// Запись через временный файл, чтобы сбой не оставил файл наполовину записанным
+	tmp := k.path + ".tmp"
+	if err := os.WriteFile(tmp, sealed, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, k.path)
+}
+
+// cipher reads the key; with create a missing key is generated
+func (k *keyring) cipher(create bool) (cipher.AEAD, error) {
+	key, err := os.ReadFile(k.keyPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && create:
+		key = make([]byte, keyringKeySize)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(k.keyPath), 0o700); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(k.keyPath, key, 0o600); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, os.ErrNotExist):
+		return nil, errKeyringKeyLost
+	case err != nil:
+		return nil, fmt.Errorf("не удалось прочитать ключ файла паролей: %w", err)
+	case len(key) != keyringKeySize:
+		return nil, errKeyringKeyLost
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
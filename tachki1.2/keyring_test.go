@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestKeyringRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	k := newKeyring(dir)
+
+	if err := k.Set("офис", "p@ssw0rd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Set("склад", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Пароли читаются новым экземпляром из тех же файлов
+	reopened := newKeyring(dir)
+	password, ok, err := reopened.Get("офис")
+	if err != nil || !ok || password != "p@ssw0rd" {
+		t.Fatalf("Get = %q, %v, %v", password, ok, err)
+	}
+
+	sealed, err := os.ReadFile(k.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte("p@ssw0rd")) {
+		t.Error("пароль хранится открытым текстом")
+	}
+
+	if err := reopened.Delete("офис"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := k.Get("офис"); ok {
+		t.Error("удалённый пароль найден")
+	}
+	if password, ok, _ := k.Get("склад"); !ok || password != "secret" {
+		t.Errorf("второй пароль потерян: %q, %v", password, ok)
+	}
+}
+
+// TestKeyringLostKey checks that a store whose key is missing, damaged or
+// replaced is reset instead of failing on every use, and that the reset is
+// reported once
+func TestKeyringLostKey(t *testing.T) {
+	damage := map[string]func(k *keyring) error{
+		"ключ удалён": func(k *keyring) error { return os.Remove(k.keyPath) },
+		"ключ обрезан": func(k *keyring) error {
+			return os.WriteFile(k.keyPath, []byte("short"), 0o600)
+		},
+		"другой ключ": func(k *keyring) error {
+			return os.WriteFile(k.keyPath, bytes.Repeat([]byte{1}, keyringKeySize), 0o600)
+		},
+		"файл паролей повреждён": func(k *keyring) error {
+			return os.WriteFile(k.path, []byte("xx"), 0o600)
+		},
+	}
+
+	for name, breakKeyring := range damage {
+		k := newKeyring(t.TempDir())
+		if err := k.Set("офис", "p@ssw0rd"); err != nil {
+			t.Fatal(err)
+		}
+		if k.Cleared() != nil {
+			t.Errorf("%s: сброс до повреждения", name)
+		}
+		if err := breakKeyring(k); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := k.Check(); err != nil {
+			t.Errorf("%s: Check = %v, ожидался сброс без ошибки", name, err)
+		}
+		if k.Cleared() == nil {
+			t.Errorf("%s: о сбросе не сообщено", name)
+		}
+		if k.Cleared() != nil {
+			t.Errorf("%s: о сбросе сообщено дважды", name)
+		}
+		if _, ok, err := k.Get("офис"); err != nil || ok {
+			t.Errorf("%s: Get = %v, %v, ожидался сброс без ошибки", name, ok, err)
+		}
+		if err := k.Set("офис", "new"); err != nil {
+			t.Fatalf("%s: Set после сброса: %v", name, err)
+		}
+		if password, ok, err := k.Get("офис"); err != nil || !ok || password != "new" {
+			t.Errorf("%s: после сброса Get = %q, %v, %v", name, password, ok, err)
+		}
+	}
+}
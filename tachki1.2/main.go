@@ -2,6 +2,7 @@ package main
 
 import (
 	_ "embed"
+	"os"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -18,6 +19,11 @@ var iconResource = &fyne.StaticResource{
 }
 
 func main() {
+	// Any arguments switch to the headless command-line mode
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:]))
+	}
+
 	// Create app with dark theme
 	myApp := app.NewWithID("car.database.manager")
 	myApp.Settings().SetTheme(theme.DarkTheme())
@@ -33,8 +39,9 @@ func main() {
 	window.CenterOnScreen()
 
 	dbApp := &DatabaseApp{
-		app:    myApp,
-		window: window,
+		app:      myApp,
+		window:   window,
+		activity: newActivityIndicator(),
 	}
 
 	// Show Login Screen instead of connecting immediately
@@ -44,7 +51,13 @@ func main() {
 	window.ShowAndRun()
 
 	// Clean up connection after window closes
-	if dbApp.db != nil {
-		dbApp.db.Close()
+	if dbApp.idle != nil {
+		dbApp.idle.Stop()
+	}
+	if health := dbApp.setMonitor(nil); health != nil {
+		health.Stop()
+	}
+	if repo := dbApp.setRepository(nil); repo != nil {
+		repo.Close()
 	}
 }
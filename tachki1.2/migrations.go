@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migration scripts live in migrations/<backend>/NNNN_description.sql.
+// SQL Server scripts may contain several batches separated by GO lines.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+var (
+	migrationName  = regexp.MustCompile(`^(\d+)_(.+)\.sql$`)
+	batchSeparator = regexp.MustCompile(`(?im)^\s*GO\s*$`)
+)
+
+// Migration is one versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	Script  string
+}
+
+// SchemaMigrator is implemented by backends whose schema is managed by migrations.
+// Migrations are applied before signIn on the unrestricted repository, so the
+// migrator checks the role of the user itself.
+type SchemaMigrator interface {
+	PendingMigrations(ctx context.Context) ([]Migration, error)
+	// CheckMigrationRights returns errPermissionDenied unless the user may
+	// change the schema
+	CheckMigrationRights(ctx context.Context) error
+	// ApplyMigrations checks the rights and applies the migrations in order
+	ApplyMigrations(ctx context.Context, migrations []Migration) error
+}
+
+// appUsersVersion is the migration that creates app_users on every backend
+const appUsersVersion = 5
+
+// errMigrationDenied is returned when a user other than an admin changes the schema
+var errMigrationDenied = fmt.Errorf("%w: обновить схему базы данных может только администратор", errPermissionDenied)
+
+var _ SchemaMigrator = (*sqlRepository)(nil)
+
+// loadMigrations reads the embedded scripts of one backend, ordered by version
+func loadMigrations(dir string) ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir(path.Join("migrations", dir))
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		match := migrationName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		script, err := migrationFiles.ReadFile(path.Join("migrations", dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    strings.ReplaceAll(match[2], "_", " "),
+			Script:  string(script),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// schemaVersion returns the latest applied migration, creating schema_version if needed
+func (r *sqlRepository) schemaVersion(ctx context.Context) (int, error) {
+	if _, err := r.db.ExecContext(ctx, r.dialect.schemaVersionDDL); err != nil {
+		return 0, fmt.Errorf("не удалось создать schema_version: %v", err)
+	}
+
+	var version int
+	err := r.queryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version)
+	return version, err
+}
+
+func (r *sqlRepository) PendingMigrations(ctx context.Context) ([]Migration, error) {
+	migrations, err := loadMigrations(r.dialect.migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := r.schemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > current {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// CheckMigrationRights allows schema changes to admins. A database without
+// app_users or without users has no admin yet: whoever applies seed_admin to
+// it becomes the admin.
+func (r *sqlRepository) CheckMigrationRights(ctx context.Context) error {
+	current, err := r.schemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if current < appUsersVersion {
+		return nil
+	}
+
+	var users int
+	if err := r.queryRow(ctx, "SELECT COUNT(*) FROM app_users").Scan(&users); err != nil {
+		return err
+	}
+	if users == 0 {
+		return nil
+	}
+
+	user, err := r.CurrentUser(ctx)
+	if err != nil {
+		return err
+	}
+	if user.Role != roleAdmin {
+		return errMigrationDenied
+	}
+	return nil
+}
+
+// ApplyMigrations runs each script in its own transaction and records it in
+// schema_version. Cancelling ctx rolls back the migration in progress; the
+// ones before it stay applied.
+func (r *sqlRepository) ApplyMigrations(ctx context.Context, migrations []Migration) error {
+	if len(migrations) == 0 {
+		return nil
+	}
+	if err := r.CheckMigrationRights(ctx); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if err := r.applyMigration(ctx, m); err != nil {
+			return fmt.Errorf("миграция %04d (%s): %v", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *sqlRepository) applyMigration(ctx context.Context, m Migration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, batch := range batchSeparator.Split(m.Script, -1) {
+		if strings.TrimSpace(batch) == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, batch); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, r.dialect.rebind("INSERT INTO schema_version (version, name) VALUES (@p1, @p2)"), m.Version, m.Name)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
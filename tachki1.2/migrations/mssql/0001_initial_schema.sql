@@ -0,0 +1,150 @@
+-- Начальная схема базы данных (приложение А РПЗ).
+-- Скрипт можно безопасно применить к уже созданной базе: таблицы создаются
+-- только если их нет, представление, функция и процедура пересоздаются.
+
+-- СПРАВОЧНАЯ ТАБЛИЦА 1: Категории водительских прав
+IF OBJECT_ID('dbo.driver_categories', 'U') IS NULL
+CREATE TABLE driver_categories (
+    category_id INT IDENTITY(1,1) PRIMARY KEY,
+    category_code VARCHAR(10) NOT NULL UNIQUE,
+    category_name NVARCHAR(100) NOT NULL,
+    description NVARCHAR(200)
+);
+
+-- ГЛАВНАЯ ТАБЛИЦА: Владельцы (с полем версии)
+IF OBJECT_ID('dbo.owners', 'U') IS NULL
+CREATE TABLE owners (
+    owner_id INT IDENTITY(1,1) PRIMARY KEY,
+    first_name NVARCHAR(50) NOT NULL,
+    last_name NVARCHAR(50) NOT NULL,
+    phone VARCHAR(20),
+    email VARCHAR(100),
+    license_category_id INT NOT NULL,
+    registration_date DATE DEFAULT GETDATE(),
+    total_cars INT DEFAULT 0,
+    row_version UNIQUEIDENTIFIER DEFAULT NEWID() NOT NULL,
+    CONSTRAINT FK_owners_category FOREIGN KEY (license_category_id)
+        REFERENCES driver_categories(category_id)
+);
+
+-- СПРАВОЧНАЯ ТАБЛИЦА 2: Марки автомобилей
+IF OBJECT_ID('dbo.car_brands', 'U') IS NULL
+CREATE TABLE car_brands (
+    brand_id INT IDENTITY(1,1) PRIMARY KEY,
+    brand_name NVARCHAR(50) NOT NULL UNIQUE,
+    country_origin NVARCHAR(50),
+    image_data VARBINARY(MAX),
+    founded_year INT
+);
+
+-- ЗАВИСИМАЯ ТАБЛИЦА: Автомобили (с полем версии)
+IF OBJECT_ID('dbo.cars', 'U') IS NULL
+CREATE TABLE cars (
+    car_id INT IDENTITY(1,1) PRIMARY KEY,
+    owner_id INT NOT NULL,
+    brand_id INT NOT NULL,
+    model NVARCHAR(50) NOT NULL,
+    year INT CHECK (year >= 1900 AND year <= YEAR(GETDATE())),
+    color NVARCHAR(30),
+    vin_code VARCHAR(17) UNIQUE,
+    price INT CHECK (price >= 0),
+    purchase_date DATE DEFAULT GETDATE(),
+    custom_market_price MONEY NULL,
+    row_version UNIQUEIDENTIFIER DEFAULT NEWID() NOT NULL,
+    CONSTRAINT FK_cars_owner FOREIGN KEY (owner_id)
+        REFERENCES owners(owner_id) ON DELETE CASCADE,
+    CONSTRAINT FK_cars_brand FOREIGN KEY (brand_id)
+        REFERENCES car_brands(brand_id)
+);
+
+-- ЗАПОЛНЕНИЕ СПРАВОЧНЫХ ТАБЛИЦ (только для пустой базы)
+IF NOT EXISTS (SELECT 1 FROM driver_categories)
+INSERT INTO driver_categories (category_code, category_name, description) VALUES
+    ('B', N'Категория B', N'Легковые автомобили до 3.5 тонн'),
+    ('C', N'Категория C', N'Грузовые автомобили свыше 3.5 тонн'),
+    ('D', N'Категория D', N'Автобусы'),
+    ('BE', N'Категория BE', N'Легковые автомобили с прицепом'),
+    ('CE', N'Категория CE', N'Грузовые автомобили с прицепом');
+
+IF NOT EXISTS (SELECT 1 FROM car_brands)
+INSERT INTO car_brands (brand_name, country_origin, founded_year) VALUES
+    (N'Toyota', N'Япония', 1937),
+    (N'BMW', N'Германия', 1916),
+    (N'Mercedes-Benz', N'Германия', 1926),
+    (N'Audi', N'Германия', 1909),
+    (N'Honda', N'Япония', 1948),
+    (N'Ford', N'США', 1903),
+    (N'Volkswagen', N'Германия', 1937),
+    (N'Nissan', N'Япония', 1933),
+    (N'Hyundai', N'Южная Корея', 1967),
+    (N'Kia', N'Южная Корея', 1944),
+    (N'Lada', N'Россия', 1970),
+    (N'Tesla', N'США', 2003);
+GO
+
+CREATE OR ALTER VIEW v_owner_details AS
+SELECT
+    o.owner_id,
+    o.first_name,
+    o.last_name,
+    o.phone,
+    o.email,
+    dc.category_code,
+    o.registration_date,
+    (SELECT COUNT(*) FROM cars c WHERE c.owner_id = o.owner_id) as car_count,
+    -- Вычисляем разницу в годах между датой прав и сегодняшним днем
+    DATEDIFF(year, o.registration_date, GETDATE()) as experience_years
+FROM owners o
+JOIN driver_categories dc ON o.license_category_id = dc.category_id;
+GO
+
+-- Скалярная функция для расчета текущей стоимости авто
+CREATE OR ALTER FUNCTION dbo.fn_GetCarDepreciatedValue
+(
+    @OriginalPrice MONEY,
+    @CarYear INT
+)
+RETURNS MONEY
+AS
+BEGIN
+    -- 1. Вычисляем возраст машины
+    DECLARE @Age INT = YEAR(GETDATE()) - @CarYear
+
+    -- Если машина из будущего, возраст 0
+    IF @Age < 0 SET @Age = 0
+
+    -- 2. Логика уценки: минус 8% за каждый год
+    DECLARE @DepreciationRate DECIMAL(10, 2) = 0.08 * @Age
+
+    -- 3. Ограничиваем уценку максимум до 90%
+    IF @DepreciationRate > 0.90
+        SET @DepreciationRate = 0.90
+
+    -- 4. Считаем итоговую цену
+    DECLARE @CurrentPrice MONEY = @OriginalPrice * (1.0 - @DepreciationRate)
+
+    RETURN @CurrentPrice
+END;
+GO
+
+CREATE OR ALTER PROCEDURE sp_MassPriceUpdate
+    @BrandID INT,
+    @Percentage DECIMAL(5, 2)
+AS
+BEGIN
+    BEGIN TRANSACTION
+
+    BEGIN TRY
+        UPDATE cars
+        SET
+            price = price * (1.0 + @Percentage / 100.0),
+            row_version = NEWID()
+        WHERE brand_id = @BrandID
+
+        COMMIT TRANSACTION
+    END TRY
+    BEGIN CATCH
+        ROLLBACK TRANSACTION
+    END CATCH
+END
+GO
@@ -0,0 +1,21 @@
+-- История передачи автомобилей между владельцами.
+-- Имена владельцев сохраняются на момент передачи, поэтому ссылок на owners
+-- нет: удаление бывшего владельца не должно стирать историю автомобиля.
+
+IF OBJECT_ID('dbo.ownership_history', 'U') IS NULL
+CREATE TABLE ownership_history (
+    history_id INT IDENTITY(1,1) PRIMARY KEY,
+    car_id INT NOT NULL,
+    from_owner_id INT NULL,
+    from_owner_name NVARCHAR(101) NULL,
+    to_owner_id INT NOT NULL,
+    to_owner_name NVARCHAR(101) NOT NULL,
+    transfer_date DATE NOT NULL DEFAULT GETDATE(),
+    sale_price INT NOT NULL DEFAULT 0 CHECK (sale_price >= 0),
+    CONSTRAINT FK_history_car FOREIGN KEY (car_id)
+        REFERENCES cars(car_id) ON DELETE CASCADE
+);
+GO
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = 'IX_ownership_history_car')
+CREATE INDEX IX_ownership_history_car ON ownership_history (car_id, transfer_date);
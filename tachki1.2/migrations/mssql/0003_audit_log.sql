@@ -0,0 +1,23 @@
+-- Журнал изменений: кто, когда и что поменял в owners, cars и car_brands.
+-- old_values/new_values — JSON-объекты "колонка": "значение";
+-- для INSERT old_values пуст, для DELETE — new_values.
+
+IF OBJECT_ID('dbo.audit_log', 'U') IS NULL
+CREATE TABLE audit_log (
+    audit_id INT IDENTITY(1,1) PRIMARY KEY,
+    changed_at DATETIME2 NOT NULL DEFAULT SYSDATETIME(),
+    db_user NVARCHAR(128) NOT NULL,
+    table_name NVARCHAR(64) NOT NULL,
+    record_id INT NOT NULL,
+    operation NVARCHAR(10) NOT NULL CHECK (operation IN ('INSERT', 'UPDATE', 'DELETE')),
+    old_values NVARCHAR(MAX) NULL,
+    new_values NVARCHAR(MAX) NULL
+);
+GO
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = 'IX_audit_log_changed_at')
+CREATE INDEX IX_audit_log_changed_at ON audit_log (changed_at);
+GO
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = 'IX_audit_log_record')
+CREATE INDEX IX_audit_log_record ON audit_log (table_name, record_id);
@@ -0,0 +1,56 @@
+-- Мягкое удаление: владельцы и автомобили помечаются удалёнными и попадают
+-- в корзину. deleted_with_owner отмечает автомобили, удалённые вместе
+-- с владельцем, — они восстанавливаются вместе с ним.
+
+IF COL_LENGTH('dbo.owners', 'deleted_at') IS NULL
+ALTER TABLE owners ADD
+    deleted_at DATETIME2 NULL,
+    deleted_by NVARCHAR(128) NULL;
+GO
+
+IF COL_LENGTH('dbo.cars', 'deleted_at') IS NULL
+ALTER TABLE cars ADD
+    deleted_at DATETIME2 NULL,
+    deleted_by NVARCHAR(128) NULL,
+    deleted_with_owner BIT NOT NULL CONSTRAINT DF_cars_deleted_with_owner DEFAULT 0;
+GO
+
+-- Удалённые владельцы и автомобили не показываются и не считаются
+CREATE OR ALTER VIEW v_owner_details AS
+SELECT
+    o.owner_id,
+    o.first_name,
+    o.last_name,
+    o.phone,
+    o.email,
+    dc.category_code,
+    o.registration_date,
+    (SELECT COUNT(*) FROM cars c WHERE c.owner_id = o.owner_id AND c.deleted_at IS NULL) as car_count,
+    -- Вычисляем разницу в годах между датой прав и сегодняшним днем
+    DATEDIFF(year, o.registration_date, GETDATE()) as experience_years
+FROM owners o
+JOIN driver_categories dc ON o.license_category_id = dc.category_id
+WHERE o.deleted_at IS NULL;
+GO
+
+-- Цены автомобилей в корзине не индексируются
+CREATE OR ALTER PROCEDURE sp_MassPriceUpdate
+    @BrandID INT,
+    @Percentage DECIMAL(5, 2)
+AS
+BEGIN
+    BEGIN TRANSACTION
+
+    BEGIN TRY
+        UPDATE cars
+        SET
+            price = price * (1.0 + @Percentage / 100.0),
+            row_version = NEWID()
+        WHERE brand_id = @BrandID AND deleted_at IS NULL
+
+        COMMIT TRANSACTION
+    END TRY
+    BEGIN CATCH
+        ROLLBACK TRANSACTION
+    END CATCH
+END
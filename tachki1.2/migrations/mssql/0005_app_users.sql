@@ -0,0 +1,10 @@
+-- Пользователи приложения и их роли. user_name — имя входа в базу данных
+-- (SUSER_SNAME()); не внесённые в список пользователи получают роль viewer.
+-- Первого администратора назначает миграция 0008_seed_admin.
+
+IF OBJECT_ID('dbo.app_users', 'U') IS NULL
+CREATE TABLE app_users (
+    user_name NVARCHAR(128) NOT NULL PRIMARY KEY,
+    role NVARCHAR(20) NOT NULL CHECK (role IN ('viewer', 'operator', 'admin')),
+    created_at DATETIME2 NOT NULL DEFAULT SYSDATETIME()
+);
@@ -0,0 +1,6 @@
+-- row_version у марок: API передаёт её как ETag при изменении логотипа,
+-- как у владельцев и автомобилей
+
+IF COL_LENGTH('dbo.car_brands', 'row_version') IS NULL
+ALTER TABLE car_brands ADD
+    row_version UNIQUEIDENTIFIER NOT NULL CONSTRAINT DF_car_brands_row_version DEFAULT NEWID();
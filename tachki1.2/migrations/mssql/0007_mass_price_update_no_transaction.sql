@@ -0,0 +1,22 @@
+-- sp_MassPriceUpdate вызывается внутри транзакции репозитория (вместе с
+-- записью в audit_log). Собственная транзакция процедуры откатывала внешнюю
+-- при ошибке, а CATCH без THROW скрывал ошибку от приложения. Транзакцией
+-- теперь управляет вызывающий, ошибка передаётся ему.
+
+CREATE OR ALTER PROCEDURE sp_MassPriceUpdate
+    @BrandID INT,
+    @Percentage DECIMAL(5, 2)
+AS
+BEGIN
+    BEGIN TRY
+        -- Цены автомобилей в корзине не индексируются
+        UPDATE cars
+        SET
+            price = price * (1.0 + @Percentage / 100.0),
+            row_version = NEWID()
+        WHERE brand_id = @BrandID AND deleted_at IS NULL
+    END TRY
+    BEGIN CATCH
+        THROW;
+    END CATCH
+END
@@ -0,0 +1,7 @@
+-- Первый администратор: вход, применивший миграцию к базе без пользователей.
+-- Раньше администратором становился первый подключившийся — теперь роль
+-- назначается только здесь или вкладкой «Пользователи».
+
+INSERT INTO app_users (user_name, role)
+SELECT SUSER_SNAME(), 'admin'
+WHERE NOT EXISTS (SELECT 1 FROM app_users);
@@ -0,0 +1,92 @@
+-- Начальная схема SQLite, эквивалентная migrations/mssql/0001_initial_schema.sql.
+-- Функция fn_GetCarDepreciatedValue и NEWID() регистрируются в repository_sqlite.go,
+-- логика sp_MassPriceUpdate выполняется в транзакции на стороне приложения.
+
+-- СПРАВОЧНАЯ ТАБЛИЦА 1: Категории водительских прав
+CREATE TABLE IF NOT EXISTS driver_categories (
+    category_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    category_code TEXT NOT NULL UNIQUE,
+    category_name TEXT NOT NULL,
+    description TEXT
+);
+
+-- ГЛАВНАЯ ТАБЛИЦА: Владельцы (с полем версии)
+CREATE TABLE IF NOT EXISTS owners (
+    owner_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    first_name TEXT NOT NULL,
+    last_name TEXT NOT NULL,
+    phone TEXT,
+    email TEXT,
+    license_category_id INTEGER NOT NULL,
+    registration_date DATE DEFAULT (date('now')),
+    total_cars INTEGER DEFAULT 0,
+    row_version BLOB NOT NULL DEFAULT (randomblob(16)),
+    CONSTRAINT FK_owners_category FOREIGN KEY (license_category_id)
+        REFERENCES driver_categories(category_id)
+);
+
+-- СПРАВОЧНАЯ ТАБЛИЦА 2: Марки автомобилей
+CREATE TABLE IF NOT EXISTS car_brands (
+    brand_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    brand_name TEXT NOT NULL UNIQUE,
+    country_origin TEXT,
+    image_data BLOB,
+    founded_year INTEGER
+);
+
+-- ЗАВИСИМАЯ ТАБЛИЦА: Автомобили (с полем версии)
+-- Верхняя граница года не проверяется: SQLite не допускает date('now') в CHECK
+CREATE TABLE IF NOT EXISTS cars (
+    car_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id INTEGER NOT NULL,
+    brand_id INTEGER NOT NULL,
+    model TEXT NOT NULL,
+    year INTEGER CHECK (year >= 1900),
+    color TEXT,
+    vin_code TEXT UNIQUE,
+    price INTEGER CHECK (price >= 0),
+    purchase_date DATE DEFAULT (date('now')),
+    custom_market_price REAL NULL,
+    row_version BLOB NOT NULL DEFAULT (randomblob(16)),
+    CONSTRAINT FK_cars_owner FOREIGN KEY (owner_id)
+        REFERENCES owners(owner_id) ON DELETE CASCADE,
+    CONSTRAINT FK_cars_brand FOREIGN KEY (brand_id)
+        REFERENCES car_brands(brand_id)
+);
+
+CREATE VIEW IF NOT EXISTS v_owner_details AS
+SELECT
+    o.owner_id,
+    o.first_name,
+    o.last_name,
+    o.phone,
+    o.email,
+    dc.category_code,
+    o.registration_date,
+    (SELECT COUNT(*) FROM cars c WHERE c.owner_id = o.owner_id) AS car_count,
+    -- Аналог DATEDIFF(year, registration_date, GETDATE())
+    CAST(strftime('%Y', 'now') AS INTEGER) - CAST(strftime('%Y', o.registration_date) AS INTEGER) AS experience_years
+FROM owners o
+JOIN driver_categories dc ON o.license_category_id = dc.category_id;
+
+-- ЗАПОЛНЕНИЕ СПРАВОЧНЫХ ТАБЛИЦ
+INSERT OR IGNORE INTO driver_categories (category_code, category_name, description) VALUES
+    ('B', 'Категория B', 'Легковые автомобили до 3.5 тонн'),
+    ('C', 'Категория C', 'Грузовые автомобили свыше 3.5 тонн'),
+    ('D', 'Категория D', 'Автобусы'),
+    ('BE', 'Категория BE', 'Легковые автомобили с прицепом'),
+    ('CE', 'Категория CE', 'Грузовые автомобили с прицепом');
+
+INSERT OR IGNORE INTO car_brands (brand_name, country_origin, founded_year) VALUES
+    ('Toyota', 'Япония', 1937),
+    ('BMW', 'Германия', 1916),
+    ('Mercedes-Benz', 'Германия', 1926),
+    ('Audi', 'Германия', 1909),
+    ('Honda', 'Япония', 1948),
+    ('Ford', 'США', 1903),
+    ('Volkswagen', 'Германия', 1937),
+    ('Nissan', 'Япония', 1933),
+    ('Hyundai', 'Южная Корея', 1967),
+    ('Kia', 'Южная Корея', 1944),
+    ('Lada', 'Россия', 1970),
+    ('Tesla', 'США', 2003);
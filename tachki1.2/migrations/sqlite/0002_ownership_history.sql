@@ -0,0 +1,18 @@
+-- История передачи автомобилей между владельцами.
+-- Имена владельцев сохраняются на момент передачи, поэтому ссылок на owners
+-- нет: удаление бывшего владельца не должно стирать историю автомобиля.
+
+CREATE TABLE IF NOT EXISTS ownership_history (
+    history_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    car_id INTEGER NOT NULL,
+    from_owner_id INTEGER NULL,
+    from_owner_name TEXT NULL,
+    to_owner_id INTEGER NOT NULL,
+    to_owner_name TEXT NOT NULL,
+    transfer_date DATE NOT NULL DEFAULT (date('now')),
+    sale_price INTEGER NOT NULL DEFAULT 0 CHECK (sale_price >= 0),
+    CONSTRAINT FK_history_car FOREIGN KEY (car_id)
+        REFERENCES cars(car_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS IX_ownership_history_car ON ownership_history (car_id, transfer_date);
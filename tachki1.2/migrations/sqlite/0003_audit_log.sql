@@ -0,0 +1,17 @@
+-- Журнал изменений: кто, когда и что поменял в owners, cars и car_brands.
+-- old_values/new_values — JSON-объекты "колонка": "значение";
+-- для INSERT old_values пуст, для DELETE — new_values.
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    audit_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    changed_at DATETIME NOT NULL DEFAULT (datetime('now', 'localtime')),
+    db_user TEXT NOT NULL,
+    table_name TEXT NOT NULL,
+    record_id INTEGER NOT NULL,
+    operation TEXT NOT NULL CHECK (operation IN ('INSERT', 'UPDATE', 'DELETE')),
+    old_values TEXT NULL,
+    new_values TEXT NULL
+);
+
+CREATE INDEX IF NOT EXISTS IX_audit_log_changed_at ON audit_log (changed_at);
+CREATE INDEX IF NOT EXISTS IX_audit_log_record ON audit_log (table_name, record_id);
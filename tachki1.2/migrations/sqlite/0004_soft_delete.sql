@@ -0,0 +1,29 @@
+-- Мягкое удаление: владельцы и автомобили помечаются удалёнными и попадают
+-- в корзину. deleted_with_owner отмечает автомобили, удалённые вместе
+-- с владельцем, — они восстанавливаются вместе с ним.
+
+ALTER TABLE owners ADD COLUMN deleted_at DATETIME NULL;
+ALTER TABLE owners ADD COLUMN deleted_by TEXT NULL;
+
+ALTER TABLE cars ADD COLUMN deleted_at DATETIME NULL;
+ALTER TABLE cars ADD COLUMN deleted_by TEXT NULL;
+ALTER TABLE cars ADD COLUMN deleted_with_owner INTEGER NOT NULL DEFAULT 0;
+
+-- Удалённые владельцы и автомобили не показываются и не считаются
+DROP VIEW IF EXISTS v_owner_details;
+
+CREATE VIEW v_owner_details AS
+SELECT
+    o.owner_id,
+    o.first_name,
+    o.last_name,
+    o.phone,
+    o.email,
+    dc.category_code,
+    o.registration_date,
+    (SELECT COUNT(*) FROM cars c WHERE c.owner_id = o.owner_id AND c.deleted_at IS NULL) AS car_count,
+    -- Аналог DATEDIFF(year, registration_date, GETDATE())
+    CAST(strftime('%Y', 'now') AS INTEGER) - CAST(strftime('%Y', o.registration_date) AS INTEGER) AS experience_years
+FROM owners o
+JOIN driver_categories dc ON o.license_category_id = dc.category_id
+WHERE o.deleted_at IS NULL;
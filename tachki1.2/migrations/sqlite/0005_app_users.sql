@@ -0,0 +1,9 @@
+-- Пользователи приложения и их роли. user_name — имя пользователя ОС;
+-- не внесённые в список пользователи получают роль viewer.
+-- Первого администратора назначает миграция 0007_seed_admin.
+
+CREATE TABLE IF NOT EXISTS app_users (
+    user_name TEXT NOT NULL PRIMARY KEY,
+    role TEXT NOT NULL CHECK (role IN ('viewer', 'operator', 'admin')),
+    created_at DATETIME NOT NULL DEFAULT (datetime('now', 'localtime'))
+);
@@ -0,0 +1,7 @@
+-- row_version у марок: API передаёт её как ETag при изменении логотипа,
+-- как у владельцев и автомобилей. ALTER TABLE в SQLite не допускает
+-- выражение по умолчанию, поэтому существующие марки заполняются отдельно.
+
+ALTER TABLE car_brands ADD COLUMN row_version BLOB;
+
+UPDATE car_brands SET row_version = randomblob(16) WHERE row_version IS NULL;
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// appliedVersions reads schema_version in the order the migrations were applied
+func appliedVersions(t *testing.T, repo *sqlRepository) []int {
+	t.Helper()
+
+	rows, err := repo.db.Query("SELECT version FROM schema_version ORDER BY applied_at, rowid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var versions []int
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return versions
+}
+
+func TestLoadMigrationsOrdered(t *testing.T) {
+	for _, dir := range []string{"mssql", "sqlite"} {
+		migrations, err := loadMigrations(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(migrations) == 0 {
+			t.Fatalf("%s: миграции не найдены", dir)
+		}
+		for i, m := range migrations {
+			if m.Version != i+1 {
+				t.Errorf("%s: миграция %d имеет версию %d", dir, i, m.Version)
+			}
+			if m.Name == "" || m.Script == "" {
+				t.Errorf("%s: миграция %04d без имени или скрипта", dir, m.Version)
+			}
+		}
+	}
+}
+
+// TestApplyMigrationsFromEmpty creates the schema of a new database
+func TestApplyMigrationsFromEmpty(t *testing.T) {
+	repo := openEmptySQLite(t)
+
+	all, err := loadMigrations("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := repo.PendingMigrations(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != len(all) {
+		t.Fatalf("в пустой базе ожидается %d миграций, найдено %d", len(all), len(pending))
+	}
+
+	if err := repo.ApplyMigrations(context.Background(), pending); err != nil {
+		t.Fatal(err)
+	}
+
+	// Таблицы последней миграции созданы, справочники заполнены
+	var categories int
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM driver_categories").Scan(&categories); err != nil {
+		t.Fatal(err)
+	}
+	if categories == 0 {
+		t.Error("driver_categories не заполнена")
+	}
+	if _, err := repo.db.Exec("SELECT COUNT(*) FROM app_users"); err != nil {
+		t.Errorf("таблица app_users не создана: %v", err)
+	}
+
+	versions := appliedVersions(t, repo)
+	if len(versions) != len(all) || versions[len(versions)-1] != all[len(all)-1].Version {
+		t.Errorf("schema_version: %v", versions)
+	}
+}
+
+// TestApplyMigrationsTwice checks that an up-to-date schema has nothing to apply
+func TestApplyMigrationsTwice(t *testing.T) {
+	repo := openTestSQLite(t)
+	before := appliedVersions(t, repo)
+
+	pending, err := repo.PendingMigrations(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("после применения остались миграции: %v", pending)
+	}
+	if err := repo.ApplyMigrations(context.Background(), pending); err != nil {
+		t.Fatal(err)
+	}
+
+	after := appliedVersions(t, repo)
+	if len(after) != len(before) {
+		t.Errorf("schema_version изменилась: %v -> %v", before, after)
+	}
+}
+
+// TestApplyMigrationsInOrder applies the migrations in two steps and checks
+// that schema_version records them in version order
+func TestApplyMigrationsInOrder(t *testing.T) {
+	repo := openEmptySQLite(t)
+
+	pending, err := repo.PendingMigrations(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) < 2 {
+		t.Fatalf("для проверки нужно хотя бы две миграции, найдено %d", len(pending))
+	}
+	if err := repo.ApplyMigrations(context.Background(), pending[:2]); err != nil {
+		t.Fatal(err)
+	}
+
+	rest, err := repo.PendingMigrations(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != len(pending)-2 || (len(rest) > 0 && rest[0].Version != pending[2].Version) {
+		t.Fatalf("после двух миграций ожидаются миграции с %04d, найдено %v", pending[2].Version, rest)
+	}
+	if err := repo.ApplyMigrations(context.Background(), rest); err != nil {
+		t.Fatal(err)
+	}
+
+	versions := appliedVersions(t, repo)
+	for i, v := range versions {
+		if v != pending[i].Version {
+			t.Fatalf("schema_version: %v, ожидался порядок версий миграций", versions)
+		}
+	}
+
+	var latest int
+	if err := repo.db.QueryRow("SELECT MAX(version) FROM schema_version").Scan(&latest); err != nil {
+		t.Fatal(err)
+	}
+	if latest != pending[len(pending)-1].Version {
+		t.Errorf("последняя версия %d, ожидалась %d", latest, pending[len(pending)-1].Version)
+	}
+}
+
+// TestMigrationRights checks that the user who creates the database becomes
+// its admin and that only admins may change the schema afterwards
+func TestMigrationRights(t *testing.T) {
+	ctx := context.Background()
+	repo := openTestSQLite(t)
+
+	user, err := repo.CurrentUser(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != osUserName() || user.Role != roleAdmin {
+		t.Fatalf("после создания базы пользователь %+v, ожидался администратор %s", user, osUserName())
+	}
+	if err := repo.CheckMigrationRights(ctx); err != nil {
+		t.Errorf("администратор: %v", err)
+	}
+
+	if _, err := repo.db.Exec("UPDATE app_users SET user_name = 'DOMAIN\\admin'"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CheckMigrationRights(ctx); !errors.Is(err, errPermissionDenied) {
+		t.Errorf("наблюдатель: CheckMigrationRights вернул %v", err)
+	}
+	before := appliedVersions(t, repo)
+	err = repo.ApplyMigrations(ctx, []Migration{{Version: 100, Name: "test", Script: "CREATE TABLE test (id INTEGER)"}})
+	if !errors.Is(err, errPermissionDenied) {
+		t.Errorf("наблюдатель: ApplyMigrations вернул %v", err)
+	}
+	if after := appliedVersions(t, repo); len(after) != len(before) {
+		t.Errorf("наблюдатель применил миграцию: %v", after)
+	}
+
+	// База без пользователей ещё не имеет администратора
+	if _, err := repo.db.Exec("DELETE FROM app_users"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CheckMigrationRights(ctx); err != nil {
+		t.Errorf("база без пользователей: %v", err)
+	}
+}
@@ -1,16 +1,50 @@
 package main
 
 import (
-	"database/sql"
+	"sync"
+	"time"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
 )
 
 // DatabaseApp holds the application state
 type DatabaseApp struct {
-	db     *sql.DB
-	app    fyne.App
-	window fyne.Window
+	// Подключение заменяется при входе, переподключении и выходе, а читается
+	// и из фоновых операций: доступ только через repository и setRepository
+	repoMu  sync.RWMutex
+	repo    Repository
+	backend string  // backendMSSQL or backendSQLite
+	connStr string  // строка подключения последнего входа (для переподключения)
+	user    AppUser // пользователь приложения и его роль, задаётся signIn
+	app     fyne.App
+	window  fyne.Window
+	tabs    *container.AppTabs
+
+	// Переходы к записи (из поиска); задаются вкладками при создании
+	openEditForm  func(tableName string, id int)
+	openBrandLogo func(brandName string)
+	openAddCar    func(ownerID int)
+
+	// Обновление вкладок журнала, корзины и пользователей при их выборе
+	reloadAudit      func()
+	reloadRecycleBin func()
+	reloadUsers      func()
+
+	// Фоновые операции с базой данных: индикатор в футере и очередь
+	// обновлений интерфейса по их завершении
+	activity *activityIndicator
+	uiOnce   sync.Once
+	uiCalls  chan func()
+
+	// Проверка связи с базой данных, запускается вместе с главным окном;
+	// защищена repoMu, как и repo
+	health *healthMonitor
+
+	// Блокировка сеанса: по бездействию или вручную. locked читается и
+	// проверкой бездействия, поэтому защищена repoMu
+	idle   *idleWatch
+	locked *lockedSession // скрытое экраном блокировки; nil — сеанс не заблокирован
 }
 
 // DriverCategory represents a row in driver_categories
@@ -31,11 +65,21 @@ type Owner struct {
 	RowVersion []byte // For optimistic locking
 }
 
+// OwnerImport is a validated owners row of a CSV import
+type OwnerImport struct {
+	FirstName  string
+	LastName   string
+	Phone      string
+	Email      string
+	CategoryID int
+}
+
 // CarBrand represents a row in car_brands
 type CarBrand struct {
-	ID        int
-	Name      string
-	ImageData []byte
+	ID         int
+	Name       string
+	ImageData  []byte
+	RowVersion []byte
 }
 
 // Car represents a row in cars
@@ -51,3 +95,35 @@ type Car struct {
 	CurrentPrice float64
 	RowVersion   []byte
 }
+
+// OwnershipTransfer represents a row in ownership_history.
+// Owner names are stored as they were at the time of the transfer.
+type OwnershipTransfer struct {
+	ID          int
+	CarID       int
+	FromOwnerID int // 0 — неизвестен (удалён или запись до ведения истории)
+	FromOwner   string
+	ToOwnerID   int
+	ToOwner     string
+	Date        time.Time
+	SalePrice   float64
+}
+
+// AuditEntry represents a row in audit_log.
+// Before and After hold the audited columns as text; nil for INSERT/DELETE.
+type AuditEntry struct {
+	ID        int
+	Time      time.Time
+	User      string
+	Table     string
+	RecordID  int
+	Operation string // INSERT, UPDATE или DELETE
+	Before    map[string]string
+	After     map[string]string
+}
+
+// AppUser represents a row in app_users
+type AppUser struct {
+	Name string // имя входа в базу данных
+	Role string // viewer, operator или admin
+}
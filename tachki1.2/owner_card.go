@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showOwnerCard shows one owner together with their cars, prices and totals
+func (d *DatabaseApp) showOwnerCard(ownerID int) {
+	content := container.NewVBox(widget.NewLabel("Загрузка…"))
+	card := dialog.NewCustom("Карточка владельца", "Закрыть", container.NewVScroll(content), d.window)
+
+	var fill func()
+	fill = func() {
+		var data *ownerCardData
+		d.runInBackground("Карточка владельца", func(ctx context.Context) error {
+			var err error
+			data, err = d.loadOwnerCard(ctx, ownerID)
+			return err
+		}, func(err error) {
+			if err != nil {
+				card.Hide()
+				d.showMessage("Ошибка", fmt.Sprintf("Не удалось загрузить карточку владельца: %v", err))
+				return
+			}
+			content.Objects = d.ownerCardContent(data, card.Hide, fill)
+			content.Refresh()
+		})
+	}
+
+	fill()
+	card.Resize(fyne.NewSize(850, 600))
+	card.Show()
+}
+
+// ownerCardData is what the owner card shows, loaded off the UI goroutine
+type ownerCardData struct {
+	owner     *Owner
+	details   [][]interface{} // строка v_owner_details
+	cars      []Car
+	brandByID map[int]CarBrand
+}
+
+func (d *DatabaseApp) loadOwnerCard(ctx context.Context, ownerID int) (*ownerCardData, error) {
+	repo := d.repository()
+	data := &ownerCardData{}
+	var err error
+	if data.owner, err = repo.GetOwnerByID(ctx, ownerID); err != nil {
+		return nil, err
+	}
+
+	// Стаж и дата получения прав — из v_owner_details
+	id := float64(ownerID)
+	if data.details, err = repo.QueryTable(ctx, "owners", TableQuery{Filters: []ColumnFilter{{Column: 0, Min: &id, Max: &id}}}); err != nil {
+		return nil, err
+	}
+
+	if data.cars, err = repo.GetCarsByOwner(ctx, ownerID); err != nil {
+		return nil, err
+	}
+
+	brands, err := repo.GetCarBrands(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data.brandByID = make(map[int]CarBrand, len(brands))
+	for _, b := range brands {
+		data.brandByID[b.ID] = b
+	}
+	return data, nil
+}
+
+// ownerCardContent builds the card; closeCard hides it, reload rebuilds it
+func (d *DatabaseApp) ownerCardContent(data *ownerCardData, closeCard, reload func()) []fyne.CanvasObject {
+	owner, details, cars, brandByID := data.owner, data.details, data.cars, data.brandByID
+	ownerID := owner.ID
+
+	// Header
+	name := widget.NewLabelWithStyle(fmt.Sprintf("%s %s", owner.FirstName, owner.LastName), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	info := widget.NewForm(
+		widget.NewFormItem("ID:", widget.NewLabel(fmt.Sprintf("%d", owner.ID))),
+		widget.NewFormItem("Телефон:", widget.NewLabel(owner.Phone)),
+		widget.NewFormItem("Email:", widget.NewLabel(owner.Email)),
+		widget.NewFormItem("Категория прав:", widget.NewLabel(owner.Category)),
+	)
+	if len(details) == 1 {
+		row := details[0]
+		info.Append("Дата получения прав:", widget.NewLabel(formatCellValue(row[6])))
+		info.Append("Стаж (лет):", widget.NewLabel(formatCellValue(row[8])))
+	}
+
+	// Cars
+	carRows := container.NewVBox()
+	var totalPrice, totalCurrent float64
+	for _, car := range cars {
+		car := car
+		totalPrice += car.Price
+		totalCurrent += car.CurrentPrice
+
+		var logo fyne.CanvasObject = widget.NewIcon(theme.MediaPhotoIcon())
+		if img, err := createThumbnailFromBytes(brandByID[car.BrandID].ImageData); err == nil {
+			img.SetMinSize(fyne.NewSize(40, 40))
+			logo = img
+		}
+
+		depreciation := 0.0
+		if car.Price > 0 {
+			depreciation = (1 - car.CurrentPrice/car.Price) * 100
+		}
+
+		title := widget.NewLabelWithStyle(fmt.Sprintf("%s %s (%d)", brandByID[car.BrandID].Name, car.Model, car.Year),
+			fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+		text := widget.NewLabel(fmt.Sprintf("VIN: %s | Цвет: %s\nЦена покупки: %.0f | Тек. цена: %.0f (−%.0f%%)",
+			car.VIN, car.Color, car.Price, car.CurrentPrice, depreciation))
+
+		editBtn := widget.NewButtonWithIcon("Изменить", theme.DocumentCreateIcon(), func() {
+			closeCard()
+			d.selectTab("✏️ Редактирование")
+			if d.openEditForm != nil {
+				d.openEditForm("cars", car.ID)
+			}
+		})
+		if !d.can(roleOperator) {
+			editBtn.Disable()
+		}
+
+		carRows.Add(container.NewBorder(nil, nil, logo, editBtn, container.NewVBox(title, text)))
+		carRows.Add(widget.NewSeparator())
+	}
+	if len(cars) == 0 {
+		carRows.Add(widget.NewLabel("У владельца нет автомобилей"))
+	}
+
+	// Totals
+	totals := widget.NewLabelWithStyle(fmt.Sprintf("Автомобилей: %d | Сумма покупки: %.0f | Текущая стоимость: %.0f | Потеря стоимости: %.0f",
+		len(cars), totalPrice, totalCurrent, totalPrice-totalCurrent), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	totals.Wrapping = fyne.TextWrapWord
+
+	// Quick Actions
+	editOwnerBtn := widget.NewButtonWithIcon("Изменить владельца", theme.DocumentCreateIcon(), func() {
+		closeCard()
+		d.selectTab("✏️ Редактирование")
+		if d.openEditForm != nil {
+			d.openEditForm("owners", ownerID)
+		}
+	})
+	addCarBtn := widget.NewButtonWithIcon("Добавить автомобиль", theme.ContentAddIcon(), func() {
+		closeCard()
+		d.selectTab("🚗 Добавить автомобиль")
+		if d.openAddCar != nil {
+			d.openAddCar(ownerID)
+		}
+	})
+	addCarBtn.Importance = widget.HighImportance
+	if !d.can(roleOperator) {
+		editOwnerBtn.Disable()
+		addCarBtn.Disable()
+	}
+	refreshBtn := widget.NewButtonWithIcon("Обновить", theme.ViewRefreshIcon(), reload)
+
+	return []fyne.CanvasObject{
+		name,
+		info,
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Автомобили:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		carRows,
+		totals,
+		container.NewGridWithColumns(3, editOwnerBtn, addCarBtn, refreshBtn),
+	}
+}
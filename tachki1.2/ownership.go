@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showTransferDialog asks for the new owner, date and sale price and transfers the car
+func (d *DatabaseApp) showTransferDialog(car *Car, onDone func()) {
+	var owners []Owner
+	d.runInBackground("Загрузка владельцев", func(ctx context.Context) error {
+		var err error
+		owners, err = d.repository().GetOwners(ctx)
+		return err
+	}, func(err error) {
+		if err != nil {
+			d.showMessage("Ошибка", fmt.Sprintf("Ошибка получения владельцев: %v", err))
+			return
+		}
+		d.showTransferForm(car, owners, onDone)
+	})
+}
+
+// showTransferForm is the transfer dialog for the loaded owners
+func (d *DatabaseApp) showTransferForm(car *Car, owners []Owner, onDone func()) {
+
+	options := make([]string, 0, len(owners))
+	ownerByOption := make(map[string]int, len(owners))
+	currentOwner := "неизвестен"
+	for _, owner := range owners {
+		option := fmt.Sprintf("%d: %s %s", owner.ID, owner.FirstName, owner.LastName)
+		if owner.ID == car.OwnerID {
+			currentOwner = option
+			continue
+		}
+		options = append(options, option)
+		ownerByOption[option] = owner.ID
+	}
+
+	ownerSelect := widget.NewSelect(options, nil)
+	ownerSelect.PlaceHolder = "Выберите нового владельца"
+
+	dateEntry := widget.NewEntry()
+	dateEntry.SetText(time.Now().Format("02.01.2006"))
+	dateEntry.SetPlaceHolder("ДД.ММ.ГГГГ")
+
+	priceEntry := widget.NewEntry()
+	priceEntry.SetPlaceHolder("Цена продажи (0 — дарение)")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Текущий владелец:", widget.NewLabel(currentOwner)),
+		widget.NewFormItem("Новый владелец:", ownerSelect),
+		widget.NewFormItem("Дата передачи:", dateEntry),
+		widget.NewFormItem("Цена продажи:", priceEntry),
+	}
+
+	form := dialog.NewForm("Передача права собственности", "Передать", "Отмена", items, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		toOwnerID, found := ownerByOption[ownerSelect.Selected]
+		if !found {
+			d.showMessage("Ошибка", "Выберите нового владельца")
+			return
+		}
+
+		date, err := parseFilterDate(dateEntry.Text)
+		if err != nil || date == nil {
+			d.showMessage("Ошибка", "Дата должна быть в формате ДД.ММ.ГГГГ")
+			return
+		}
+
+		price := 0.0
+		if text := strings.TrimSpace(priceEntry.Text); text != "" {
+			price, err = strconv.ParseFloat(text, 64)
+			if err != nil || price < 0 {
+				d.showMessage("Ошибка", "Цена продажи должна быть неотрицательным числом")
+				return
+			}
+		}
+
+		toOwner, transferDate := ownerSelect.Selected, *date
+		d.runInBackground("Передача автомобиля", func(ctx context.Context) error {
+			return d.repository().TransferOwnership(ctx, car.ID, toOwnerID, transferDate, price, car.RowVersion)
+		}, func(err error) {
+			if err != nil {
+				if errors.Is(err, errConcurrentUpdate) {
+					d.showMessage("Конфликт редактирования", "Запись была изменена другим пользователем. Пожалуйста, обновите данные.")
+				} else {
+					d.showMessage("Ошибка", fmt.Sprintf("Не удалось передать автомобиль: %v", err))
+				}
+				return
+			}
+
+			d.showMessage("Успех", fmt.Sprintf("Автомобиль #%d передан владельцу %s", car.ID, toOwner))
+			if onDone != nil {
+				onDone()
+			}
+		})
+	}, d.window)
+	form.Resize(fyne.NewSize(500, 300))
+	form.Show()
+}
+
+// ownershipHistoryView lists the provenance chain of the car, oldest transfer
+// first. The history is loaded in the background and filled in when ready.
+func (d *DatabaseApp) ownershipHistoryView(carID int) fyne.CanvasObject {
+	header := widget.NewLabelWithStyle("История владения:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	lines := container.NewVBox(header, widget.NewLabel("Загрузка…"))
+
+	var history []OwnershipTransfer
+	d.runInBackground("Загрузка истории владения", func(ctx context.Context) error {
+		var err error
+		history, err = d.repository().GetOwnershipHistory(ctx, carID)
+		return err
+	}, func(err error) {
+		lines.Objects = []fyne.CanvasObject{header}
+		switch {
+		case err != nil:
+			lines.Add(widget.NewLabel(fmt.Sprintf("Не удалось загрузить историю: %v", err)))
+		case len(history) == 0:
+			lines.Add(widget.NewLabel("Передач не было"))
+		}
+		for _, t := range history {
+			from := t.FromOwner
+			if from == "" {
+				from = "—"
+			}
+			lines.Add(widget.NewLabel(fmt.Sprintf("%s: %s → %s, цена %.0f",
+				t.Date.Format("02.01.2006"), from, t.ToOwner, t.SalePrice)))
+		}
+		lines.Refresh()
+	})
+	return lines
+}
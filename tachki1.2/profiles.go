@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+)
+
+// Preference keys of the saved connection profiles
+const (
+	profilesKey    = "connection.profiles"
+	lastProfileKey = "connection.lastProfile"
+)
+
+// Encryption modes of a SQL Server connection (go-mssqldb "encrypt")
+const (
+	encryptDisable  = "disable"
+	encryptOptional = "false"
+	encryptRequired = "true"
+)
+
+// encryptModes lists the encryption modes in the order of the login form
+var encryptModes = []string{encryptOptional, encryptRequired, encryptDisable}
+
+// connectionProfile is a named set of connection settings shown on the login
+// screen. Passwords are not part of it: they live in the keyring.
+type connectionProfile struct {
+	Name     string `json:"name"`
+	Backend  string `json:"backend"`
+	Server   string `json:"server,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Instance string `json:"instance,omitempty"` // именованный экземпляр SQL Server
+	Database string `json:"database,omitempty"`
+	User     string `json:"user,omitempty"`
+	Encrypt  string `json:"encrypt,omitempty"`
+	// TrustServerCertificate skips validation of the server certificate
+	TrustServerCertificate bool   `json:"trustServerCertificate,omitempty"`
+	ConnectTimeout         int    `json:"connectTimeout,omitempty"` // секунды; 0 — по умолчанию драйвера
+	AppName                string `json:"appName,omitempty"`
+	SQLitePath             string `json:"sqlitePath,omitempty"`
+}
+
+// defaultAppName identifies the application in SQL Server sessions
+const defaultAppName = "Car Database Manager"
+
+// defaultMSSQLProfile holds the settings shown when no profile is saved
+func defaultMSSQLProfile() connectionProfile {
+	return connectionProfile{
+		Backend:  backendMSSQL,
+		Server:   "localhost",
+		Port:     1433,
+		Database: "master",
+		User:     "sa",
+		Encrypt:  encryptOptional,
+		AppName:  defaultAppName,
+	}
+}
+
+// mssqlConnString builds a go-mssqldb URL, so that special characters in
+// the user name or password need no escaping
+func (p connectionProfile) mssqlConnString(password string) string {
+	host, instance := p.Server, p.Instance
+	// host\instance в поле сервера тоже поддерживается
+	if i := strings.Index(host, `\`); i >= 0 {
+		host = p.Server[:i]
+		if instance == "" {
+			instance = p.Server[i+1:]
+		}
+	}
+	if p.Port > 0 {
+		host = net.JoinHostPort(host, strconv.Itoa(p.Port))
+	}
+
+	query := url.Values{}
+	if p.Database != "" {
+		query.Set("database", p.Database)
+	}
+	if p.Encrypt != "" {
+		query.Set("encrypt", p.Encrypt)
+	}
+	if p.TrustServerCertificate {
+		query.Set("TrustServerCertificate", "true")
+	}
+	if p.ConnectTimeout > 0 {
+		query.Set("connection timeout", strconv.Itoa(p.ConnectTimeout))
+	}
+	if p.AppName != "" {
+		query.Set("app name", p.AppName)
+	}
+
+	u := &url.URL{
+		Scheme:   "sqlserver",
+		User:     url.UserPassword(p.User, password),
+		Host:     host,
+		RawQuery: query.Encode(),
+	}
+	if instance != "" {
+		u.Path = instance
+	}
+	return u.String()
+}
+
+// connString returns the connection string passed to connectDB
+func (p connectionProfile) connString(password string) string {
+	if p.Backend == backendSQLite {
+		return p.SQLitePath
+	}
+	return p.mssqlConnString(password)
+}
+
+// loadProfiles reads the saved profiles sorted by name
+func loadProfiles(prefs fyne.Preferences) []connectionProfile {
+	var profiles []connectionProfile
+	if data := prefs.String(profilesKey); data != "" {
+		if err := json.Unmarshal([]byte(data), &profiles); err != nil {
+			log.Printf("Не удалось прочитать профили подключения: %v", err)
+			return nil
+		}
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles
+}
+
+func storeProfiles(prefs fyne.Preferences, profiles []connectionProfile) error {
+	data, err := json.Marshal(profiles)
+	if err != nil {
+		return err
+	}
+	prefs.SetString(profilesKey, string(data))
+	return nil
+}
+
+// findProfile returns the profile with the given name
+func findProfile(profiles []connectionProfile, name string) (connectionProfile, bool) {
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return connectionProfile{}, false
+}
+
+// saveProfile adds the profile or replaces the one with the same name
+func saveProfile(prefs fyne.Preferences, profile connectionProfile) error {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Name == "" {
+		return fmt.Errorf("не задано имя профиля")
+	}
+
+	profiles := loadProfiles(prefs)
+	replaced := false
+	for i := range profiles {
+		if profiles[i].Name == profile.Name {
+			profiles[i] = profile
+			replaced = true
+		}
+	}
+	if !replaced {
+		profiles = append(profiles, profile)
+	}
+	return storeProfiles(prefs, profiles)
+}
+
+// deleteProfile removes the profile with the given name
+func deleteProfile(prefs fyne.Preferences, name string) error {
+	profiles := loadProfiles(prefs)
+	kept := profiles[:0]
+	for _, p := range profiles {
+		if p.Name != name {
+			kept = append(kept, p)
+		}
+	}
+	if prefs.String(lastProfileKey) == name {
+		prefs.SetString(lastProfileKey, "")
+	}
+	return storeProfiles(prefs, kept)
+}
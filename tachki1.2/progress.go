@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// progressDelay keeps quick operations from flashing the progress dialog
+const progressDelay = 300 * time.Millisecond
+
+// activityIndicator shows in the footer that database work is in flight
+type activityIndicator struct {
+	mu      sync.Mutex
+	titles  []string // операции в работе, в порядке запуска
+	label   *widget.Label
+	bar     *widget.ProgressBarInfinite
+	content *fyne.Container
+}
+
+func newActivityIndicator() *activityIndicator {
+	a := &activityIndicator{
+		label: widget.NewLabel(""),
+		bar:   widget.NewProgressBarInfinite(),
+	}
+	a.bar.Stop()
+	a.content = container.NewHBox(a.bar, a.label)
+	a.content.Hide()
+	return a
+}
+
+// begin shows the indicator while at least one operation runs
+func (a *activityIndicator) begin(title string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.titles = append(a.titles, title)
+	a.update()
+}
+
+func (a *activityIndicator) end(title string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i, t := range a.titles {
+		if t == title {
+			a.titles = append(a.titles[:i], a.titles[i+1:]...)
+			break
+		}
+	}
+	a.update()
+}
+
+// update redraws the indicator; a.mu must be held
+func (a *activityIndicator) update() {
+	switch len(a.titles) {
+	case 0:
+		a.bar.Stop()
+		a.content.Hide()
+		return
+	case 1:
+		a.label.SetText(a.titles[0] + "…")
+	default:
+		a.label.SetText(fmt.Sprintf("%s… (ещё операций: %d)", a.titles[len(a.titles)-1], len(a.titles)-1))
+	}
+	if !a.bar.Running() {
+		a.bar.Start()
+	}
+	a.content.Show()
+}
+
+// onUI queues fn to update widgets with the result of a background operation.
+// Fyne 2.4 has no public way to run code on its event goroutine; its widgets
+// may be changed from any goroutine, and the single queue keeps the updates
+// of finished operations in order and from interleaving with each other.
+func (d *DatabaseApp) onUI(fn func()) {
+	d.uiOnce.Do(func() {
+		d.uiCalls = make(chan func(), 64)
+		go func() {
+			for call := range d.uiCalls {
+				call()
+			}
+		}()
+	})
+	d.uiCalls <- fn
+}
+
+// runInBackground runs op off the UI goroutine, limited by the query timeout.
+// While it runs, the footer shows title and the busy widgets are disabled;
+// then done receives the result through onUI.
+func (d *DatabaseApp) runInBackground(title string, op func(ctx context.Context) error, done func(err error), busy ...fyne.Disableable) {
+	ctx, cancel := d.queryContext()
+	finish := d.beginActivity(title, busy)
+
+	go func() {
+		defer cancel()
+		err := op(ctx)
+		d.reportError(err)
+		d.onUI(func() {
+			finish()
+			done(err)
+		})
+	}()
+}
+
+// reportError asks the health monitor to check the connection right away when
+// an operation failed with a network error
+func (d *DatabaseApp) reportError(err error) {
+	if m := d.monitor(); err != nil && m != nil && isTransientError(err) {
+		m.CheckNow()
+	}
+}
+
+// beginActivity disables the enabled busy widgets and shows title in the
+// footer; the returned func undoes both
+func (d *DatabaseApp) beginActivity(title string, busy []fyne.Disableable) func() {
+	var disabled []fyne.Disableable
+	for _, w := range busy {
+		if !w.Disabled() {
+			w.Disable()
+			disabled = append(disabled, w)
+		}
+	}
+	if d.activity != nil {
+		d.activity.begin(title)
+	}
+	d.touchSession()
+
+	return func() {
+		for _, w := range disabled {
+			w.Enable()
+		}
+		if d.activity != nil {
+			d.activity.end(title)
+		}
+	}
+}
+
+// runWithProgress runs a long database operation like runInBackground. If it
+// takes longer than progressDelay, a dialog with a cancel button is shown.
+// The context of op is limited by the query timeout and cancelled by the
+// button. done receives the result unless the operation was cancelled or
+// timed out: that is reported here.
+func (d *DatabaseApp) runWithProgress(title string, op func(ctx context.Context) error, done func(err error), busy ...fyne.Disableable) {
+	ctx, cancel := d.queryContext()
+	finish := d.beginActivity(title, busy)
+
+	var mu sync.Mutex
+	finished, cancelled := false, false
+
+	cancelBtn := widget.NewButtonWithIcon("Отмена", theme.CancelIcon(), nil)
+	progress := dialog.NewCustomWithoutButtons(title, container.NewVBox(
+		widget.NewLabel("Выполняется запрос к базе данных…"),
+		widget.NewProgressBarInfinite(),
+	), d.window)
+	progress.SetButtons([]fyne.CanvasObject{cancelBtn})
+	cancelBtn.OnTapped = func() {
+		mu.Lock()
+		cancelled = true
+		mu.Unlock()
+		cancelBtn.Disable()
+		cancel()
+	}
+
+	timer := time.AfterFunc(progressDelay, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if !finished {
+			progress.Show()
+		}
+	})
+
+	go func() {
+		err := op(ctx)
+		ctxErr := ctx.Err()
+		d.reportError(err)
+		cancel()
+
+		timer.Stop()
+		mu.Lock()
+		finished = true
+		byUser := cancelled
+		mu.Unlock()
+
+		d.onUI(func() {
+			progress.Hide()
+			finish()
+
+			switch {
+			case err != nil && byUser:
+				d.showMessage("Отменено", "Операция отменена, изменения не сохранены")
+			case err != nil && errors.Is(ctxErr, context.DeadlineExceeded):
+				d.showMessage("Ошибка", fmt.Sprintf("Превышено время ожидания (%v). "+
+					"Увеличьте таймаут запросов на экране подключения.", d.queryTimeout()))
+			default:
+				done(err)
+			}
+		})
+	}()
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// errConcurrentUpdate is returned when row_version no longer matches (optimistic locking)
+var errConcurrentUpdate = errors.New("запись была изменена другим пользователем")
+
+// errSameOwner is returned by TransferOwnership when the car already belongs to the new owner
+var errSameOwner = errors.New("автомобиль уже принадлежит этому владельцу")
+
+// errNotActive is returned by a delete of a record that is missing or already
+// in the recycle bin; it wraps sql.ErrNoRows
+func errNotActive(id int) error {
+	return fmt.Errorf("запись %d не найдена или уже в корзине: %w", id, sql.ErrNoRows)
+}
+
+// errNotInRecycleBin is returned when restoring or purging a record that is not deleted
+var errNotInRecycleBin = errors.New("запись не найдена в корзине")
+
+// errOwnerDeleted is returned when restoring a car whose owner is in the recycle bin
+var errOwnerDeleted = errors.New("владелец автомобиля в корзине — сначала восстановите владельца")
+
+// errOwnerChange is returned by UpdateCar when the owner differs: the owner is
+// changed only by TransferOwnership, which records ownership_history
+var errOwnerChange = errors.New("владелец автомобиля меняется только передачей (история владения)")
+
+// errDuplicate is returned when a write violates a unique constraint, e.g. a
+// VIN that another car already has
+var errDuplicate = errors.New("запись с таким значением уже существует")
+
+// errInvalidReference is returned when a write refers to a missing owner,
+// brand or driver category
+var errInvalidReference = errors.New("связанная запись не найдена")
+
+// errNoChanges is returned by BulkUpdateCars when no field is set
+var errNoChanges = errors.New("не выбрано ни одного изменения")
+
+// errImportNotCommitted is returned by a check-only or failed import inside
+// InTransaction: its rows can only be undone by rolling back the whole unit
+var errImportNotCommitted = errors.New("импорт не выполнен, транзакция должна быть отменена")
+
+// errNoCarsForBrand is returned by MassPriceUpdate when the brand has no cars
+var errNoCarsForBrand = errors.New("автомобили данного бренда не найдены")
+
+// errPermissionDenied is returned when the role of the user does not allow the operation
+var errPermissionDenied = errors.New("недостаточно прав")
+
+// errLastAdmin is returned when a change would leave the application without administrators
+var errLastAdmin = errors.New("нельзя лишить прав последнего администратора")
+
+// CategoryRepository provides access to driver_categories
+type CategoryRepository interface {
+	GetDriverCategories(ctx context.Context) ([]DriverCategory, error)
+}
+
+// OwnerRepository provides access to owners
+type OwnerRepository interface {
+	GetOwners(ctx context.Context) ([]Owner, error)
+	GetOwnerByID(ctx context.Context, id int) (*Owner, error)
+	// AddOwner inserts an owner and returns its owner_id
+	AddOwner(ctx context.Context, firstName, lastName, phone, email string, categoryID int) (int, error)
+	UpdateOwner(ctx context.Context, id int, firstName, lastName, phone, email string, categoryID int, rowVersion []byte) error
+	// DeleteOwner moves the owner and all their cars to the recycle bin.
+	// A non-nil rowVersion must match the owner's, otherwise errConcurrentUpdate;
+	// a missing or already deleted owner is errNotActive (sql.ErrNoRows).
+	DeleteOwner(ctx context.Context, id int, rowVersion []byte) error
+}
+
+// CarRepository provides access to cars
+type CarRepository interface {
+	GetCars(ctx context.Context) ([]Car, error)
+	GetCarsByOwner(ctx context.Context, ownerID int) ([]Car, error)
+	GetCarByID(ctx context.Context, id int) (*Car, error)
+	// AddCar inserts a car and returns its car_id
+	AddCar(ctx context.Context, ownerID, brandID int, model string, year int, color, vin string, price float64) (int, error)
+	// UpdateCar changes the car data; ownerID must be the current owner,
+	// otherwise errOwnerChange (use TransferOwnership)
+	UpdateCar(ctx context.Context, id int, ownerID, brandID int, model string, year int, color, vin string, price float64, rowVersion []byte) error
+	// DeleteCar moves the car to the recycle bin; rowVersion as in DeleteOwner
+	DeleteCar(ctx context.Context, id int, rowVersion []byte) error
+	// MassPriceUpdate changes the price of every car of the brand by percentage
+	MassPriceUpdate(ctx context.Context, brandID int, percentage float64) error
+}
+
+// OwnershipRepository moves cars between owners keeping ownership_history
+type OwnershipRepository interface {
+	// TransferOwnership changes owner_id and records the transfer in one transaction
+	TransferOwnership(ctx context.Context, carID, toOwnerID int, date time.Time, salePrice float64, rowVersion []byte) error
+	// GetOwnershipHistory returns the transfers of the car, oldest first
+	GetOwnershipHistory(ctx context.Context, carID int) ([]OwnershipTransfer, error)
+}
+
+// BrandRepository provides access to car_brands
+type BrandRepository interface {
+	GetCarBrands(ctx context.Context) ([]CarBrand, error)
+	GetCarBrand(ctx context.Context, id int) (*CarBrand, error)
+	GetBrandImage(ctx context.Context, brandID int) ([]byte, error)
+	// UpdateBrandImage replaces the logo. A non-nil rowVersion must match the
+	// brand's, otherwise errConcurrentUpdate; nil overwrites unconditionally.
+	UpdateBrandImage(ctx context.Context, brandID int, imageData []byte, rowVersion []byte) error
+}
+
+// TableRepository returns the rows shown in the View tab.
+// Column order matches the headers defined in tableColumnNames.
+type TableRepository interface {
+	GetTableRows(ctx context.Context, tableName string) ([][]interface{}, error)
+	// QueryTable is GetTableRows with sorting, filtering and paging done by the database
+	QueryTable(ctx context.Context, tableName string, q TableQuery) ([][]interface{}, error)
+	// CountTable returns the number of rows matching the filters
+	CountTable(ctx context.Context, tableName string, filters []ColumnFilter) (int, error)
+}
+
+// TableQuery describes sorting, filtering and paging of a View tab table.
+// Columns are indexes into tableColumnNames.
+type TableQuery struct {
+	Sort    *TableSort // nil — порядок таблицы
+	Filters []ColumnFilter
+	Offset  int
+	Limit   int // 0 — все строки
+}
+
+// TableSort orders rows by one column
+type TableSort struct {
+	Column int
+	Desc   bool
+}
+
+// ColumnFilter restricts one column; only the set conditions apply
+type ColumnFilter struct {
+	Column   int
+	Contains string     // подстрока
+	Min, Max *float64   // числовой диапазон, включительно
+	From, To *time.Time // диапазон дат, включительно
+}
+
+// ImportRepository inserts CSV batches in one transaction.
+// rowErrors maps the batch index to the database error of that row; the
+// transaction is committed only when commit is set and no row failed,
+// so commit=false is a dry run. On SQL Server the dry run still uses up the
+// IDENTITY values of the inserted rows (they are not returned on rollback),
+// so the IDs of a checked and then imported batch have a gap; SQLite and the
+// memory repository roll the counters back.
+type ImportRepository interface {
+	ImportOwners(ctx context.Context, owners []OwnerImport, commit bool) (rowErrors map[int]error, err error)
+	ImportCars(ctx context.Context, cars []Car, commit bool) (rowErrors map[int]error, err error)
+}
+
+// SearchResult is one record found by the global search
+type SearchResult struct {
+	Table   string // owners, cars или car_brands
+	ID      int
+	Title   string
+	Details string
+}
+
+// SearchRepository finds owners (name, phone, email), cars (model, VIN,
+// color) and brands (name) containing the text; at most limit per table
+type SearchRepository interface {
+	Search(ctx context.Context, text string, limit int) ([]SearchResult, error)
+}
+
+// DeletedRecord is an owner or a car in the recycle bin
+type DeletedRecord struct {
+	Table     string // owners или cars
+	ID        int
+	Title     string
+	DeletedAt time.Time
+	DeletedBy string
+	OwnerID   int  // владелец автомобиля
+	WithOwner bool // автомобиль удалён вместе с владельцем
+}
+
+// RecycleBinRepository manages soft-deleted owners and cars.
+// Deleted records are hidden from every other read.
+type RecycleBinRepository interface {
+	// GetDeleted returns the recycle bin, most recently deleted first
+	GetDeleted(ctx context.Context) ([]DeletedRecord, error)
+	// RestoreOwner restores the owner together with the cars deleted with them
+	RestoreOwner(ctx context.Context, id int) error
+	// RestoreCar restores a car; its owner must not be deleted
+	RestoreCar(ctx context.Context, id int) error
+	// PurgeOwner removes a deleted owner and all their cars permanently
+	PurgeOwner(ctx context.Context, id int) error
+	// PurgeCar removes a deleted car permanently
+	PurgeCar(ctx context.Context, id int) error
+}
+
+// CarChanges lists the fields set by BulkUpdateCars; nil fields are kept.
+// A new owner is recorded in ownership_history for every car that changes
+// hands, dated today and without a sale price.
+type CarChanges struct {
+	OwnerID *int
+	BrandID *int
+	Color   *string
+}
+
+// BulkResult counts the records changed by a bulk operation
+type BulkResult struct {
+	Owners int
+	Cars   int
+}
+
+// BulkRepository changes many records in one transaction: all or nothing
+type BulkRepository interface {
+	// BulkDeleteOwners moves the owners and all their cars to the recycle bin
+	BulkDeleteOwners(ctx context.Context, ids []int) (BulkResult, error)
+	// BulkDeleteCars moves the cars to the recycle bin
+	BulkDeleteCars(ctx context.Context, ids []int) (BulkResult, error)
+	// BulkUpdateCars applies changes to every car and returns the number of updated cars
+	BulkUpdateCars(ctx context.Context, ids []int, changes CarChanges) (int, error)
+}
+
+// AuditFilter selects audit_log entries; zero fields do not restrict
+type AuditFilter struct {
+	Table     string
+	Operation string
+	User      string // подстрока
+	RecordID  int
+	From, To  *time.Time // диапазон дат, включительно
+	Limit     int
+}
+
+// AuditRepository reads the audit_log written by every insert, update and delete
+type AuditRepository interface {
+	// GetAuditLog returns the matching entries, newest first
+	GetAuditLog(ctx context.Context, filter AuditFilter) ([]AuditEntry, error)
+}
+
+// UserRepository manages the application users and their roles (app_users).
+// Users are identified by the name of the database login.
+type UserRepository interface {
+	// CurrentUser returns the connected user with their role; users not in
+	// the list are viewers. The first admin is seeded by the seed_admin
+	// migration: the user who applied it to a database without users.
+	CurrentUser(ctx context.Context) (AppUser, error)
+	// GetAppUsers returns the users ordered by name
+	GetAppUsers(ctx context.Context) ([]AppUser, error)
+	// SetAppUser adds the user or changes their role
+	SetAppUser(ctx context.Context, name, role string) error
+	DeleteAppUser(ctx context.Context, name string) error
+}
+
+// TransactionRepository groups several repository calls into one transaction
+type TransactionRepository interface {
+	// InTransaction calls fn with a repository bound to a single transaction.
+	// The transaction commits when fn returns nil and rolls back when fn
+	// returns an error or panics; the panic is then re-raised. Calls on the
+	// repository passed to fn see each other's uncommitted changes; a nested
+	// InTransaction joins the outer transaction.
+	InTransaction(ctx context.Context, fn func(repo Repository) error) error
+}
+
+// Repository is the full data layer used by the UI
+type Repository interface {
+	CategoryRepository
+	OwnerRepository
+	CarRepository
+	OwnershipRepository
+	BrandRepository
+	TableRepository
+	ImportRepository
+	SearchRepository
+	AuditRepository
+	RecycleBinRepository
+	BulkRepository
+	UserRepository
+	TransactionRepository
+	// Ping checks that the database is reachable
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+var (
+	_ Repository = (*sqlRepository)(nil)
+	_ Repository = (*memoryRepository)(nil)
+	_ Repository = closedRepository{}
+)
+
+// depreciatedValue mirrors dbo.fn_GetCarDepreciatedValue: minus 8% per year, at most 90%
+func depreciatedValue(price float64, year int) float64 {
+	age := time.Now().Year() - year
+	if age < 0 {
+		age = 0
+	}
+
+	rate := 0.08 * float64(age)
+	if rate > 0.90 {
+		rate = 0.90
+	}
+
+	return price * (1.0 - rate)
+}
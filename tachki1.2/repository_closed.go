@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// errNotConnected is returned while there is no connection: the session is
+// locked or the user is back on the login screen
+var errNotConnected = errors.New("нет подключения к базе данных")
+
+// closedRepository stands in for the connection after it is closed, so that
+// background operations still running get errNotConnected instead of a nil
+// repository
+type closedRepository struct{}
+
+func (closedRepository) GetDriverCategories(context.Context) ([]DriverCategory, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) GetOwners(context.Context) ([]Owner, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) GetOwnerByID(context.Context, int) (*Owner, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) AddOwner(context.Context, string, string, string, string, int) (int, error) {
+	return 0, errNotConnected
+}
+
+func (closedRepository) UpdateOwner(context.Context, int, string, string, string, string, int, []byte) error {
+	return errNotConnected
+}
+
+func (closedRepository) DeleteOwner(context.Context, int, []byte) error {
+	return errNotConnected
+}
+
+func (closedRepository) GetCars(context.Context) ([]Car, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) GetCarsByOwner(context.Context, int) ([]Car, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) GetCarByID(context.Context, int) (*Car, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) AddCar(context.Context, int, int, string, int, string, string, float64) (int, error) {
+	return 0, errNotConnected
+}
+
+func (closedRepository) UpdateCar(context.Context, int, int, int, string, int, string, string, float64, []byte) error {
+	return errNotConnected
+}
+
+func (closedRepository) DeleteCar(context.Context, int, []byte) error {
+	return errNotConnected
+}
+
+func (closedRepository) MassPriceUpdate(context.Context, int, float64) error {
+	return errNotConnected
+}
+
+func (closedRepository) TransferOwnership(context.Context, int, int, time.Time, float64, []byte) error {
+	return errNotConnected
+}
+
+func (closedRepository) GetOwnershipHistory(context.Context, int) ([]OwnershipTransfer, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) GetCarBrands(context.Context) ([]CarBrand, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) GetCarBrand(context.Context, int) (*CarBrand, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) GetBrandImage(context.Context, int) ([]byte, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) UpdateBrandImage(context.Context, int, []byte, []byte) error {
+	return errNotConnected
+}
+
+func (closedRepository) GetTableRows(context.Context, string) ([][]interface{}, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) QueryTable(context.Context, string, TableQuery) ([][]interface{}, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) CountTable(context.Context, string, []ColumnFilter) (int, error) {
+	return 0, errNotConnected
+}
+
+func (closedRepository) ImportOwners(context.Context, []OwnerImport, bool) (map[int]error, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) ImportCars(context.Context, []Car, bool) (map[int]error, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) Search(context.Context, string, int) ([]SearchResult, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) GetDeleted(context.Context) ([]DeletedRecord, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) RestoreOwner(context.Context, int) error {
+	return errNotConnected
+}
+
+func (closedRepository) RestoreCar(context.Context, int) error {
+	return errNotConnected
+}
+
+func (closedRepository) PurgeOwner(context.Context, int) error {
+	return errNotConnected
+}
+
+func (closedRepository) PurgeCar(context.Context, int) error {
+	return errNotConnected
+}
+
+func (closedRepository) BulkDeleteOwners(context.Context, []int) (BulkResult, error) {
+	return BulkResult{}, errNotConnected
+}
+
+func (closedRepository) BulkDeleteCars(context.Context, []int) (BulkResult, error) {
+	return BulkResult{}, errNotConnected
+}
+
+func (closedRepository) BulkUpdateCars(context.Context, []int, CarChanges) (int, error) {
+	return 0, errNotConnected
+}
+
+func (closedRepository) GetAuditLog(context.Context, AuditFilter) ([]AuditEntry, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) CurrentUser(context.Context) (AppUser, error) {
+	return AppUser{}, errNotConnected
+}
+
+func (closedRepository) GetAppUsers(context.Context) ([]AppUser, error) {
+	return nil, errNotConnected
+}
+
+func (closedRepository) SetAppUser(context.Context, string, string) error {
+	return errNotConnected
+}
+
+func (closedRepository) DeleteAppUser(context.Context, string) error {
+	return errNotConnected
+}
+
+func (closedRepository) InTransaction(context.Context, func(Repository) error) error {
+	return errNotConnected
+}
+
+func (closedRepository) Ping(context.Context) error {
+	return errNotConnected
+}
+
+func (closedRepository) Close() error {
+	return nil
+}
@@ -0,0 +1,1374 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryRepository is an in-memory implementation of Repository.
+// It follows the same rules as the SQL schema (foreign keys, unique VIN,
+// cascade delete, row_version) so UI handlers can be exercised without a server.
+type memoryRepository struct {
+	*memoryStore
+	unit bool // репозиторий единицы работы, переданный в fn InTransaction
+}
+
+// memoryStore is the data shared by the repository and its units of work
+type memoryStore struct {
+	mu sync.Mutex
+	// Единица работы держит writeMu до фиксации или отката: записи
+	// других вызывающих ждут её, как блокировку записи в SQLite
+	writeMu sync.Mutex
+
+	categories map[int]*memCategory
+	owners     map[int]*memOwner
+	brands     map[int]*memBrand
+	cars       map[int]*memCar
+	history    []OwnershipTransfer
+	auditLog   []AuditEntry
+	appUsers   map[string]string // пользователь → роль
+	user       string            // пользователь для audit_log
+
+	nextOwnerID   int
+	nextCarID     int
+	nextHistoryID int
+	nextAuditID   int
+}
+
+type memCategory struct {
+	DriverCategory
+	Description string
+}
+
+type memOwner struct {
+	Owner
+	CategoryID       int
+	RegistrationDate time.Time
+	deletion
+}
+
+// deletion marks a record in the recycle bin (deleted_at, deleted_by)
+type deletion struct {
+	DeletedAt time.Time // нулевое значение — запись не удалена
+	DeletedBy string
+}
+
+func (d deletion) deleted() bool {
+	return !d.DeletedAt.IsZero()
+}
+
+// deletedAt is the deleted_at column value: nil for active records
+func (d deletion) deletedAt() interface{} {
+	if !d.deleted() {
+		return nil
+	}
+	return d.DeletedAt
+}
+
+type memBrand struct {
+	CarBrand
+	Country     string
+	FoundedYear int
+}
+
+type memCar struct {
+	Car
+	PurchaseDate time.Time
+	deletion
+	WithOwner bool // deleted_with_owner
+}
+
+// newMemoryRepository returns a repository filled with the same reference
+// data (driver categories and car brands) as the SQL setup script
+func newMemoryRepository() *memoryRepository {
+	r := &memoryRepository{memoryStore: &memoryStore{
+		categories:    make(map[int]*memCategory),
+		owners:        make(map[int]*memOwner),
+		brands:        make(map[int]*memBrand),
+		cars:          make(map[int]*memCar),
+		appUsers:      make(map[string]string),
+		nextOwnerID:   1,
+		nextCarID:     1,
+		nextHistoryID: 1,
+		nextAuditID:   1,
+		user:          osUserName(),
+	}}
+
+	categories := []memCategory{
+		{DriverCategory{Code: "B", Name: "Категория B"}, "Легковые автомобили до 3.5 тонн"},
+		{DriverCategory{Code: "C", Name: "Категория C"}, "Грузовые автомобили свыше 3.5 тонн"},
+		{DriverCategory{Code: "D", Name: "Категория D"}, "Автобусы"},
+		{DriverCategory{Code: "BE", Name: "Категория BE"}, "Легковые автомобили с прицепом"},
+		{DriverCategory{Code: "CE", Name: "Категория CE"}, "Грузовые автомобили с прицепом"},
+	}
+	for i := range categories {
+		cat := categories[i]
+		cat.ID = i + 1
+		r.categories[cat.ID] = &cat
+	}
+
+	brands := []memBrand{
+		{CarBrand{Name: "Toyota"}, "Япония", 1937},
+		{CarBrand{Name: "BMW"}, "Германия", 1916},
+		{CarBrand{Name: "Mercedes-Benz"}, "Германия", 1926},
+		{CarBrand{Name: "Audi"}, "Германия", 1909},
+		{CarBrand{Name: "Honda"}, "Япония", 1948},
+		{CarBrand{Name: "Ford"}, "США", 1903},
+		{CarBrand{Name: "Volkswagen"}, "Германия", 1937},
+		{CarBrand{Name: "Nissan"}, "Япония", 1933},
+		{CarBrand{Name: "Hyundai"}, "Южная Корея", 1967},
+		{CarBrand{Name: "Kia"}, "Южная Корея", 1944},
+		{CarBrand{Name: "Lada"}, "Россия", 1970},
+		{CarBrand{Name: "Tesla"}, "США", 2003},
+	}
+	for i := range brands {
+		brand := brands[i]
+		brand.ID = i + 1
+		brand.RowVersion = newRowVersion()
+		r.brands[brand.ID] = &brand
+	}
+
+	// Как миграция seed_admin: создатель базы — её администратор
+	r.appUsers[r.user] = roleAdmin
+
+	return r
+}
+
+// Ping always succeeds: the data is in memory
+func (r *memoryRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryRepository) Close() error {
+	return nil
+}
+
+// newRowVersion plays the role of NEWID() for row_version columns
+func newRowVersion() []byte {
+	version := make([]byte, 16)
+	rand.Read(version)
+	return version
+}
+
+// sortedIDs returns map keys in ascending order, like a clustered primary key scan
+func sortedIDs[T any](m map[int]T) []int {
+	ids := make([]int, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// --- Reads ---
+
+func (r *memoryRepository) GetDriverCategories(ctx context.Context) ([]DriverCategory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var categories []DriverCategory
+	for _, id := range sortedIDs(r.categories) {
+		categories = append(categories, r.categories[id].DriverCategory)
+	}
+	return categories, nil
+}
+
+func (r *memoryRepository) GetOwners(ctx context.Context) ([]Owner, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var owners []Owner
+	for _, id := range sortedIDs(r.owners) {
+		if r.owners[id].deleted() {
+			continue
+		}
+		owner := r.owners[id].Owner
+		owner.Category = r.categories[r.owners[id].CategoryID].Code
+		owners = append(owners, owner)
+	}
+	return owners, nil
+}
+
+func (r *memoryRepository) GetCarBrands(ctx context.Context) ([]CarBrand, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var brands []CarBrand
+	for _, id := range sortedIDs(r.brands) {
+		brands = append(brands, r.brands[id].CarBrand)
+	}
+	return brands, nil
+}
+
+func (r *memoryRepository) GetCarBrand(ctx context.Context, id int) (*CarBrand, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.brands[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	brand := b.CarBrand
+	return &brand, nil
+}
+
+func (r *memoryRepository) GetOwnerByID(ctx context.Context, id int) (*Owner, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o, ok := r.activeOwner(id)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	owner := o.Owner
+	owner.Category = r.categories[o.CategoryID].Code
+	return &owner, nil
+}
+
+func (r *memoryRepository) GetCars(ctx context.Context) ([]Car, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var cars []Car
+	for _, id := range sortedIDs(r.cars) {
+		if r.cars[id].deleted() {
+			continue
+		}
+		car := r.cars[id].Car
+		car.CurrentPrice = depreciatedValue(car.Price, car.Year)
+		cars = append(cars, car)
+	}
+	return cars, nil
+}
+
+func (r *memoryRepository) GetCarsByOwner(ctx context.Context, ownerID int) ([]Car, error) {
+	cars, err := r.GetCars(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Car
+	for _, car := range cars {
+		if car.OwnerID == ownerID {
+			result = append(result, car)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryRepository) GetCarByID(ctx context.Context, id int) (*Car, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.activeCar(id)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	car := c.Car
+	car.CurrentPrice = depreciatedValue(car.Price, car.Year)
+	return &car, nil
+}
+
+func (r *memoryRepository) GetBrandImage(ctx context.Context, brandID int) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.brands[brandID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return b.ImageData, nil
+}
+
+func (r *memoryRepository) GetTableRows(ctx context.Context, tableName string) ([][]interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var data [][]interface{}
+
+	switch tableName {
+	case "driver_categories":
+		for _, id := range sortedIDs(r.categories) {
+			c := r.categories[id]
+			data = append(data, []interface{}{int64(c.ID), c.Code, c.Name, c.Description})
+		}
+	case "owners":
+		for _, id := range sortedIDs(r.owners) {
+			o := r.owners[id]
+			if o.deleted() {
+				continue
+			}
+			carCount := 0
+			for _, c := range r.cars {
+				if c.OwnerID == o.ID && !c.deleted() {
+					carCount++
+				}
+			}
+			experience := time.Now().Year() - o.RegistrationDate.Year()
+			data = append(data, []interface{}{int64(o.ID), o.FirstName, o.LastName, o.Phone, o.Email,
+				r.categories[o.CategoryID].Code, o.RegistrationDate, int64(carCount), int64(experience)})
+		}
+	case "car_brands":
+		for _, id := range sortedIDs(r.brands) {
+			b := r.brands[id]
+			data = append(data, []interface{}{int64(b.ID), b.Name, b.Country, int64(b.FoundedYear), b.ImageData})
+		}
+	case "cars":
+		for _, id := range sortedIDs(r.cars) {
+			c := r.cars[id]
+			if c.deleted() {
+				continue
+			}
+			o := r.owners[c.OwnerID]
+			data = append(data, []interface{}{int64(c.ID), o.FirstName + " " + o.LastName, r.brands[c.BrandID].Name,
+				c.Model, int64(c.Year), c.Color, c.VIN, c.Price, depreciatedValue(c.Price, c.Year), c.PurchaseDate})
+		}
+	default:
+		return nil, fmt.Errorf("неизвестная таблица: %s", tableName)
+	}
+	return data, nil
+}
+
+func (r *memoryRepository) QueryTable(ctx context.Context, tableName string, q TableQuery) ([][]interface{}, error) {
+	data, err := r.GetTableRows(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	var result [][]interface{}
+	for _, row := range data {
+		if rowMatches(row, q.Filters) {
+			result = append(result, row)
+		}
+	}
+
+	if q.Sort != nil {
+		col, desc := q.Sort.Column, q.Sort.Desc
+		if col < 0 || col >= len(tableColumnNames(tableName)) {
+			return nil, fmt.Errorf("неверный номер колонки: %d", col)
+		}
+		sort.SliceStable(result, func(i, j int) bool {
+			if desc {
+				return compareCells(result[j][col], result[i][col]) < 0
+			}
+			return compareCells(result[i][col], result[j][col]) < 0
+		})
+	}
+
+	if q.Limit > 0 {
+		if q.Offset >= len(result) {
+			return nil, nil
+		}
+		result = result[q.Offset:min(q.Offset+q.Limit, len(result))]
+	}
+	return result, nil
+}
+
+func (r *memoryRepository) CountTable(ctx context.Context, tableName string, filters []ColumnFilter) (int, error) {
+	rows, err := r.QueryTable(ctx, tableName, TableQuery{Filters: filters})
+	return len(rows), err
+}
+
+// rowMatches applies column filters the way the SQL WHERE clause does
+func rowMatches(row []interface{}, filters []ColumnFilter) bool {
+	for _, f := range filters {
+		if f.Column < 0 || f.Column >= len(row) {
+			return false
+		}
+		value := row[f.Column]
+
+		if f.Contains != "" && !strings.Contains(strings.ToLower(formatCellValue(value)), strings.ToLower(f.Contains)) {
+			return false
+		}
+		if f.Min != nil || f.Max != nil {
+			number, ok := cellNumber(value)
+			if !ok || (f.Min != nil && number < *f.Min) || (f.Max != nil && number > *f.Max) {
+				return false
+			}
+		}
+		if f.From != nil || f.To != nil {
+			date, ok := parseDateValue(value)
+			day := date.Format("2006-01-02")
+			if !ok || (f.From != nil && day < f.From.Format("2006-01-02")) || (f.To != nil && day > f.To.Format("2006-01-02")) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// cellNumber returns the numeric value of a table cell
+func cellNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case []byte:
+		number, err := strconv.ParseFloat(string(v), 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// compareCells orders table cells; NULL goes first like in SQL
+func compareCells(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	if x, ok := cellNumber(a); ok {
+		if y, ok := cellNumber(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	if x, ok := a.(time.Time); ok {
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+	return strings.Compare(formatCellValue(a), formatCellValue(b))
+}
+
+func (r *memoryRepository) Search(ctx context.Context, text string, limit int) ([]SearchResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	text = strings.ToLower(text)
+	match := func(values ...string) bool {
+		for _, v := range values {
+			if strings.Contains(strings.ToLower(v), text) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var results []SearchResult
+	found := 0
+	for _, id := range sortedIDs(r.owners) {
+		o := r.owners[id]
+		name := o.FirstName + " " + o.LastName
+		if found < limit && !o.deleted() && match(o.FirstName, o.LastName, name, o.Phone, o.Email) {
+			results = append(results, SearchResult{"owners", o.ID, name, o.Phone + " " + o.Email})
+			found++
+		}
+	}
+
+	found = 0
+	for _, id := range sortedIDs(r.cars) {
+		c := r.cars[id]
+		if found < limit && !c.deleted() && match(c.Model, c.VIN, c.Color) {
+			o := r.owners[c.OwnerID]
+			vin := c.VIN
+			if vin == "" {
+				vin = "-"
+			}
+			results = append(results, SearchResult{"cars", c.ID,
+				fmt.Sprintf("%s %s (%d)", r.brands[c.BrandID].Name, c.Model, c.Year),
+				fmt.Sprintf("VIN %s, %s, %s %s", vin, c.Color, o.FirstName, o.LastName)})
+			found++
+		}
+	}
+
+	found = 0
+	for _, id := range sortedIDs(r.brands) {
+		b := r.brands[id]
+		if found < limit && match(b.Name) {
+			results = append(results, SearchResult{"car_brands", b.ID, b.Name, b.Country})
+			found++
+		}
+	}
+	return results, nil
+}
+
+// --- Writes (Create/Update/Delete) ---
+
+func (r *memoryRepository) AddOwner(ctx context.Context, firstName, lastName, phone, email string, categoryID int) (int, error) {
+	defer r.lockWrite()()
+
+	id, err := r.addOwner(firstName, lastName, phone, email, categoryID)
+	if err == nil {
+		r.audit("owners", id, nil)
+	}
+	return id, err
+}
+
+func (r *memoryRepository) addOwner(firstName, lastName, phone, email string, categoryID int) (int, error) {
+	if _, ok := r.categories[categoryID]; !ok {
+		return 0, fmt.Errorf("%w: категория прав %d", errInvalidReference, categoryID)
+	}
+
+	owner := &memOwner{
+		Owner: Owner{
+			ID:         r.nextOwnerID,
+			FirstName:  firstName,
+			LastName:   lastName,
+			Phone:      phone,
+			Email:      email,
+			RowVersion: newRowVersion(),
+		},
+		CategoryID:       categoryID,
+		RegistrationDate: time.Now(),
+	}
+	r.owners[owner.ID] = owner
+	r.nextOwnerID++
+	return owner.ID, nil
+}
+
+func (r *memoryRepository) AddCar(ctx context.Context, ownerID, brandID int, model string, year int, color, vin string, price float64) (int, error) {
+	defer r.lockWrite()()
+
+	id, err := r.addCar(ownerID, brandID, model, year, color, vin, price)
+	if err == nil {
+		r.audit("cars", id, nil)
+	}
+	return id, err
+}
+
+func (r *memoryRepository) addCar(ownerID, brandID int, model string, year int, color, vin string, price float64) (int, error) {
+	if err := r.checkCar(0, ownerID, brandID, vin, price); err != nil {
+		return 0, err
+	}
+
+	car := &memCar{
+		Car: Car{
+			ID:         r.nextCarID,
+			OwnerID:    ownerID,
+			BrandID:    brandID,
+			Model:      model,
+			Year:       year,
+			Color:      color,
+			VIN:        vin,
+			Price:      price,
+			RowVersion: newRowVersion(),
+		},
+		PurchaseDate: time.Now(),
+	}
+	r.cars[car.ID] = car
+	r.nextCarID++
+	return car.ID, nil
+}
+
+func (r *memoryRepository) UpdateOwner(ctx context.Context, id int, firstName, lastName, phone, email string, categoryID int, rowVersion []byte) error {
+	defer r.lockWrite()()
+
+	o, ok := r.activeOwner(id)
+	if !ok || !bytes.Equal(o.RowVersion, rowVersion) {
+		return errConcurrentUpdate
+	}
+	if _, ok := r.categories[categoryID]; !ok {
+		return fmt.Errorf("%w: категория прав %d", errInvalidReference, categoryID)
+	}
+
+	before := r.auditValues("owners", id)
+	o.FirstName = firstName
+	o.LastName = lastName
+	o.Phone = phone
+	o.Email = email
+	o.CategoryID = categoryID
+	o.RowVersion = newRowVersion()
+	r.audit("owners", id, before)
+	return nil
+}
+
+func (r *memoryRepository) UpdateCar(ctx context.Context, id int, ownerID, brandID int, model string, year int, color, vin string, price float64, rowVersion []byte) error {
+	defer r.lockWrite()()
+
+	c, ok := r.activeCar(id)
+	if ok && c.OwnerID != ownerID {
+		return errOwnerChange
+	}
+	if !ok || !bytes.Equal(c.RowVersion, rowVersion) {
+		return errConcurrentUpdate
+	}
+	if err := r.checkCar(id, ownerID, brandID, vin, price); err != nil {
+		return err
+	}
+
+	before := r.auditValues("cars", id)
+	c.BrandID = brandID
+	c.Model = model
+	c.Year = year
+	c.Color = color
+	c.VIN = vin
+	c.Price = price
+	c.RowVersion = newRowVersion()
+	r.audit("cars", id, before)
+	return nil
+}
+
+// checkCar enforces the cars table constraints (FK owner/brand, unique VIN, price >= 0)
+func (r *memoryRepository) checkCar(carID, ownerID, brandID int, vin string, price float64) error {
+	if _, ok := r.owners[ownerID]; !ok {
+		return fmt.Errorf("%w: владелец %d", errInvalidReference, ownerID)
+	}
+	if _, ok := r.brands[brandID]; !ok {
+		return fmt.Errorf("%w: марка %d", errInvalidReference, brandID)
+	}
+	if price < 0 {
+		return fmt.Errorf("цена не может быть отрицательной")
+	}
+	if vin != "" {
+		for _, c := range r.cars {
+			if c.ID != carID && c.VIN == vin {
+				return fmt.Errorf("%w: VIN %s", errDuplicate, vin)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepository) DeleteOwner(ctx context.Context, id int, rowVersion []byte) error {
+	defer r.lockWrite()()
+
+	o, ok := r.activeOwner(id)
+	if !ok {
+		return errNotActive(id)
+	}
+	if rowVersion != nil && !bytes.Equal(o.RowVersion, rowVersion) {
+		return errConcurrentUpdate
+	}
+	r.deleteOwner(id, deletion{DeletedAt: time.Now(), DeletedBy: r.user})
+	return nil
+}
+
+// deleteOwner moves an active owner and their cars to the recycle bin and
+// returns how many cars went with them; ok is false when the owner is not active
+func (r *memoryRepository) deleteOwner(id int, mark deletion) (cars int, ok bool) {
+	o, ok := r.activeOwner(id)
+	if !ok {
+		return 0, false
+	}
+
+	// Автомобили владельца уходят в корзину вместе с ним
+	for _, carID := range sortedIDs(r.cars) {
+		c := r.cars[carID]
+		if c.OwnerID == id && !c.deleted() {
+			before := r.auditValues("cars", carID)
+			c.deletion = mark
+			c.WithOwner = true
+			c.RowVersion = newRowVersion()
+			r.audit("cars", carID, before)
+			cars++
+		}
+	}
+	before := r.auditValues("owners", id)
+	o.deletion = mark
+	o.RowVersion = newRowVersion()
+	r.audit("owners", id, before)
+	return cars, true
+}
+
+func (r *memoryRepository) DeleteCar(ctx context.Context, id int, rowVersion []byte) error {
+	defer r.lockWrite()()
+
+	c, ok := r.activeCar(id)
+	if !ok {
+		return errNotActive(id)
+	}
+	if rowVersion != nil && !bytes.Equal(c.RowVersion, rowVersion) {
+		return errConcurrentUpdate
+	}
+	r.deleteCar(id, deletion{DeletedAt: time.Now(), DeletedBy: r.user})
+	return nil
+}
+
+// deleteCar moves an active car to the recycle bin; false when the car is not active
+func (r *memoryRepository) deleteCar(id int, mark deletion) bool {
+	c, ok := r.activeCar(id)
+	if !ok {
+		return false
+	}
+
+	before := r.auditValues("cars", id)
+	c.deletion = mark
+	c.WithOwner = false
+	c.RowVersion = newRowVersion()
+	r.audit("cars", id, before)
+	return true
+}
+
+// --- Unit of work ---
+
+// memorySnapshot is a copy of the repository data for rolling back a unit of work
+type memorySnapshot struct {
+	owners   map[int]memOwner
+	brands   map[int]memBrand
+	cars     map[int]memCar
+	history  []OwnershipTransfer
+	auditLog []AuditEntry
+	appUsers map[string]string
+
+	nextOwnerID, nextCarID, nextHistoryID, nextAuditID int
+}
+
+// lockWrite locks the data for a write and returns the unlock. Outside a unit
+// of work the write also waits for the running unit to finish, so that its
+// rollback cannot undo the write.
+func (r *memoryRepository) lockWrite() func() {
+	if !r.unit {
+		r.writeMu.Lock()
+	}
+	r.mu.Lock()
+	return func() {
+		r.mu.Unlock()
+		if !r.unit {
+			r.writeMu.Unlock()
+		}
+	}
+}
+
+// InTransaction saves the data, calls fn with a repository bound to the unit
+// of work and restores the data when fn fails. Units and writes of other
+// callers wait until the unit ends; reads outside the unit see its
+// uncommitted changes. A nested call on the unit's repository joins it.
+func (r *memoryRepository) InTransaction(ctx context.Context, fn func(repo Repository) error) (err error) {
+	if r.unit {
+		return fn(r)
+	}
+
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	saved := r.snapshot()
+	defer func() {
+		if p := recover(); p != nil {
+			r.restore(saved)
+			panic(p)
+		}
+	}()
+
+	unit := &memoryRepository{memoryStore: r.memoryStore, unit: true}
+	if err := fn(unit); err != nil {
+		r.restore(saved)
+		return err
+	}
+	return nil
+}
+
+// importNotCommitted matches the SQL backends: inside a unit of work an
+// import that is not committed fails the whole unit
+func (r *memoryRepository) importNotCommitted() error {
+	if r.unit {
+		return errImportNotCommitted
+	}
+	return nil
+}
+
+func (r *memoryRepository) snapshot() *memorySnapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := &memorySnapshot{
+		owners:        make(map[int]memOwner, len(r.owners)),
+		brands:        make(map[int]memBrand, len(r.brands)),
+		cars:          make(map[int]memCar, len(r.cars)),
+		history:       append([]OwnershipTransfer(nil), r.history...),
+		auditLog:      append([]AuditEntry(nil), r.auditLog...),
+		appUsers:      make(map[string]string, len(r.appUsers)),
+		nextOwnerID:   r.nextOwnerID,
+		nextCarID:     r.nextCarID,
+		nextHistoryID: r.nextHistoryID,
+		nextAuditID:   r.nextAuditID,
+	}
+	for id, o := range r.owners {
+		s.owners[id] = *o
+	}
+	for id, b := range r.brands {
+		s.brands[id] = *b
+	}
+	for id, c := range r.cars {
+		s.cars[id] = *c
+	}
+	for name, role := range r.appUsers {
+		s.appUsers[name] = role
+	}
+	return s
+}
+
+func (r *memoryRepository) restore(s *memorySnapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.owners = make(map[int]*memOwner, len(s.owners))
+	for id, o := range s.owners {
+		o := o
+		r.owners[id] = &o
+	}
+	r.brands = make(map[int]*memBrand, len(s.brands))
+	for id, b := range s.brands {
+		b := b
+		r.brands[id] = &b
+	}
+	r.cars = make(map[int]*memCar, len(s.cars))
+	for id, c := range s.cars {
+		c := c
+		r.cars[id] = &c
+	}
+	r.history = s.history
+	r.auditLog = s.auditLog
+	r.appUsers = s.appUsers
+	r.nextOwnerID = s.nextOwnerID
+	r.nextCarID = s.nextCarID
+	r.nextHistoryID = s.nextHistoryID
+	r.nextAuditID = s.nextAuditID
+}
+
+// --- Bulk operations ---
+
+func (r *memoryRepository) BulkDeleteOwners(ctx context.Context, ids []int) (BulkResult, error) {
+	defer r.lockWrite()()
+
+	var result BulkResult
+	mark := deletion{DeletedAt: time.Now(), DeletedBy: r.user}
+	for _, id := range ids {
+		if cars, ok := r.deleteOwner(id, mark); ok {
+			result.Owners++
+			result.Cars += cars
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryRepository) BulkDeleteCars(ctx context.Context, ids []int) (BulkResult, error) {
+	defer r.lockWrite()()
+
+	var result BulkResult
+	mark := deletion{DeletedAt: time.Now(), DeletedBy: r.user}
+	for _, id := range ids {
+		if r.deleteCar(id, mark) {
+			result.Cars++
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryRepository) BulkUpdateCars(ctx context.Context, ids []int, changes CarChanges) (int, error) {
+	if changes.OwnerID == nil && changes.BrandID == nil && changes.Color == nil {
+		return 0, errNoChanges
+	}
+
+	defer r.lockWrite()()
+
+	// Все проверки до первого изменения: либо все автомобили, либо ни одного
+	var to *memOwner
+	if changes.OwnerID != nil {
+		var ok bool
+		if to, ok = r.activeOwner(*changes.OwnerID); !ok {
+			return 0, fmt.Errorf("%w: владелец %d", errInvalidReference, *changes.OwnerID)
+		}
+	}
+	if changes.BrandID != nil {
+		if _, ok := r.brands[*changes.BrandID]; !ok {
+			return 0, fmt.Errorf("%w: марка %d", errInvalidReference, *changes.BrandID)
+		}
+	}
+
+	updated := 0
+	for _, id := range ids {
+		c, ok := r.activeCar(id)
+		if !ok {
+			continue
+		}
+		before := r.auditValues("cars", id)
+		if to != nil && c.OwnerID != to.ID {
+			r.recordTransfer(c, to, time.Now(), 0)
+			c.OwnerID = to.ID
+		}
+		if changes.BrandID != nil {
+			c.BrandID = *changes.BrandID
+		}
+		if changes.Color != nil {
+			c.Color = *changes.Color
+		}
+		c.RowVersion = newRowVersion()
+		r.audit("cars", id, before)
+		updated++
+	}
+	return updated, nil
+}
+
+// activeOwner returns the owner unless it is missing or in the recycle bin
+func (r *memoryRepository) activeOwner(id int) (*memOwner, bool) {
+	o, ok := r.owners[id]
+	if !ok || o.deleted() {
+		return nil, false
+	}
+	return o, true
+}
+
+// activeCar returns the car unless it is missing or in the recycle bin
+func (r *memoryRepository) activeCar(id int) (*memCar, bool) {
+	c, ok := r.cars[id]
+	if !ok || c.deleted() {
+		return nil, false
+	}
+	return c, true
+}
+
+// --- Recycle bin ---
+
+func (r *memoryRepository) GetDeleted(ctx context.Context) ([]DeletedRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var records []DeletedRecord
+	for _, id := range sortedIDs(r.owners) {
+		o := r.owners[id]
+		if o.deleted() {
+			records = append(records, DeletedRecord{"owners", o.ID, o.FirstName + " " + o.LastName,
+				o.DeletedAt, o.DeletedBy, o.ID, false})
+		}
+	}
+	for _, id := range sortedIDs(r.cars) {
+		c := r.cars[id]
+		if c.deleted() {
+			vin := c.VIN
+			if vin == "" {
+				vin = "-"
+			}
+			records = append(records, DeletedRecord{"cars", c.ID,
+				fmt.Sprintf("%s %s (%d), VIN %s", r.brands[c.BrandID].Name, c.Model, c.Year, vin),
+				c.DeletedAt, c.DeletedBy, c.OwnerID, c.WithOwner})
+		}
+	}
+
+	// Как ORDER BY deleted_at DESC, владелец перед своими автомобилями
+	sort.SliceStable(records, func(i, j int) bool {
+		if !records[i].DeletedAt.Equal(records[j].DeletedAt) {
+			return records[i].DeletedAt.After(records[j].DeletedAt)
+		}
+		return records[i].Table > records[j].Table
+	})
+	return records, nil
+}
+
+func (r *memoryRepository) RestoreOwner(ctx context.Context, id int) error {
+	defer r.lockWrite()()
+
+	o, ok := r.owners[id]
+	if !ok || !o.deleted() {
+		return errNotInRecycleBin
+	}
+
+	before := r.auditValues("owners", id)
+	o.deletion = deletion{}
+	o.RowVersion = newRowVersion()
+	r.audit("owners", id, before)
+
+	for _, carID := range sortedIDs(r.cars) {
+		c := r.cars[carID]
+		if c.OwnerID == id && c.WithOwner {
+			before := r.auditValues("cars", carID)
+			c.deletion = deletion{}
+			c.WithOwner = false
+			c.RowVersion = newRowVersion()
+			r.audit("cars", carID, before)
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepository) RestoreCar(ctx context.Context, id int) error {
+	defer r.lockWrite()()
+
+	c, ok := r.cars[id]
+	if !ok || !c.deleted() {
+		return errNotInRecycleBin
+	}
+	if r.owners[c.OwnerID].deleted() {
+		return errOwnerDeleted
+	}
+
+	before := r.auditValues("cars", id)
+	c.deletion = deletion{}
+	c.WithOwner = false
+	c.RowVersion = newRowVersion()
+	r.audit("cars", id, before)
+	return nil
+}
+
+func (r *memoryRepository) PurgeOwner(ctx context.Context, id int) error {
+	defer r.lockWrite()()
+
+	o, ok := r.owners[id]
+	if !ok || !o.deleted() {
+		return errNotInRecycleBin
+	}
+
+	// ON DELETE CASCADE
+	for _, carID := range sortedIDs(r.cars) {
+		if r.cars[carID].OwnerID == id {
+			r.purgeCar(carID)
+		}
+	}
+	before := r.auditValues("owners", id)
+	delete(r.owners, id)
+	r.audit("owners", id, before)
+	return nil
+}
+
+func (r *memoryRepository) PurgeCar(ctx context.Context, id int) error {
+	defer r.lockWrite()()
+
+	c, ok := r.cars[id]
+	if !ok || !c.deleted() {
+		return errNotInRecycleBin
+	}
+	r.purgeCar(id)
+	return nil
+}
+
+// purgeCar removes the car with its ownership history
+func (r *memoryRepository) purgeCar(id int) {
+	before := r.auditValues("cars", id)
+	delete(r.cars, id)
+	r.deleteHistory(id)
+	r.audit("cars", id, before)
+}
+
+// --- Ownership transfer ---
+
+func (r *memoryRepository) TransferOwnership(ctx context.Context, carID, toOwnerID int, date time.Time, salePrice float64, rowVersion []byte) error {
+	defer r.lockWrite()()
+
+	c, ok := r.activeCar(carID)
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if c.OwnerID == toOwnerID {
+		return errSameOwner
+	}
+	if !bytes.Equal(c.RowVersion, rowVersion) {
+		return errConcurrentUpdate
+	}
+	to, ok := r.activeOwner(toOwnerID)
+	if !ok {
+		return fmt.Errorf("%w: владелец %d", errInvalidReference, toOwnerID)
+	}
+	if salePrice < 0 {
+		return fmt.Errorf("цена продажи не может быть отрицательной")
+	}
+
+	before := r.auditValues("cars", carID)
+	r.recordTransfer(c, to, date, salePrice)
+	c.OwnerID = toOwnerID
+	c.RowVersion = newRowVersion()
+	r.audit("cars", carID, before)
+	return nil
+}
+
+// recordTransfer appends the transfer of c to the new owner to ownership_history
+func (r *memoryRepository) recordTransfer(c *memCar, to *memOwner, date time.Time, salePrice float64) {
+	transfer := OwnershipTransfer{
+		ID:        r.nextHistoryID,
+		CarID:     c.ID,
+		ToOwnerID: to.ID,
+		ToOwner:   to.FirstName + " " + to.LastName,
+		Date:      date,
+		SalePrice: salePrice,
+	}
+	if from, ok := r.owners[c.OwnerID]; ok {
+		transfer.FromOwnerID = from.ID
+		transfer.FromOwner = from.FirstName + " " + from.LastName
+	}
+	r.nextHistoryID++
+	r.history = append(r.history, transfer)
+}
+
+func (r *memoryRepository) GetOwnershipHistory(ctx context.Context, carID int) ([]OwnershipTransfer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var history []OwnershipTransfer
+	for _, t := range r.history {
+		if t.CarID == carID {
+			history = append(history, t)
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Date.Before(history[j].Date)
+	})
+	return history, nil
+}
+
+// deleteHistory removes the transfers of a deleted car (ON DELETE CASCADE)
+func (r *memoryRepository) deleteHistory(carID int) {
+	kept := r.history[:0]
+	for _, t := range r.history {
+		if t.CarID != carID {
+			kept = append(kept, t)
+		}
+	}
+	r.history = kept
+}
+
+func (r *memoryRepository) MassPriceUpdate(ctx context.Context, brandID int, percentage float64) error {
+	defer r.lockWrite()()
+
+	updated := 0
+	for _, id := range sortedIDs(r.cars) {
+		c := r.cars[id]
+		if c.BrandID == brandID && !c.deleted() {
+			before := r.auditValues("cars", id)
+			c.Price = c.Price * (1.0 + percentage/100.0)
+			c.RowVersion = newRowVersion()
+			r.audit("cars", id, before)
+			updated++
+		}
+	}
+	if updated == 0 {
+		return errNoCarsForBrand
+	}
+	return nil
+}
+
+func (r *memoryRepository) UpdateBrandImage(ctx context.Context, brandID int, imageData []byte, rowVersion []byte) error {
+	defer r.lockWrite()()
+
+	b, ok := r.brands[brandID]
+	if rowVersion != nil && (!ok || !bytes.Equal(b.RowVersion, rowVersion)) {
+		return errConcurrentUpdate
+	}
+	if ok {
+		before := r.auditValues("car_brands", brandID)
+		b.ImageData = imageData
+		b.RowVersion = newRowVersion()
+		r.audit("car_brands", brandID, before)
+	}
+	return nil
+}
+
+// --- CSV import ---
+
+func (r *memoryRepository) ImportOwners(ctx context.Context, owners []OwnerImport, commit bool) (map[int]error, error) {
+	defer r.lockWrite()()
+
+	rowErrors := make(map[int]error)
+	nextID := r.nextOwnerID
+	var added []int
+	for i, o := range owners {
+		id, err := r.addOwner(o.FirstName, o.LastName, o.Phone, o.Email, o.CategoryID)
+		if err != nil {
+			rowErrors[i] = err
+			continue
+		}
+		added = append(added, id)
+	}
+
+	if !commit || len(rowErrors) > 0 {
+		// Откат: удаляем добавленные строки
+		for _, id := range added {
+			delete(r.owners, id)
+		}
+		r.nextOwnerID = nextID
+		return rowErrors, r.importNotCommitted()
+	}
+
+	for _, id := range added {
+		r.audit("owners", id, nil)
+	}
+	return rowErrors, nil
+}
+
+func (r *memoryRepository) ImportCars(ctx context.Context, cars []Car, commit bool) (map[int]error, error) {
+	defer r.lockWrite()()
+
+	rowErrors := make(map[int]error)
+	nextID := r.nextCarID
+	var added []int
+	for i, c := range cars {
+		id, err := r.addCar(c.OwnerID, c.BrandID, c.Model, c.Year, c.Color, c.VIN, c.Price)
+		if err != nil {
+			rowErrors[i] = err
+			continue
+		}
+		added = append(added, id)
+	}
+
+	if !commit || len(rowErrors) > 0 {
+		for _, id := range added {
+			delete(r.cars, id)
+		}
+		r.nextCarID = nextID
+		return rowErrors, r.importNotCommitted()
+	}
+
+	for _, id := range added {
+		r.audit("cars", id, nil)
+	}
+	return rowErrors, nil
+}
+
+// --- Application users ---
+
+func (r *memoryRepository) CurrentUser(ctx context.Context) (AppUser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	role, ok := r.appUsers[r.user]
+	if !ok {
+		role = roleViewer
+	}
+	return AppUser{Name: r.user, Role: role}, nil
+}
+
+func (r *memoryRepository) GetAppUsers(ctx context.Context) ([]AppUser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := make([]AppUser, 0, len(r.appUsers))
+	for name, role := range r.appUsers {
+		users = append(users, AppUser{Name: name, Role: role})
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	return users, nil
+}
+
+func (r *memoryRepository) SetAppUser(ctx context.Context, name, role string) error {
+	if err := checkAppUser(name, role); err != nil {
+		return err
+	}
+
+	defer r.lockWrite()()
+
+	previous, existed := r.appUsers[name]
+	r.appUsers[name] = role
+	if !r.hasAdmin() {
+		if existed {
+			r.appUsers[name] = previous
+		} else {
+			delete(r.appUsers, name)
+		}
+		return errLastAdmin
+	}
+	return nil
+}
+
+func (r *memoryRepository) DeleteAppUser(ctx context.Context, name string) error {
+	defer r.lockWrite()()
+
+	role, ok := r.appUsers[name]
+	if !ok {
+		return nil
+	}
+	delete(r.appUsers, name)
+	if !r.hasAdmin() {
+		r.appUsers[name] = role
+		return errLastAdmin
+	}
+	return nil
+}
+
+// hasAdmin reports whether an admin remains; r.mu must be held
+func (r *memoryRepository) hasAdmin() bool {
+	for _, role := range r.appUsers {
+		if role == roleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// --- Audit log ---
+
+// auditValues returns the audited columns of a record the way the SQL
+// backends store them in audit_log; nil if the record does not exist
+func (r *memoryRepository) auditValues(table string, id int) map[string]string {
+	var values []interface{}
+	switch table {
+	case "owners":
+		o, ok := r.owners[id]
+		if !ok {
+			return nil
+		}
+		values = []interface{}{o.ID, o.FirstName, o.LastName, o.Phone, o.Email, o.CategoryID, o.deletedAt()}
+	case "cars":
+		c, ok := r.cars[id]
+		if !ok {
+			return nil
+		}
+		values = []interface{}{c.ID, c.OwnerID, c.BrandID, c.Model, c.Year, c.Color, c.VIN, c.Price, c.deletedAt()}
+	case "car_brands":
+		b, ok := r.brands[id]
+		if !ok {
+			return nil
+		}
+		values = []interface{}{b.ID, b.Name, b.ImageData}
+	default:
+		return nil
+	}
+
+	record := make(map[string]string, len(values))
+	for i, column := range auditedTables[table].columns {
+		record[column] = auditValue(column, values[i])
+	}
+	return record
+}
+
+// audit logs the change of one record; before is auditValues taken before the write
+func (r *memoryRepository) audit(table string, id int, before map[string]string) {
+	after := r.auditValues(table, id)
+	operation, ok := auditOperation(before, after)
+	if !ok {
+		return
+	}
+
+	r.auditLog = append(r.auditLog, AuditEntry{
+		ID:        r.nextAuditID,
+		Time:      time.Now(),
+		User:      r.user,
+		Table:     table,
+		RecordID:  id,
+		Operation: operation,
+		Before:    before,
+		After:     after,
+	})
+	r.nextAuditID++
+}
+
+func (r *memoryRepository) GetAuditLog(ctx context.Context, filter AuditFilter) ([]AuditEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var entries []AuditEntry
+	for i := len(r.auditLog) - 1; i >= 0; i-- {
+		e := r.auditLog[i]
+		switch {
+		case filter.Table != "" && e.Table != filter.Table,
+			filter.Operation != "" && e.Operation != filter.Operation,
+			filter.User != "" && !strings.Contains(strings.ToLower(e.User), strings.ToLower(filter.User)),
+			filter.RecordID != 0 && e.RecordID != filter.RecordID,
+			filter.From != nil && e.Time.Format("2006-01-02") < filter.From.Format("2006-01-02"),
+			filter.To != nil && e.Time.Format("2006-01-02") > filter.To.Format("2006-01-02"):
+			continue
+		}
+
+		entries = append(entries, e)
+		if filter.Limit > 0 && len(entries) == filter.Limit {
+			break
+		}
+	}
+	return entries, nil
+}
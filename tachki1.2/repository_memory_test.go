@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestMemoryOwnerCRUD(t *testing.T) {
+	repo := newMemoryRepository()
+	ctx := context.Background()
+
+	id, err := repo.AddOwner(ctx, "Иван", "Петров", "+79001234567", "ivan@example.com", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, err := repo.GetOwnerByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner.FirstName != "Иван" || owner.Category != "B" || len(owner.RowVersion) == 0 {
+		t.Fatalf("владелец: %+v", owner)
+	}
+
+	if err := repo.UpdateOwner(ctx, id, "Иван", "Сидоров", "", "", 2, owner.RowVersion); err != nil {
+		t.Fatal(err)
+	}
+	// Старая версия строки уже не подходит
+	if err := repo.UpdateOwner(ctx, id, "Иван", "Иванов", "", "", 2, owner.RowVersion); !errors.Is(err, errConcurrentUpdate) {
+		t.Errorf("изменение по старой версии: %v", err)
+	}
+	updated, err := repo.GetOwnerByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.LastName != "Сидоров" || updated.Category != "C" {
+		t.Errorf("после изменения: %+v", updated)
+	}
+
+	if err := repo.DeleteOwner(ctx, id, owner.RowVersion); !errors.Is(err, errConcurrentUpdate) {
+		t.Errorf("удаление по старой версии: %v", err)
+	}
+	if err := repo.DeleteOwner(ctx, id, updated.RowVersion); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetOwnerByID(ctx, id); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("удалённый владелец читается: %v", err)
+	}
+	if err := repo.DeleteOwner(ctx, id, nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("повторное удаление: %v", err)
+	}
+}
+
+// TestMemoryRecycleBin checks that the cars of a deleted owner go to the
+// recycle bin with them and come back with them
+func TestMemoryRecycleBin(t *testing.T) {
+	repo := newMemoryRepository()
+	ctx := context.Background()
+
+	ownerID, err := repo.AddOwner(ctx, "Иван", "Петров", "", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	carID, err := repo.AddCar(ctx, ownerID, 1, "Camry", 2020, "", "JT2BF22K1W0123456", 25000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.DeleteOwner(ctx, ownerID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if cars, err := repo.GetCars(ctx); err != nil || len(cars) != 0 {
+		t.Fatalf("автомобили удалённого владельца: %v, %v", cars, err)
+	}
+	deleted, err := repo.GetDeleted(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 2 {
+		t.Fatalf("корзина: %+v", deleted)
+	}
+	for _, rec := range deleted {
+		if rec.Table == "cars" && !rec.WithOwner {
+			t.Errorf("автомобиль не помечен как удалённый с владельцем: %+v", rec)
+		}
+	}
+
+	if err := repo.RestoreCar(ctx, carID); !errors.Is(err, errOwnerDeleted) {
+		t.Errorf("восстановление автомобиля без владельца: %v", err)
+	}
+	if err := repo.RestoreOwner(ctx, ownerID); err != nil {
+		t.Fatal(err)
+	}
+	if cars, err := repo.GetCarsByOwner(ctx, ownerID); err != nil || len(cars) != 1 {
+		t.Fatalf("автомобиль не восстановлен с владельцем: %v, %v", cars, err)
+	}
+
+	if err := repo.PurgeCar(ctx, carID); !errors.Is(err, errNotInRecycleBin) {
+		t.Errorf("окончательное удаление активного автомобиля: %v", err)
+	}
+	if err := repo.DeleteCar(ctx, carID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.PurgeCar(ctx, carID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetCarByID(ctx, carID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("окончательно удалённый автомобиль читается: %v", err)
+	}
+}
+
+func TestMemoryAppUsers(t *testing.T) {
+	repo := newMemoryRepository()
+	ctx := context.Background()
+
+	user, err := repo.CurrentUser(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != roleAdmin {
+		t.Fatalf("создатель базы: %+v, ожидался администратор", user)
+	}
+
+	if err := repo.SetAppUser(ctx, user.Name, roleViewer); !errors.Is(err, errLastAdmin) {
+		t.Errorf("понижение последнего администратора: %v", err)
+	}
+	if err := repo.DeleteAppUser(ctx, user.Name); !errors.Is(err, errLastAdmin) {
+		t.Errorf("удаление последнего администратора: %v", err)
+	}
+
+	if err := repo.SetAppUser(ctx, "DOMAIN\\admin", roleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetAppUser(ctx, user.Name, roleOperator); err != nil {
+		t.Fatal(err)
+	}
+	if user, err = repo.CurrentUser(ctx); err != nil || user.Role != roleOperator {
+		t.Errorf("после смены роли: %+v, %v", user, err)
+	}
+	if err := repo.SetAppUser(ctx, "DOMAIN\\user", "owner"); err == nil {
+		t.Error("назначена неизвестная роль")
+	}
+}
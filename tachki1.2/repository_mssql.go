@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	mssql "github.com/microsoft/go-mssqldb"
+)
+
+// mssqlDialect runs the queries as written; migrations/mssql provides
+// v_owner_details, dbo.fn_GetCarDepreciatedValue and sp_MassPriceUpdate
+var mssqlDialect = &sqlDialect{
+	rebind: func(query string) string {
+		return query
+	},
+	massPriceUpdate: func(ctx context.Context, tx *sql.Tx, brandID int, percentage float64) (sql.Result, error) {
+		// Процедура не открывает своей транзакции (миграция 0007) и
+		// выполняется в транзакции репозитория вместе с audit_log
+		query := "EXEC sp_MassPriceUpdate @BrandID = @p1, @Percentage = @p2"
+		return tx.ExecContext(ctx, query, brandID, percentage)
+	},
+	migrationsDir: "mssql",
+	schemaVersionDDL: `IF OBJECT_ID('dbo.schema_version', 'U') IS NULL
+		CREATE TABLE schema_version (
+			version INT PRIMARY KEY,
+			name NVARCHAR(200) NOT NULL,
+			applied_at DATETIME2 NOT NULL DEFAULT SYSDATETIME()
+		)`,
+	returningID: func(query, column string) string {
+		return strings.Replace(query, "VALUES", "OUTPUT INSERTED."+column+" VALUES", 1)
+	},
+	paginate: func(query string, offset, limit int) string {
+		return query + fmt.Sprintf(" OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", offset, limit)
+	},
+	constraint: func(err error) error {
+		var e mssql.Error
+		if !errors.As(err, &e) {
+			return nil
+		}
+		switch {
+		case e.Number == 2627 || e.Number == 2601: // UNIQUE и уникальный индекс
+			return errDuplicate
+		case e.Number == 547 && strings.Contains(e.Message, "FOREIGN KEY"): // 547 — также CHECK
+			return errInvalidReference
+		}
+		return nil
+	},
+}
+
+// openMSSQL connects to SQL Server using a go-mssqldb connection string
+func openMSSQL(connStr string) (*sqlRepository, error) {
+	db, err := sql.Open("sqlserver", connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	repo := newSQLRepository(db, mssqlDialect)
+	// Логин SQL Server записывается в audit_log
+	if err := repo.queryRow(context.Background(), "SELECT SUSER_SNAME()").Scan(&repo.user); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return repo, nil
+}
+
+// connectionTest is the result of testMSSQL
+type connectionTest struct {
+	Version   string        // первая строка @@VERSION
+	Connect   time.Duration // установка соединения и вход
+	RoundTrip time.Duration // выполнение простого запроса
+}
+
+// testMSSQL connects without opening a repository and reports the server
+// version and how long the connection and a trivial query took
+func testMSSQL(ctx context.Context, connStr string) (*connectionTest, error) {
+	db, err := sql.Open("sqlserver", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	start := time.Now()
+	if err := db.PingContext(ctx); err != nil {
+		return nil, err
+	}
+	result := &connectionTest{Connect: time.Since(start)}
+
+	start = time.Now()
+	if err := db.QueryRowContext(ctx, "SELECT @@VERSION").Scan(&result.Version); err != nil {
+		return nil, err
+	}
+	result.RoundTrip = time.Since(start)
+
+	if i := strings.IndexAny(result.Version, "\r\n"); i >= 0 {
+		result.Version = result.Version[:i]
+	}
+	result.Version = strings.TrimSpace(result.Version)
+	return result, nil
+}
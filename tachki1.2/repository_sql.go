@@ -0,0 +1,1378 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sqlRepository implements Repository on top of database/sql.
+// Queries are written in T-SQL; the dialect adapts them to the backend.
+type sqlRepository struct {
+	db      *sql.DB
+	dialect *sqlDialect
+	user    string  // пользователь БД для audit_log
+	tx      *sql.Tx // транзакция InTransaction; nil — каждый вызов в своей транзакции
+}
+
+// sqlConn is what *sql.DB and *sql.Tx have in common
+type sqlConn interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// sqlDialect describes what differs between the supported SQL backends
+type sqlDialect struct {
+	// rebind rewrites a T-SQL query (with @pN placeholders) for the backend
+	rebind func(query string) string
+	// massPriceUpdate runs the equivalent of sp_MassPriceUpdate inside tx
+	massPriceUpdate func(ctx context.Context, tx *sql.Tx, brandID int, percentage float64) (sql.Result, error)
+	// migrationsDir is the folder under migrations/ with this backend's scripts
+	migrationsDir string
+	// schemaVersionDDL creates the schema_version table if it does not exist
+	schemaVersionDDL string
+	// returningID makes an INSERT ... VALUES statement return the new key column
+	returningID func(query, column string) string
+	// paginate appends the paging clause to a query that ends with ORDER BY
+	paginate func(query string, offset, limit int) string
+	// constraint returns errDuplicate or errInvalidReference when err is a
+	// violated unique or foreign key constraint, otherwise nil
+	constraint func(err error) error
+}
+
+func newSQLRepository(db *sql.DB, dialect *sqlDialect) *sqlRepository {
+	return &sqlRepository{db: db, dialect: dialect}
+}
+
+// Ping checks the connection pool; database/sql redials broken connections
+func (r *sqlRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Close closes the database; for the repository of a unit of work it does
+// nothing, the transaction is finished by InTransaction
+func (r *sqlRepository) Close() error {
+	if r.tx != nil {
+		return nil
+	}
+	return r.db.Close()
+}
+
+// conn returns the transaction of the unit of work or the connection pool
+func (r *sqlRepository) conn() sqlConn {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+func (r *sqlRepository) query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return r.conn().QueryContext(ctx, r.dialect.rebind(query), args...)
+}
+
+func (r *sqlRepository) queryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return r.conn().QueryRowContext(ctx, r.dialect.rebind(query), args...)
+}
+
+func (r *sqlRepository) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return r.conn().ExecContext(ctx, r.dialect.rebind(query), args...)
+}
+
+// constraintError wraps a violated unique or foreign key constraint in
+// errDuplicate or errInvalidReference, keeping the database message
+func (r *sqlRepository) constraintError(err error) error {
+	if kind := r.dialect.constraint(err); kind != nil {
+		return fmt.Errorf("%w: %v", kind, err)
+	}
+	return err
+}
+
+// --- Unit of work ---
+
+func (r *sqlRepository) InTransaction(ctx context.Context, fn func(repo Repository) error) (err error) {
+	// Вложенный вызов присоединяется к уже открытой транзакции
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	unit := &sqlRepository{db: r.db, dialect: r.dialect, user: r.user, tx: tx}
+	if err := fn(unit); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// begin starts the transaction of a write. Inside a unit of work it returns
+// the unit's transaction with own=false: commit and rollback are then left
+// to InTransaction.
+func (r *sqlRepository) begin(ctx context.Context) (tx *sql.Tx, own bool, err error) {
+	if r.tx != nil {
+		return r.tx, false, nil
+	}
+	tx, err = r.db.BeginTx(ctx, nil)
+	return tx, err == nil, err
+}
+
+// --- Reads ---
+
+func (r *sqlRepository) GetDriverCategories(ctx context.Context) ([]DriverCategory, error) {
+	query := "SELECT category_id, category_code, category_name FROM driver_categories"
+	rows, err := r.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []DriverCategory
+	for rows.Next() {
+		var cat DriverCategory
+		err := rows.Scan(&cat.ID, &cat.Code, &cat.Name)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, cat)
+	}
+	return categories, nil
+}
+
+func (r *sqlRepository) GetOwners(ctx context.Context) ([]Owner, error) {
+	query := `SELECT o.owner_id, o.first_name, o.last_name, COALESCE(o.phone, ''), COALESCE(o.email, ''),
+			  dc.category_code, o.row_version
+			  FROM owners o
+			  JOIN driver_categories dc ON o.license_category_id = dc.category_id
+			  WHERE o.deleted_at IS NULL
+			  ORDER BY o.owner_id`
+	rows, err := r.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var owners []Owner
+	for rows.Next() {
+		var owner Owner
+		err := rows.Scan(&owner.ID, &owner.FirstName, &owner.LastName, &owner.Phone, &owner.Email, &owner.Category, &owner.RowVersion)
+		if err != nil {
+			return nil, err
+		}
+		owners = append(owners, owner)
+	}
+	return owners, nil
+}
+
+func (r *sqlRepository) GetCarBrands(ctx context.Context) ([]CarBrand, error) {
+	query := "SELECT brand_id, brand_name, image_data, row_version FROM car_brands"
+	rows, err := r.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var brands []CarBrand
+	for rows.Next() {
+		var brand CarBrand
+		var imageData []byte
+		err := rows.Scan(&brand.ID, &brand.Name, &imageData, &brand.RowVersion)
+		if err != nil {
+			return nil, err
+		}
+		brand.ImageData = imageData
+		brands = append(brands, brand)
+	}
+	return brands, nil
+}
+
+func (r *sqlRepository) GetCarBrand(ctx context.Context, id int) (*CarBrand, error) {
+	query := "SELECT brand_id, brand_name, image_data, row_version FROM car_brands WHERE brand_id = @p1"
+
+	var brand CarBrand
+	err := r.queryRow(ctx, query, id).Scan(&brand.ID, &brand.Name, &brand.ImageData, &brand.RowVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &brand, nil
+}
+
+func (r *sqlRepository) GetOwnerByID(ctx context.Context, id int) (*Owner, error) {
+	query := `SELECT o.owner_id, o.first_name, o.last_name, o.phone, o.email, dc.category_code,
+			  o.row_version
+			  FROM owners o
+			  JOIN driver_categories dc ON o.license_category_id = dc.category_id
+			  WHERE o.owner_id = @p1 AND o.deleted_at IS NULL`
+
+	row := r.queryRow(ctx, query, id)
+
+	var owner Owner
+	err := row.Scan(&owner.ID, &owner.FirstName, &owner.LastName, &owner.Phone, &owner.Email, &owner.Category, &owner.RowVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &owner, nil
+}
+
+func (r *sqlRepository) GetCars(ctx context.Context) ([]Car, error) {
+	return r.queryCars(ctx, "")
+}
+
+func (r *sqlRepository) GetCarsByOwner(ctx context.Context, ownerID int) ([]Car, error) {
+	return r.queryCars(ctx, "AND owner_id = @p1", ownerID)
+}
+
+// queryCars selects cars not in the recycle bin with the current (depreciated) price;
+// condition is appended to the WHERE clause
+func (r *sqlRepository) queryCars(ctx context.Context, condition string, args ...interface{}) ([]Car, error) {
+	query := `SELECT car_id, owner_id, brand_id, model, year, color, vin_code, price,
+			  dbo.fn_GetCarDepreciatedValue(price, year),
+			  row_version
+			  FROM cars WHERE deleted_at IS NULL ` + condition + `
+			  ORDER BY car_id`
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cars []Car
+	for rows.Next() {
+		var car Car
+		err := rows.Scan(&car.ID, &car.OwnerID, &car.BrandID, &car.Model, &car.Year, &car.Color,
+			&car.VIN, &car.Price, &car.CurrentPrice, &car.RowVersion)
+		if err != nil {
+			return nil, err
+		}
+		cars = append(cars, car)
+	}
+	return cars, rows.Err()
+}
+
+func (r *sqlRepository) GetCarByID(ctx context.Context, id int) (*Car, error) {
+	// Добавили вызов dbo.fn_GetCarDepreciatedValue(price, year) в запрос
+	query := `SELECT car_id, owner_id, brand_id, model, year, color, vin_code, price,
+			  dbo.fn_GetCarDepreciatedValue(price, year),
+			  row_version
+			  FROM cars WHERE car_id = @p1 AND deleted_at IS NULL`
+
+	row := r.queryRow(ctx, query, id)
+
+	var car Car
+	// Добавили &car.CurrentPrice в scan
+	err := row.Scan(&car.ID, &car.OwnerID, &car.BrandID, &car.Model, &car.Year, &car.Color,
+		&car.VIN, &car.Price, &car.CurrentPrice, &car.RowVersion)
+
+	if err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+func (r *sqlRepository) GetBrandImage(ctx context.Context, brandID int) ([]byte, error) {
+	query := "SELECT image_data FROM car_brands WHERE brand_id = @p1"
+	row := r.queryRow(ctx, query, brandID)
+
+	var imageData []byte
+	// Если в базе NULL, Scan запишет nil в imageData, ошибки не будет
+	err := row.Scan(&imageData)
+	if err != nil {
+		return nil, err
+	}
+
+	return imageData, nil
+}
+
+// tableSelect is a View tab query: one SQL expression per column and the FROM clause
+type tableSelect struct {
+	columns []string
+	from    string
+	filter  string // условие, которое действует всегда (например, скрывает корзину)
+}
+
+var tableSelects = map[string]tableSelect{
+	"driver_categories": {
+		columns: []string{"category_id", "category_code", "category_name", "description"},
+		from:    "driver_categories",
+	},
+	// Мы используем созданное представление v_owner_details
+	// Добавляем experience_years в выборку
+	"owners": {
+		columns: []string{"owner_id", "first_name", "last_name", "phone", "email",
+			"category_code", "registration_date", "car_count", "experience_years"},
+		from: "v_owner_details",
+	},
+	"car_brands": {
+		columns: []string{"brand_id", "brand_name", "country_origin", "founded_year", "image_data"},
+		from:    "car_brands",
+	},
+	"cars": {
+		columns: []string{"c.car_id",
+			"CONCAT(o.first_name, ' ', o.last_name)",
+			"b.brand_name",
+			"c.model",
+			"c.year",
+			"c.color",
+			"c.vin_code",
+			"c.price", // Цена покупки
+			"dbo.fn_GetCarDepreciatedValue(c.price, c.year)", // Текущая цена (функция)
+			"c.purchase_date"},
+		from: `cars c
+                 JOIN owners o ON c.owner_id = o.owner_id
+                 JOIN car_brands b ON c.brand_id = b.brand_id`,
+		filter: "c.deleted_at IS NULL",
+	},
+}
+
+func (r *sqlRepository) GetTableRows(ctx context.Context, tableName string) ([][]interface{}, error) {
+	return r.QueryTable(ctx, tableName, TableQuery{})
+}
+
+func (r *sqlRepository) QueryTable(ctx context.Context, tableName string, q TableQuery) ([][]interface{}, error) {
+	table, ok := tableSelects[tableName]
+	if !ok {
+		return nil, fmt.Errorf("неизвестная таблица: %s", tableName)
+	}
+
+	where, args, err := table.where(q.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + strings.Join(table.columns, ", ") + " FROM " + table.from + where
+	if q.Sort != nil {
+		if q.Sort.Column < 0 || q.Sort.Column >= len(table.columns) {
+			return nil, fmt.Errorf("неверный номер колонки: %d", q.Sort.Column)
+		}
+		direction := "ASC"
+		if q.Sort.Desc {
+			direction = "DESC"
+		}
+		// Сортировка по номеру колонки, ID — для стабильного порядка
+		query += fmt.Sprintf(" ORDER BY %d %s, 1", q.Sort.Column+1, direction)
+	}
+
+	if q.Limit > 0 {
+		// OFFSET/FETCH требует ORDER BY
+		if q.Sort == nil {
+			query += " ORDER BY 1"
+		}
+		query = r.dialect.paginate(query, q.Offset, q.Limit)
+	}
+
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	columnCount := len(columns)
+
+	var data [][]interface{}
+	for rows.Next() {
+		values := make([]interface{}, columnCount)
+		valuePtrs := make([]interface{}, columnCount)
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		err := rows.Scan(valuePtrs...)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, values)
+	}
+	return data, rows.Err()
+}
+
+// --- Writes (Create/Update/Delete) ---
+// Every write runs in a transaction together with its audit_log entries.
+
+func (r *sqlRepository) AddOwner(ctx context.Context, firstName, lastName, phone, email string, categoryID int) (int, error) {
+	query := `INSERT INTO owners (first_name, last_name, phone, email, license_category_id)
+              VALUES (@p1, @p2, @p3, @p4, @p5)`
+
+	var id int
+	err := r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{{"owners", "owner_id = @p1", []interface{}{id}}}
+	}, func(tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx, r.dialect.rebind(r.dialect.returningID(query, "owner_id")),
+			firstName, lastName, phone, email, categoryID).Scan(&id)
+	})
+	return id, err
+}
+
+func (r *sqlRepository) AddCar(ctx context.Context, ownerID, brandID int, model string, year int, color, vin string, price float64) (int, error) {
+	query := `INSERT INTO cars (owner_id, brand_id, model, year, color, vin_code, price)
+              VALUES (@p1, @p2, @p3, @p4, @p5, @p6, @p7)`
+
+	var id int
+	err := r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{{"cars", "car_id = @p1", []interface{}{id}}}
+	}, func(tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx, r.dialect.rebind(r.dialect.returningID(query, "car_id")),
+			ownerID, brandID, model, year, color, vin, price).Scan(&id)
+	})
+	return id, err
+}
+
+func (r *sqlRepository) UpdateOwner(ctx context.Context, id int, firstName, lastName, phone, email string, categoryID int, rowVersion []byte) error {
+	query := `UPDATE owners
+			  SET first_name = @p1, last_name = @p2, phone = @p3, email = @p4,
+			      license_category_id = @p5, row_version = NEWID()
+			  WHERE owner_id = @p6 AND row_version = @p7`
+
+	return r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{{"owners", "owner_id = @p1", []interface{}{id}}}
+	}, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, r.dialect.rebind(query), firstName, lastName, phone, email, categoryID, id, rowVersion)
+		return checkRowVersion(result, err)
+	})
+}
+
+func (r *sqlRepository) UpdateCar(ctx context.Context, id int, ownerID, brandID int, model string, year int, color, vin string, price float64, rowVersion []byte) error {
+	query := `UPDATE cars
+			  SET owner_id = @p1, brand_id = @p2, model = @p3, year = @p4, color = @p5,
+			      vin_code = @p6, price = @p7, row_version = NEWID()
+			  WHERE car_id = @p8 AND row_version = @p9`
+
+	return r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{{"cars", "car_id = @p1", []interface{}{id}}}
+	}, func(tx *sql.Tx) error {
+		var currentOwner int
+		err := tx.QueryRowContext(ctx, r.dialect.rebind("SELECT owner_id FROM cars WHERE car_id = @p1"), id).Scan(&currentOwner)
+		switch {
+		case err == nil && currentOwner != ownerID:
+			return errOwnerChange
+		case err != nil && !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, r.dialect.rebind(query), ownerID, brandID, model, year, color, vin, price, id, rowVersion)
+		return checkRowVersion(result, err)
+	})
+}
+
+// checkRowVersion turns an UPDATE ... WHERE row_version = @p that matched nothing into errConcurrentUpdate
+func checkRowVersion(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errConcurrentUpdate
+	}
+	return nil
+}
+
+func (r *sqlRepository) DeleteOwner(ctx context.Context, id int, rowVersion []byte) error {
+	// Автомобили владельца уходят в корзину вместе с ним
+	now := time.Now().Format("2006-01-02 15:04:05")
+	return r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{
+			{"cars", "owner_id = @p1", []interface{}{id}},
+			{"owners", "owner_id = @p1", []interface{}{id}},
+		}
+	}, func(tx *sql.Tx) error {
+		query, args := withRowVersion(`UPDATE owners
+              SET deleted_at = @p1, deleted_by = @p2, row_version = NEWID()
+              WHERE owner_id = @p3 AND deleted_at IS NULL`, rowVersion, now, r.user, id)
+		result, err := tx.ExecContext(ctx, r.dialect.rebind(query), args...)
+		if err := r.checkSoftDelete(ctx, tx, "owners", id, result, err); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, r.dialect.rebind(`UPDATE cars
+              SET deleted_at = @p1, deleted_by = @p2, deleted_with_owner = 1, row_version = NEWID()
+              WHERE owner_id = @p3 AND deleted_at IS NULL`), now, r.user, id)
+		return err
+	})
+}
+
+func (r *sqlRepository) DeleteCar(ctx context.Context, id int, rowVersion []byte) error {
+	now := time.Now().Format("2006-01-02 15:04:05")
+	return r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{{"cars", "car_id = @p1", []interface{}{id}}}
+	}, func(tx *sql.Tx) error {
+		query, args := withRowVersion(`UPDATE cars
+              SET deleted_at = @p1, deleted_by = @p2, deleted_with_owner = 0, row_version = NEWID()
+              WHERE car_id = @p3 AND deleted_at IS NULL`, rowVersion, now, r.user, id)
+		result, err := tx.ExecContext(ctx, r.dialect.rebind(query), args...)
+		return r.checkSoftDelete(ctx, tx, "cars", id, result, err)
+	})
+}
+
+// checkSoftDelete explains a soft delete that changed no row: the record is
+// still active, so its row_version differed (errConcurrentUpdate), or it is
+// missing or already in the recycle bin (errNotActive)
+func (r *sqlRepository) checkSoftDelete(ctx context.Context, tx *sql.Tx, table string, id int, result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected > 0 {
+		return err
+	}
+
+	var active int
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = @p1 AND deleted_at IS NULL", table, auditedTables[table].key)
+	if err := tx.QueryRowContext(ctx, r.dialect.rebind(query), id).Scan(&active); err != nil {
+		return err
+	}
+	if active > 0 {
+		return errConcurrentUpdate
+	}
+	return errNotActive(id)
+}
+
+// withRowVersion appends the optimistic lock condition to a write when
+// rowVersion is set; the version becomes the last parameter
+func withRowVersion(query string, rowVersion []byte, args ...interface{}) (string, []interface{}) {
+	if rowVersion == nil {
+		return query, args
+	}
+	args = append(args, rowVersion)
+	return query + fmt.Sprintf(" AND row_version = @p%d", len(args)), args
+}
+
+// checkOptionalRowVersion is checkRowVersion for a write made without a
+// row_version condition when rowVersion is nil
+func checkOptionalRowVersion(result sql.Result, err error, rowVersion []byte) error {
+	if rowVersion == nil {
+		return err
+	}
+	return checkRowVersion(result, err)
+}
+
+func (r *sqlRepository) MassPriceUpdate(ctx context.Context, brandID int, percentage float64) error {
+	return r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{{"cars", "brand_id = @p1 AND deleted_at IS NULL", []interface{}{brandID}}}
+	}, func(tx *sql.Tx) error {
+		result, err := r.dialect.massPriceUpdate(ctx, tx, brandID, percentage)
+		if err != nil {
+			return err
+		}
+		// Можно даже проверить, сколько записей затронуто
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			return errNoCarsForBrand
+		}
+		return nil
+	})
+}
+
+func (r *sqlRepository) UpdateBrandImage(ctx context.Context, brandID int, imageData []byte, rowVersion []byte) error {
+	query, args := withRowVersion("UPDATE car_brands SET image_data = @p1, row_version = NEWID() WHERE brand_id = @p2",
+		rowVersion, imageData, brandID)
+	return r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{{"car_brands", "brand_id = @p1", []interface{}{brandID}}}
+	}, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, r.dialect.rebind(query), args...)
+		return checkOptionalRowVersion(result, err, rowVersion)
+	})
+}
+
+// --- Ownership transfer ---
+
+func (r *sqlRepository) TransferOwnership(ctx context.Context, carID, toOwnerID int, date time.Time, salePrice float64, rowVersion []byte) error {
+	return r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{{"cars", "car_id = @p1", []interface{}{carID}}}
+	}, func(tx *sql.Tx) error {
+		var fromOwnerID int
+		err := tx.QueryRowContext(ctx, r.dialect.rebind("SELECT owner_id FROM cars WHERE car_id = @p1 AND deleted_at IS NULL"), carID).Scan(&fromOwnerID)
+		if err != nil {
+			return err
+		}
+		if fromOwnerID == toOwnerID {
+			return errSameOwner
+		}
+
+		result, err := tx.ExecContext(ctx, r.dialect.rebind(`UPDATE cars SET owner_id = @p1, row_version = NEWID()
+              WHERE car_id = @p2 AND row_version = @p3`), toOwnerID, carID, rowVersion)
+		if err := checkRowVersion(result, err); err != nil {
+			return err
+		}
+
+		// Имена сохраняются на момент передачи — история переживёт удаление владельца
+		query := `INSERT INTO ownership_history
+                  (car_id, from_owner_id, from_owner_name, to_owner_id, to_owner_name, transfer_date, sale_price)
+              SELECT @p1, f.owner_id, CONCAT(f.first_name, ' ', f.last_name),
+                     t.owner_id, CONCAT(t.first_name, ' ', t.last_name), @p4, @p5
+              FROM owners t
+              LEFT JOIN owners f ON f.owner_id = @p2
+              WHERE t.owner_id = @p3 AND t.deleted_at IS NULL`
+		result, err = tx.ExecContext(ctx, r.dialect.rebind(query), carID, fromOwnerID, toOwnerID, date.Format("2006-01-02"), salePrice)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("%w: владелец %d", errInvalidReference, toOwnerID)
+		}
+		return nil
+	})
+}
+
+func (r *sqlRepository) GetOwnershipHistory(ctx context.Context, carID int) ([]OwnershipTransfer, error) {
+	query := `SELECT history_id, car_id, from_owner_id, from_owner_name,
+                     to_owner_id, to_owner_name, transfer_date, sale_price
+              FROM ownership_history
+              WHERE car_id = @p1
+              ORDER BY transfer_date, history_id`
+
+	rows, err := r.query(ctx, query, carID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []OwnershipTransfer
+	for rows.Next() {
+		var t OwnershipTransfer
+		var fromID sql.NullInt64
+		var fromName sql.NullString
+		var date interface{}
+		if err := rows.Scan(&t.ID, &t.CarID, &fromID, &fromName, &t.ToOwnerID, &t.ToOwner, &date, &t.SalePrice); err != nil {
+			return nil, err
+		}
+		t.FromOwnerID = int(fromID.Int64)
+		t.FromOwner = fromName.String
+		t.Date, _ = parseDateValue(date)
+		history = append(history, t)
+	}
+	return history, rows.Err()
+}
+
+// --- Recycle bin ---
+
+func (r *sqlRepository) GetDeleted(ctx context.Context) ([]DeletedRecord, error) {
+	query := `SELECT 'owners', owner_id, CONCAT(first_name, ' ', last_name),
+                     deleted_at, COALESCE(deleted_by, ''), owner_id, 0
+              FROM owners
+              WHERE deleted_at IS NOT NULL
+              UNION ALL
+              SELECT 'cars', c.car_id, CONCAT(b.brand_name, ' ', c.model, ' (', c.year, '), VIN ', COALESCE(c.vin_code, '-')),
+                     c.deleted_at, COALESCE(c.deleted_by, ''), c.owner_id, c.deleted_with_owner
+              FROM cars c
+              JOIN car_brands b ON c.brand_id = b.brand_id
+              WHERE c.deleted_at IS NOT NULL
+              ORDER BY 4 DESC, 1 DESC, 2`
+
+	rows, err := r.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []DeletedRecord
+	for rows.Next() {
+		var rec DeletedRecord
+		var deletedAt interface{}
+		if err := rows.Scan(&rec.Table, &rec.ID, &rec.Title, &deletedAt, &rec.DeletedBy, &rec.OwnerID, &rec.WithOwner); err != nil {
+			return nil, err
+		}
+		rec.DeletedAt = parseAuditTime(deletedAt)
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+func (r *sqlRepository) RestoreOwner(ctx context.Context, id int) error {
+	return r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{
+			{"owners", "owner_id = @p1", []interface{}{id}},
+			{"cars", "owner_id = @p1", []interface{}{id}},
+		}
+	}, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, r.dialect.rebind(`UPDATE owners
+              SET deleted_at = NULL, deleted_by = NULL, row_version = NEWID()
+              WHERE owner_id = @p1 AND deleted_at IS NOT NULL`), id)
+		if err := checkInRecycleBin(result, err); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, r.dialect.rebind(`UPDATE cars
+              SET deleted_at = NULL, deleted_by = NULL, deleted_with_owner = 0, row_version = NEWID()
+              WHERE owner_id = @p1 AND deleted_with_owner = 1`), id)
+		return err
+	})
+}
+
+func (r *sqlRepository) RestoreCar(ctx context.Context, id int) error {
+	return r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{{"cars", "car_id = @p1", []interface{}{id}}}
+	}, func(tx *sql.Tx) error {
+		var ownerDeleted bool
+		err := tx.QueryRowContext(ctx, r.dialect.rebind(`SELECT CASE WHEN o.deleted_at IS NULL THEN 0 ELSE 1 END
+              FROM cars c
+              JOIN owners o ON c.owner_id = o.owner_id
+              WHERE c.car_id = @p1 AND c.deleted_at IS NOT NULL`), id).Scan(&ownerDeleted)
+		if err == sql.ErrNoRows {
+			return errNotInRecycleBin
+		}
+		if err != nil {
+			return err
+		}
+		if ownerDeleted {
+			return errOwnerDeleted
+		}
+
+		_, err = tx.ExecContext(ctx, r.dialect.rebind(`UPDATE cars
+              SET deleted_at = NULL, deleted_by = NULL, deleted_with_owner = 0, row_version = NEWID()
+              WHERE car_id = @p1`), id)
+		return err
+	})
+}
+
+func (r *sqlRepository) PurgeOwner(ctx context.Context, id int) error {
+	// Автомобили владельца удаляются каскадно (FK_cars_owner ON DELETE CASCADE)
+	return r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{
+			{"cars", "owner_id = @p1", []interface{}{id}},
+			{"owners", "owner_id = @p1", []interface{}{id}},
+		}
+	}, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, r.dialect.rebind("DELETE FROM owners WHERE owner_id = @p1 AND deleted_at IS NOT NULL"), id)
+		return checkInRecycleBin(result, err)
+	})
+}
+
+func (r *sqlRepository) PurgeCar(ctx context.Context, id int) error {
+	return r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{{"cars", "car_id = @p1", []interface{}{id}}}
+	}, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, r.dialect.rebind("DELETE FROM cars WHERE car_id = @p1 AND deleted_at IS NOT NULL"), id)
+		return checkInRecycleBin(result, err)
+	})
+}
+
+// checkInRecycleBin turns a restore or purge that matched nothing into errNotInRecycleBin
+func checkInRecycleBin(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errNotInRecycleBin
+	}
+	return nil
+}
+
+// --- Bulk operations ---
+
+// idList formats the IDs for an IN (...) condition. The IDs are inlined rather
+// than passed as parameters: SQL Server accepts at most 2100 parameters.
+func idList(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (r *sqlRepository) BulkDeleteOwners(ctx context.Context, ids []int) (BulkResult, error) {
+	var result BulkResult
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	list := idList(ids)
+	now := time.Now().Format("2006-01-02 15:04:05")
+	err := r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{
+			{"cars", "owner_id IN (" + list + ")", nil},
+			{"owners", "owner_id IN (" + list + ")", nil},
+		}
+	}, func(tx *sql.Tx) error {
+		cars, err := tx.ExecContext(ctx, r.dialect.rebind(`UPDATE cars
+              SET deleted_at = @p1, deleted_by = @p2, deleted_with_owner = 1, row_version = NEWID()
+              WHERE owner_id IN (`+list+`) AND deleted_at IS NULL`), now, r.user)
+		if err != nil {
+			return err
+		}
+		owners, err := tx.ExecContext(ctx, r.dialect.rebind(`UPDATE owners
+              SET deleted_at = @p1, deleted_by = @p2, row_version = NEWID()
+              WHERE owner_id IN (`+list+`) AND deleted_at IS NULL`), now, r.user)
+		if err != nil {
+			return err
+		}
+		result.Cars, err = rowsAffected(cars)
+		if err == nil {
+			result.Owners, err = rowsAffected(owners)
+		}
+		return err
+	})
+	return result, err
+}
+
+func (r *sqlRepository) BulkDeleteCars(ctx context.Context, ids []int) (BulkResult, error) {
+	var result BulkResult
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	list := idList(ids)
+	now := time.Now().Format("2006-01-02 15:04:05")
+	err := r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{{"cars", "car_id IN (" + list + ")", nil}}
+	}, func(tx *sql.Tx) error {
+		cars, err := tx.ExecContext(ctx, r.dialect.rebind(`UPDATE cars
+              SET deleted_at = @p1, deleted_by = @p2, deleted_with_owner = 0, row_version = NEWID()
+              WHERE car_id IN (`+list+`) AND deleted_at IS NULL`), now, r.user)
+		if err != nil {
+			return err
+		}
+		result.Cars, err = rowsAffected(cars)
+		return err
+	})
+	return result, err
+}
+
+func (r *sqlRepository) BulkUpdateCars(ctx context.Context, ids []int, changes CarChanges) (int, error) {
+	if changes.OwnerID == nil && changes.BrandID == nil && changes.Color == nil {
+		return 0, errNoChanges
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var set []string
+	var args []interface{}
+	param := func(column string, value interface{}) {
+		args = append(args, value)
+		set = append(set, fmt.Sprintf("%s = @p%d", column, len(args)))
+	}
+	if changes.OwnerID != nil {
+		param("owner_id", *changes.OwnerID)
+	}
+	if changes.BrandID != nil {
+		param("brand_id", *changes.BrandID)
+	}
+	if changes.Color != nil {
+		param("color", *changes.Color)
+	}
+
+	list := idList(ids)
+	query := "UPDATE cars SET " + strings.Join(set, ", ") + ", row_version = NEWID()" +
+		" WHERE car_id IN (" + list + ") AND deleted_at IS NULL"
+
+	var updated int
+	err := r.auditedWrite(ctx, func() []auditScope {
+		return []auditScope{{"cars", "car_id IN (" + list + ")", nil}}
+	}, func(tx *sql.Tx) error {
+		// Автомобили нельзя передать владельцу из корзины
+		if changes.OwnerID != nil {
+			var active int
+			err := tx.QueryRowContext(ctx, r.dialect.rebind("SELECT COUNT(*) FROM owners WHERE owner_id = @p1 AND deleted_at IS NULL"),
+				*changes.OwnerID).Scan(&active)
+			if err != nil {
+				return err
+			}
+			if active == 0 {
+				return fmt.Errorf("владелец %d не найден", *changes.OwnerID)
+			}
+
+			// Каждая смена владельца — передача в ownership_history, как в TransferOwnership
+			_, err = tx.ExecContext(ctx, r.dialect.rebind(`INSERT INTO ownership_history
+                  (car_id, from_owner_id, from_owner_name, to_owner_id, to_owner_name, transfer_date, sale_price)
+              SELECT c.car_id, f.owner_id, CONCAT(f.first_name, ' ', f.last_name),
+                     t.owner_id, CONCAT(t.first_name, ' ', t.last_name), @p2, 0
+              FROM cars c
+              JOIN owners t ON t.owner_id = @p1
+              LEFT JOIN owners f ON f.owner_id = c.owner_id
+              WHERE c.car_id IN (`+list+`) AND c.deleted_at IS NULL AND c.owner_id <> @p1`),
+				*changes.OwnerID, time.Now().Format("2006-01-02"))
+			if err != nil {
+				return err
+			}
+		}
+
+		result, err := tx.ExecContext(ctx, r.dialect.rebind(query), args...)
+		if err != nil {
+			return err
+		}
+		updated, err = rowsAffected(result)
+		return err
+	})
+	return updated, err
+}
+
+// rowsAffected returns the RowsAffected of result as int
+func rowsAffected(result sql.Result) (int, error) {
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// --- CSV import ---
+
+func (r *sqlRepository) ImportOwners(ctx context.Context, owners []OwnerImport, commit bool) (map[int]error, error) {
+	query := r.dialect.rebind(r.dialect.returningID(`INSERT INTO owners (first_name, last_name, phone, email, license_category_id)
+              VALUES (@p1, @p2, @p3, @p4, @p5)`, "owner_id"))
+
+	return r.importBatch(ctx, "owners", len(owners), commit, func(tx *sql.Tx, i int) (int, error) {
+		o := owners[i]
+		var id int
+		err := tx.QueryRowContext(ctx, query, o.FirstName, o.LastName, o.Phone, o.Email, o.CategoryID).Scan(&id)
+		return id, err
+	})
+}
+
+func (r *sqlRepository) ImportCars(ctx context.Context, cars []Car, commit bool) (map[int]error, error) {
+	query := r.dialect.rebind(r.dialect.returningID(`INSERT INTO cars (owner_id, brand_id, model, year, color, vin_code, price)
+              VALUES (@p1, @p2, @p3, @p4, @p5, @p6, @p7)`, "car_id"))
+
+	return r.importBatch(ctx, "cars", len(cars), commit, func(tx *sql.Tx, i int) (int, error) {
+		c := cars[i]
+		var id int
+		err := tx.QueryRowContext(ctx, query, c.OwnerID, c.BrandID, c.Model, c.Year, c.Color, c.VIN, c.Price).Scan(&id)
+		return id, err
+	})
+}
+
+// importBatch runs insert for every row inside one transaction and collects the row errors.
+// insert returns the key of the new row of table, which is written to audit_log.
+func (r *sqlRepository) importBatch(ctx context.Context, table string, count int, commit bool, insert func(tx *sql.Tx, i int) (int, error)) (map[int]error, error) {
+	tx, own, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if own {
+		defer tx.Rollback()
+	}
+
+	rowErrors := make(map[int]error)
+	for i := 0; i < count; i++ {
+		id, err := insert(tx, i)
+		if err != nil {
+			rowErrors[i] = err
+			continue
+		}
+
+		after, err := r.snapshot(ctx, tx, table, auditedTables[table].key+" = @p1", id)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.writeAudit(ctx, tx, table, nil, after); err != nil {
+			return nil, err
+		}
+	}
+
+	if !commit || len(rowErrors) > 0 {
+		if !own {
+			// Вставленные строки откатит только вся единица работы
+			return rowErrors, errImportNotCommitted
+		}
+		return rowErrors, nil
+	}
+	if !own {
+		return rowErrors, nil
+	}
+	return rowErrors, tx.Commit()
+}
+
+// --- Application users ---
+
+func (r *sqlRepository) CurrentUser(ctx context.Context) (AppUser, error) {
+	user := AppUser{Name: r.user, Role: roleViewer}
+	err := r.queryRow(ctx, "SELECT role FROM app_users WHERE user_name = @p1", r.user).Scan(&user.Role)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return AppUser{}, err
+	}
+	return user, nil
+}
+
+func (r *sqlRepository) GetAppUsers(ctx context.Context) ([]AppUser, error) {
+	rows, err := r.query(ctx, "SELECT user_name, role FROM app_users ORDER BY user_name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []AppUser
+	for rows.Next() {
+		var u AppUser
+		if err := rows.Scan(&u.Name, &u.Role); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func (r *sqlRepository) SetAppUser(ctx context.Context, name, role string) error {
+	if err := checkAppUser(name, role); err != nil {
+		return err
+	}
+	return r.writeUsers(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, r.dialect.rebind("UPDATE app_users SET role = @p2 WHERE user_name = @p1"), name, role)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil || n > 0 {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, r.dialect.rebind("INSERT INTO app_users (user_name, role) VALUES (@p1, @p2)"), name, role)
+		return err
+	})
+}
+
+func (r *sqlRepository) DeleteAppUser(ctx context.Context, name string) error {
+	return r.writeUsers(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, r.dialect.rebind("DELETE FROM app_users WHERE user_name = @p1"), name)
+		return err
+	})
+}
+
+// writeUsers changes app_users in a transaction that is rolled back if no
+// admin would remain
+func (r *sqlRepository) writeUsers(ctx context.Context, write func(tx *sql.Tx) error) error {
+	tx, own, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	if own {
+		defer tx.Rollback()
+	}
+
+	if err := write(tx); err != nil {
+		return err
+	}
+
+	var admins int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM app_users WHERE role = 'admin'").Scan(&admins)
+	if err != nil {
+		return err
+	}
+	if admins == 0 {
+		return errLastAdmin
+	}
+
+	if own {
+		return tx.Commit()
+	}
+	return nil
+}
+
+// --- Audit log ---
+
+// auditScope selects the rows of an audited table that a write may change
+type auditScope struct {
+	table string
+	where string
+	args  []interface{}
+}
+
+// auditedWrite runs write in a transaction and logs to audit_log how the rows
+// of the scopes changed. scopes is called before and after write, so it may
+// use a key that write assigns (e.g. the ID of an inserted row).
+func (r *sqlRepository) auditedWrite(ctx context.Context, scopes func() []auditScope, write func(tx *sql.Tx) error) error {
+	tx, own, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	if own {
+		defer tx.Rollback()
+	}
+
+	var before []map[int]map[string]string
+	for _, scope := range scopes() {
+		rows, err := r.snapshot(ctx, tx, scope.table, scope.where, scope.args...)
+		if err != nil {
+			return err
+		}
+		before = append(before, rows)
+	}
+
+	if err := write(tx); err != nil {
+		return r.constraintError(err)
+	}
+
+	for i, scope := range scopes() {
+		after, err := r.snapshot(ctx, tx, scope.table, scope.where, scope.args...)
+		if err != nil {
+			return err
+		}
+		if err := r.writeAudit(ctx, tx, scope.table, before[i], after); err != nil {
+			return err
+		}
+	}
+
+	if !own {
+		return nil
+	}
+	return tx.Commit()
+}
+
+// snapshot reads the audited columns of the matching rows, keyed by record ID
+func (r *sqlRepository) snapshot(ctx context.Context, tx *sql.Tx, table, where string, args ...interface{}) (map[int]map[string]string, error) {
+	spec, ok := auditedTables[table]
+	if !ok {
+		return nil, fmt.Errorf("таблица %s не журналируется", table)
+	}
+
+	query := "SELECT " + strings.Join(spec.columns, ", ") + " FROM " + table + " WHERE " + where
+	rows, err := tx.QueryContext(ctx, r.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int]map[string]string)
+	values := make([]interface{}, len(spec.columns))
+	valuePtrs := make([]interface{}, len(spec.columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, err
+		}
+
+		record := make(map[string]string, len(spec.columns))
+		for i, column := range spec.columns {
+			record[column] = auditValue(column, values[i])
+		}
+		id, err := strconv.Atoi(record[spec.key])
+		if err != nil {
+			return nil, err
+		}
+		result[id] = record
+	}
+	return result, rows.Err()
+}
+
+// writeAudit logs one audit_log entry per record that differs between the snapshots
+func (r *sqlRepository) writeAudit(ctx context.Context, tx *sql.Tx, table string, before, after map[int]map[string]string) error {
+	ids := make(map[int]bool, len(before)+len(after))
+	for id := range before {
+		ids[id] = true
+	}
+	for id := range after {
+		ids[id] = true
+	}
+
+	query := r.dialect.rebind(`INSERT INTO audit_log
+                  (changed_at, db_user, table_name, record_id, operation, old_values, new_values)
+              VALUES (@p1, @p2, @p3, @p4, @p5, @p6, @p7)`)
+	now := time.Now().Format("2006-01-02 15:04:05")
+
+	for _, id := range sortedKeys(ids) {
+		operation, ok := auditOperation(before[id], after[id])
+		if !ok {
+			continue
+		}
+		oldValues, err := auditJSON(before[id])
+		if err != nil {
+			return err
+		}
+		newValues, err := auditJSON(after[id])
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, query, now, r.user, table, id, operation, oldValues, newValues); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *sqlRepository) GetAuditLog(ctx context.Context, filter AuditFilter) ([]AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("@p%d", len(args))
+	}
+
+	if filter.Table != "" {
+		conditions = append(conditions, "table_name = "+param(filter.Table))
+	}
+	if filter.Operation != "" {
+		conditions = append(conditions, "operation = "+param(filter.Operation))
+	}
+	if filter.User != "" {
+		conditions = append(conditions, fmt.Sprintf(`db_user LIKE %s ESCAPE '\'`, param("%"+likeEscaper.Replace(filter.User)+"%")))
+	}
+	if filter.RecordID != 0 {
+		conditions = append(conditions, "record_id = "+param(filter.RecordID))
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "changed_at >= "+param(filter.From.Format("2006-01-02")))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "changed_at < "+param(filter.To.AddDate(0, 0, 1).Format("2006-01-02")))
+	}
+
+	query := `SELECT audit_id, changed_at, db_user, table_name, record_id, operation, old_values, new_values
+              FROM audit_log`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY changed_at DESC, audit_id DESC"
+	if filter.Limit > 0 {
+		query = r.dialect.paginate(query, 0, filter.Limit)
+	}
+
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var e AuditEntry
+		var changedAt interface{}
+		var oldValues, newValues sql.NullString
+		if err := rows.Scan(&e.ID, &changedAt, &e.User, &e.Table, &e.RecordID, &e.Operation, &oldValues, &newValues); err != nil {
+			return nil, err
+		}
+		e.Time = parseAuditTime(changedAt)
+		if e.Before, err = parseAuditJSON(oldValues.String); err != nil {
+			return nil, err
+		}
+		if e.After, err = parseAuditJSON(newValues.String); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *sqlRepository) CountTable(ctx context.Context, tableName string, filters []ColumnFilter) (int, error) {
+	table, ok := tableSelects[tableName]
+	if !ok {
+		return 0, fmt.Errorf("неизвестная таблица: %s", tableName)
+	}
+
+	where, args, err := table.where(filters)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = r.queryRow(ctx, "SELECT COUNT(*) FROM "+table.from+where, args...).Scan(&count)
+	return count, err
+}
+
+// --- Global search ---
+
+func (r *sqlRepository) Search(ctx context.Context, text string, limit int) ([]SearchResult, error) {
+	pattern := "%" + likeEscaper.Replace(text) + "%"
+
+	queries := []struct {
+		table string
+		query string
+	}{
+		{"owners", `SELECT owner_id, CONCAT(first_name, ' ', last_name),
+                           CONCAT(COALESCE(phone, ''), ' ', COALESCE(email, ''))
+                    FROM owners
+                    WHERE deleted_at IS NULL
+                      AND (first_name LIKE @p1 ESCAPE '\' OR last_name LIKE @p1 ESCAPE '\'
+                       OR CONCAT(first_name, ' ', last_name) LIKE @p1 ESCAPE '\'
+                       OR phone LIKE @p1 ESCAPE '\' OR email LIKE @p1 ESCAPE '\')`},
+		{"cars", `SELECT c.car_id, CONCAT(b.brand_name, ' ', c.model, ' (', c.year, ')'),
+                         CONCAT('VIN ', COALESCE(c.vin_code, '-'), ', ', COALESCE(c.color, ''), ', ', o.first_name, ' ', o.last_name)
+                  FROM cars c
+                  JOIN owners o ON c.owner_id = o.owner_id
+                  JOIN car_brands b ON c.brand_id = b.brand_id
+                  WHERE c.deleted_at IS NULL
+                    AND (c.model LIKE @p1 ESCAPE '\' OR c.vin_code LIKE @p1 ESCAPE '\' OR c.color LIKE @p1 ESCAPE '\')`},
+		{"car_brands", `SELECT brand_id, brand_name, COALESCE(country_origin, '')
+                        FROM car_brands
+                        WHERE brand_name LIKE @p1 ESCAPE '\'`},
+	}
+
+	var results []SearchResult
+	for _, q := range queries {
+		rows, err := r.query(ctx, r.dialect.paginate(q.query+" ORDER BY 1", 0, limit), pattern)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			result := SearchResult{Table: q.table}
+			if err := rows.Scan(&result.ID, &result.Title, &result.Details); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			results = append(results, result)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// where builds the WHERE clause for column filters with @pN parameters
+func (t tableSelect) where(filters []ColumnFilter) (string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("@p%d", len(args))
+	}
+
+	if t.filter != "" {
+		conditions = append(conditions, t.filter)
+	}
+
+	for _, f := range filters {
+		if f.Column < 0 || f.Column >= len(t.columns) {
+			return "", nil, fmt.Errorf("неверный номер колонки: %d", f.Column)
+		}
+		expr := t.columns[f.Column]
+
+		if f.Contains != "" {
+			conditions = append(conditions, fmt.Sprintf(`%s LIKE %s ESCAPE '\'`, expr, param("%"+likeEscaper.Replace(f.Contains)+"%")))
+		}
+		if f.Min != nil {
+			conditions = append(conditions, fmt.Sprintf("%s >= %s", expr, param(*f.Min)))
+		}
+		if f.Max != nil {
+			conditions = append(conditions, fmt.Sprintf("%s <= %s", expr, param(*f.Max)))
+		}
+		// Даты передаются строкой yyyy-mm-dd: так их сравнивают и DATE в SQL Server, и TEXT в SQLite
+		if f.From != nil {
+			conditions = append(conditions, fmt.Sprintf("%s >= %s", expr, param(f.From.Format("2006-01-02"))))
+		}
+		if f.To != nil {
+			conditions = append(conditions, fmt.Sprintf("%s <= %s", expr, param(f.To.Format("2006-01-02"))))
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "", nil, nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// likeEscaper escapes LIKE wildcards so the filter text is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "[", `\[`)
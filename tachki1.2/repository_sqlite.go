@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+var sqlitePlaceholder = regexp.MustCompile(`@p(\d+)`)
+
+func init() {
+	// T-SQL функции, которые используются в запросах репозитория
+	sqlite.MustRegisterScalarFunction("NEWID", 0, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		return newRowVersion(), nil
+	})
+
+	// Имя пользователя, как у SQL Server: миграция назначает его администратором
+	sqlite.MustRegisterScalarFunction("SUSER_SNAME", 0, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		return osUserName(), nil
+	})
+
+	sqlite.MustRegisterScalarFunction("fn_GetCarDepreciatedValue", 2, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		if args[0] == nil || args[1] == nil {
+			return nil, nil
+		}
+		price, err := sqliteFloat(args[0])
+		if err != nil {
+			return nil, err
+		}
+		year, err := sqliteFloat(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return depreciatedValue(price, int(year)), nil
+	})
+}
+
+// sqliteFloat converts a numeric SQLite value passed to a user function
+func sqliteFloat(v driver.Value) (float64, error) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	default:
+		return 0, fmt.Errorf("ожидалось число, получено %T", v)
+	}
+}
+
+// sqliteDialect turns @pN into ?N and drops the dbo. schema prefix
+var sqliteDialect = &sqlDialect{
+	rebind: func(query string) string {
+		query = sqlitePlaceholder.ReplaceAllString(query, "?$1")
+		return strings.ReplaceAll(query, "dbo.", "")
+	},
+	massPriceUpdate: func(ctx context.Context, tx *sql.Tx, brandID int, percentage float64) (sql.Result, error) {
+		// Тело sp_MassPriceUpdate; транзакцию открывает репозиторий.
+		// Цены автомобилей в корзине не индексируются
+		return tx.ExecContext(ctx, `UPDATE cars
+			SET price = price * (1.0 + ?2 / 100.0),
+			    row_version = NEWID()
+			WHERE brand_id = ?1 AND deleted_at IS NULL`, brandID, percentage)
+	},
+	migrationsDir: "sqlite",
+	schemaVersionDDL: `CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL DEFAULT (datetime('now'))
+	)`,
+	returningID: func(query, column string) string {
+		return query + " RETURNING " + column
+	},
+	paginate: func(query string, offset, limit int) string {
+		return query + fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	},
+	constraint: func(err error) error {
+		var e *sqlite.Error
+		if !errors.As(err, &e) {
+			return nil
+		}
+		switch e.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return errDuplicate
+		case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+			return errInvalidReference
+		}
+		return nil
+	},
+}
+
+// openSQLite opens (or creates) a database file.
+// The schema is created by the migrations in migrations/sqlite.
+func openSQLite(path string) (*sqlRepository, error) {
+	if path == "" {
+		return nil, fmt.Errorf("не указан файл базы данных")
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	// У SQLite нет логинов — в audit_log пишется пользователь ОС
+	repo := newSQLRepository(db, sqliteDialect)
+	repo.user = osUserName()
+	return repo, nil
+}
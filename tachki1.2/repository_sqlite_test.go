@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+// openEmptySQLite returns an in-memory SQLite database without a schema
+func openEmptySQLite(t *testing.T) *sqlRepository {
+	t.Helper()
+
+	repo, err := openSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// У каждого подключения к :memory: своя база — пул из одного подключения
+	repo.db.SetMaxOpenConns(1)
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+// openTestSQLite returns an in-memory SQLite database with every migration applied
+func openTestSQLite(t *testing.T) *sqlRepository {
+	t.Helper()
+
+	repo := openEmptySQLite(t)
+	pending, err := repo.PendingMigrations(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.ApplyMigrations(context.Background(), pending); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestSQLiteRebind(t *testing.T) {
+	tests := []struct {
+		query, want string
+	}{
+		{"SELECT 1", "SELECT 1"},
+		{"SELECT * FROM owners WHERE owner_id = @p1", "SELECT * FROM owners WHERE owner_id = ?1"},
+		{"UPDATE cars SET price = @p2 WHERE brand_id = @p1 AND car_id = @p12", "UPDATE cars SET price = ?2 WHERE brand_id = ?1 AND car_id = ?12"},
+		{"SELECT dbo.fn_GetCarDepreciatedValue(price, year) FROM cars", "SELECT fn_GetCarDepreciatedValue(price, year) FROM cars"},
+	}
+	for _, tt := range tests {
+		if got := sqliteDialect.rebind(tt.query); got != tt.want {
+			t.Errorf("rebind(%q) = %q, ожидалось %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSQLiteReturningID(t *testing.T) {
+	query := "INSERT INTO owners (first_name) VALUES (@p1)"
+	want := "INSERT INTO owners (first_name) VALUES (@p1) RETURNING owner_id"
+	if got := sqliteDialect.returningID(query, "owner_id"); got != want {
+		t.Errorf("returningID = %q, ожидалось %q", got, want)
+	}
+}
+
+func TestSQLitePaginate(t *testing.T) {
+	query := "SELECT owner_id FROM owners ORDER BY 1"
+	want := query + " LIMIT 50 OFFSET 100"
+	if got := sqliteDialect.paginate(query, 100, 50); got != want {
+		t.Errorf("paginate = %q, ожидалось %q", got, want)
+	}
+
+	// Страницы по 2 строки выбирают строки таблицы по порядку
+	repo := openTestSQLite(t)
+	ctx := context.Background()
+	for _, name := range []string{"Анна", "Борис", "Вера"} {
+		if _, err := repo.AddOwner(ctx, name, "Иванова", "", "", 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for offset, wantRows := range map[int]int{0: 2, 2: 1, 4: 0} {
+		rows, err := repo.QueryTable(ctx, "owners", TableQuery{Offset: offset, Limit: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != wantRows {
+			t.Errorf("страница с %d: %d строк, ожидалось %d", offset, len(rows), wantRows)
+		}
+	}
+}
+
+// TestSQLiteCRUD adds, reads, changes and deletes an owner with a car
+func TestSQLiteCRUD(t *testing.T) {
+	repo := openTestSQLite(t)
+	ctx := context.Background()
+
+	ownerID, err := repo.AddOwner(ctx, "Иван", "Петров", "+7 900 000-00-00", "ivan@example.com", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, err := repo.GetOwnerByID(ctx, ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner.LastName != "Петров" || owner.Category != "B" || len(owner.RowVersion) == 0 {
+		t.Fatalf("владелец прочитан неверно: %+v", owner)
+	}
+
+	carID, err := repo.AddCar(ctx, ownerID, 1, "Camry", 2020, "Чёрный", "JT2BF22K1W0123456", 25000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	car, err := repo.GetCarByID(ctx, carID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if car.Model != "Camry" || car.CurrentPrice != depreciatedValue(25000, 2020) {
+		t.Fatalf("автомобиль прочитан неверно: %+v", car)
+	}
+
+	if _, err := repo.AddCar(ctx, ownerID, 1, "Corolla", 2021, "", "JT2BF22K1W0123456", 20000); err == nil {
+		t.Error("повторный VIN принят")
+	}
+
+	if err := repo.UpdateCar(ctx, carID, ownerID, 1, "Camry", 2020, "Белый", car.VIN, 24000, car.RowVersion); err != nil {
+		t.Fatal(err)
+	}
+	err = repo.UpdateCar(ctx, carID, ownerID, 1, "Camry", 2020, "Красный", car.VIN, 24000, car.RowVersion)
+	if !errors.Is(err, errConcurrentUpdate) {
+		t.Errorf("изменение по старой row_version: %v, ожидалось errConcurrentUpdate", err)
+	}
+
+	// Владелец меняется только передачей
+	otherID, err := repo.AddOwner(ctx, "Анна", "Смирнова", "", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	car, err = repo.GetCarByID(ctx, carID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.UpdateCar(ctx, carID, otherID, 1, "Camry", 2020, "Белый", car.VIN, 24000, car.RowVersion)
+	if !errors.Is(err, errOwnerChange) {
+		t.Errorf("смена владельца через UpdateCar: %v, ожидалось errOwnerChange", err)
+	}
+
+	cars, err := repo.GetCarsByOwner(ctx, ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cars) != 1 || cars[0].Color != "Белый" {
+		t.Fatalf("автомобили владельца: %+v", cars)
+	}
+
+	if err := repo.DeleteCar(ctx, carID, owner.RowVersion); !errors.Is(err, errConcurrentUpdate) {
+		t.Errorf("удаление по чужой row_version: %v, ожидалось errConcurrentUpdate", err)
+	}
+	if err := repo.DeleteOwner(ctx, ownerID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteOwner(ctx, ownerID, nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("повторное удаление владельца: %v, ожидалось sql.ErrNoRows", err)
+	}
+	if err := repo.DeleteCar(ctx, 999, nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("удаление несуществующего автомобиля: %v, ожидалось sql.ErrNoRows", err)
+	}
+	if _, err := repo.GetOwnerByID(ctx, ownerID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("удалённый владелец найден: %v", err)
+	}
+	if _, err := repo.GetCarByID(ctx, carID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("автомобиль удалённого владельца найден: %v", err)
+	}
+
+	deleted, err := repo.GetDeleted(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 2 {
+		t.Fatalf("в корзине %d записей, ожидалось 2", len(deleted))
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testRepositories returns the memory repository and an in-memory SQLite
+// database, for rules both must follow
+func testRepositories(t *testing.T) map[string]Repository {
+	return map[string]Repository{"память": newMemoryRepository(), "SQLite": openTestSQLite(t)}
+}
+
+// TestBulkUpdateCarsOwnerHistory checks that a bulk owner change is recorded
+// as a transfer of every car that changes hands
+func TestBulkUpdateCarsOwnerHistory(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		ctx := context.Background()
+		fromID, err := repo.AddOwner(ctx, "Иван", "Петров", "", "", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		toID, err := repo.AddOwner(ctx, "Анна", "Смирнова", "", "", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		soldID, err := repo.AddCar(ctx, fromID, 1, "Camry", 2020, "", "JT2BF22K1W0123456", 25000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keptID, err := repo.AddCar(ctx, toID, 1, "Corolla", 2021, "", "JT2BF22K1W0654321", 20000)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		updated, err := repo.BulkUpdateCars(ctx, []int{soldID, keptID}, CarChanges{OwnerID: &toID})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if updated != 2 {
+			t.Errorf("%s: изменено %d автомобилей, ожидалось 2", name, updated)
+		}
+
+		history, err := repo.GetOwnershipHistory(ctx, soldID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != 1 || history[0].FromOwnerID != fromID || history[0].ToOwnerID != toID || history[0].ToOwner != "Анна Смирнова" {
+			t.Errorf("%s: история переданного автомобиля: %+v", name, history)
+		}
+
+		// Автомобиль, уже принадлежавший владельцу, не передавался
+		history, err = repo.GetOwnershipHistory(ctx, keptID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != 0 {
+			t.Errorf("%s: история автомобиля без передачи: %+v", name, history)
+		}
+	}
+}
+
+// countOwners returns the number of active owners
+func countOwners(t *testing.T, repo Repository) int {
+	t.Helper()
+	owners, err := repo.GetOwners(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(owners)
+}
+
+// TestInTransactionRollback checks that an error of fn undoes every write of
+// the unit, including a mass price update
+func TestInTransactionRollback(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		ctx := context.Background()
+		ownerID, err := repo.AddOwner(ctx, "Иван", "Петров", "", "", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		carID, err := repo.AddCar(ctx, ownerID, 1, "Camry", 2020, "", "JT2BF22K1W0123456", 25000)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		failure := errors.New("отмена")
+		err = repo.InTransaction(ctx, func(unit Repository) error {
+			if _, err := unit.AddOwner(ctx, "Анна", "Смирнова", "", "", 1); err != nil {
+				return err
+			}
+			if err := unit.MassPriceUpdate(ctx, 1, 10); err != nil {
+				return err
+			}
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Fatalf("%s: InTransaction вернул %v", name, err)
+		}
+
+		if n := countOwners(t, repo); n != 1 {
+			t.Errorf("%s: после отката владельцев %d, ожидался 1", name, n)
+		}
+		car, err := repo.GetCarByID(ctx, carID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if car.Price != 25000 {
+			t.Errorf("%s: после отката цена %.0f, ожидалась 25000", name, car.Price)
+		}
+	}
+}
+
+func TestInTransactionPanic(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		ctx := context.Background()
+
+		func() {
+			defer func() {
+				if p := recover(); p != "сбой" {
+					t.Errorf("%s: паника %v не передана вызывающему", name, p)
+				}
+			}()
+			repo.InTransaction(ctx, func(unit Repository) error {
+				if _, err := unit.AddOwner(ctx, "Анна", "Смирнова", "", "", 1); err != nil {
+					return err
+				}
+				panic("сбой")
+			})
+		}()
+
+		if n := countOwners(t, repo); n != 0 {
+			t.Errorf("%s: после паники владельцев %d, ожидалось 0", name, n)
+		}
+		// Подключение свободно: транзакция закрыта
+		if _, err := repo.AddOwner(ctx, "Иван", "Петров", "", "", 1); err != nil {
+			t.Errorf("%s: запись после паники: %v", name, err)
+		}
+	}
+}
+
+// TestInTransactionNested checks that a nested call joins the outer unit:
+// its writes are committed or rolled back with the outer ones
+func TestInTransactionNested(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		ctx := context.Background()
+
+		nested := func(unit Repository) error {
+			return unit.InTransaction(ctx, func(inner Repository) error {
+				_, err := inner.AddOwner(ctx, "Анна", "Смирнова", "", "", 1)
+				return err
+			})
+		}
+
+		failure := errors.New("отмена")
+		err := repo.InTransaction(ctx, func(unit Repository) error {
+			if err := nested(unit); err != nil {
+				return err
+			}
+			// Вложенная запись видна внешней единице работы до фиксации
+			owners, err := unit.GetOwners(ctx)
+			if err != nil {
+				return err
+			}
+			if len(owners) != 1 {
+				t.Errorf("%s: внутри единицы работы владельцев %d, ожидался 1", name, len(owners))
+			}
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Fatalf("%s: InTransaction вернул %v", name, err)
+		}
+		if n := countOwners(t, repo); n != 0 {
+			t.Errorf("%s: вложенная запись пережила откат внешней: владельцев %d", name, n)
+		}
+
+		if err := repo.InTransaction(ctx, nested); err != nil {
+			t.Fatal(err)
+		}
+		if n := countOwners(t, repo); n != 1 {
+			t.Errorf("%s: после фиксации владельцев %d, ожидался 1", name, n)
+		}
+	}
+}
+
+// TestInTransactionConcurrent runs two unrelated units at once: the rollback
+// of one must not undo the writes of the other
+func TestInTransactionConcurrent(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		ctx := context.Background()
+		failure := errors.New("отмена")
+		wrote, release := make(chan struct{}), make(chan struct{})
+
+		first := make(chan error, 1)
+		go func() {
+			first <- repo.InTransaction(ctx, func(unit Repository) error {
+				if _, err := unit.AddOwner(ctx, "Иван", "Петров", "", "", 1); err != nil {
+					return err
+				}
+				close(wrote)
+				<-release
+				return failure
+			})
+		}()
+
+		<-wrote
+		second := make(chan error, 1)
+		go func() {
+			second <- repo.InTransaction(ctx, func(unit Repository) error {
+				_, err := unit.AddOwner(ctx, "Анна", "Смирнова", "", "", 1)
+				return err
+			})
+		}()
+		time.Sleep(50 * time.Millisecond) // вторая единица работы ждёт первую
+		close(release)
+
+		if err := <-first; !errors.Is(err, failure) {
+			t.Fatalf("%s: первая единица работы: %v", name, err)
+		}
+		if err := <-second; err != nil {
+			t.Fatalf("%s: вторая единица работы: %v", name, err)
+		}
+		owners, err := repo.GetOwners(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(owners) != 1 || owners[0].LastName != "Смирнова" {
+			t.Errorf("%s: после отката первой единицы работы владельцы %+v", name, owners)
+		}
+	}
+}
+
+// TestQueryTableSortColumn checks that a sort column outside the table is an
+// error on every backend
+func TestQueryTableSortColumn(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		ctx := context.Background()
+		if _, err := repo.AddOwner(ctx, "Иван", "Петров", "", "", 1); err != nil {
+			t.Fatal(err)
+		}
+		columns := len(tableColumnNames("owners"))
+
+		for _, col := range []int{-1, columns} {
+			if _, err := repo.QueryTable(ctx, "owners", TableQuery{Sort: &TableSort{Column: col}}); err == nil {
+				t.Errorf("%s: сортировка по колонке %d выполнена", name, col)
+			}
+		}
+		rows, err := repo.QueryTable(ctx, "owners", TableQuery{Sort: &TableSort{Column: columns - 1, Desc: true}})
+		if err != nil || len(rows) != 1 {
+			t.Errorf("%s: сортировка по последней колонке: %d строк, %v", name, len(rows), err)
+		}
+	}
+}
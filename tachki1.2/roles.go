@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Roles of the application users, from the least to the most privileged.
+// Each role has the rights of the previous ones.
+const (
+	roleViewer   = "viewer"   // просмотр, поиск, выгрузка и журнал
+	roleOperator = "operator" // добавление и изменение владельцев и автомобилей, импорт
+	roleAdmin    = "admin"    // удаление и корзина, переоценка, логотипы, пользователи
+)
+
+// roles lists the roles in the order of privilege
+var roles = []string{roleViewer, roleOperator, roleAdmin}
+
+// roleTitles are the role names shown in the UI
+var roleTitles = map[string]string{
+	roleViewer:   "Наблюдатель",
+	roleOperator: "Оператор",
+	roleAdmin:    "Администратор",
+}
+
+func roleRank(role string) int {
+	for i, r := range roles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
+
+// roleAllows reports whether role has the rights of required
+func roleAllows(role, required string) bool {
+	rank := roleRank(role)
+	return rank >= 0 && rank >= roleRank(required)
+}
+
+// checkAppUser validates a user passed to SetAppUser
+func checkAppUser(name, role string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("не задано имя пользователя")
+	}
+	if roleRank(role) < 0 {
+		return fmt.Errorf("неизвестная роль: %s", role)
+	}
+	return nil
+}
+
+// roleRepository enforces the role of the user in the data layer, so that
+// every caller — tabs, dialogs, CLI and API — is checked the same way.
+//
+// The check is done by the application only: the database itself lets any
+// login with write access change the data, and code holding the unwrapped
+// repository is not restricted. The only such path is the schema check before
+// signIn, and ApplyMigrations checks the role itself. Real protection against
+// other clients needs database permissions granted per login.
+type roleRepository struct {
+	Repository
+	role string
+}
+
+// withRole restricts repo to the operations allowed to role
+func withRole(repo Repository, role string) Repository {
+	if r, ok := repo.(*roleRepository); ok {
+		repo = r.Repository
+	}
+	return &roleRepository{Repository: repo, role: role}
+}
+
+// require returns errPermissionDenied unless the role has the rights of required
+func (r *roleRepository) require(required string) error {
+	if roleAllows(r.role, required) {
+		return nil
+	}
+	return fmt.Errorf("%w: требуется роль «%s»", errPermissionDenied, roleTitles[required])
+}
+
+func (r *roleRepository) InTransaction(ctx context.Context, fn func(repo Repository) error) error {
+	return r.Repository.InTransaction(ctx, func(unit Repository) error {
+		return fn(&roleRepository{Repository: unit, role: r.role})
+	})
+}
+
+// --- Operator ---
+
+func (r *roleRepository) AddOwner(ctx context.Context, firstName, lastName, phone, email string, categoryID int) (int, error) {
+	if err := r.require(roleOperator); err != nil {
+		return 0, err
+	}
+	return r.Repository.AddOwner(ctx, firstName, lastName, phone, email, categoryID)
+}
+
+func (r *roleRepository) UpdateOwner(ctx context.Context, id int, firstName, lastName, phone, email string, categoryID int, rowVersion []byte) error {
+	if err := r.require(roleOperator); err != nil {
+		return err
+	}
+	return r.Repository.UpdateOwner(ctx, id, firstName, lastName, phone, email, categoryID, rowVersion)
+}
+
+func (r *roleRepository) AddCar(ctx context.Context, ownerID, brandID int, model string, year int, color, vin string, price float64) (int, error) {
+	if err := r.require(roleOperator); err != nil {
+		return 0, err
+	}
+	return r.Repository.AddCar(ctx, ownerID, brandID, model, year, color, vin, price)
+}
+
+func (r *roleRepository) UpdateCar(ctx context.Context, id int, ownerID, brandID int, model string, year int, color, vin string, price float64, rowVersion []byte) error {
+	if err := r.require(roleOperator); err != nil {
+		return err
+	}
+	return r.Repository.UpdateCar(ctx, id, ownerID, brandID, model, year, color, vin, price, rowVersion)
+}
+
+func (r *roleRepository) TransferOwnership(ctx context.Context, carID, toOwnerID int, date time.Time, salePrice float64, rowVersion []byte) error {
+	if err := r.require(roleOperator); err != nil {
+		return err
+	}
+	return r.Repository.TransferOwnership(ctx, carID, toOwnerID, date, salePrice, rowVersion)
+}
+
+func (r *roleRepository) ImportOwners(ctx context.Context, owners []OwnerImport, commit bool) (map[int]error, error) {
+	if err := r.require(roleOperator); err != nil {
+		return nil, err
+	}
+	return r.Repository.ImportOwners(ctx, owners, commit)
+}
+
+func (r *roleRepository) ImportCars(ctx context.Context, cars []Car, commit bool) (map[int]error, error) {
+	if err := r.require(roleOperator); err != nil {
+		return nil, err
+	}
+	return r.Repository.ImportCars(ctx, cars, commit)
+}
+
+func (r *roleRepository) BulkUpdateCars(ctx context.Context, ids []int, changes CarChanges) (int, error) {
+	if err := r.require(roleOperator); err != nil {
+		return 0, err
+	}
+	return r.Repository.BulkUpdateCars(ctx, ids, changes)
+}
+
+// --- Admin ---
+
+func (r *roleRepository) DeleteOwner(ctx context.Context, id int, rowVersion []byte) error {
+	if err := r.require(roleAdmin); err != nil {
+		return err
+	}
+	return r.Repository.DeleteOwner(ctx, id, rowVersion)
+}
+
+func (r *roleRepository) DeleteCar(ctx context.Context, id int, rowVersion []byte) error {
+	if err := r.require(roleAdmin); err != nil {
+		return err
+	}
+	return r.Repository.DeleteCar(ctx, id, rowVersion)
+}
+
+func (r *roleRepository) MassPriceUpdate(ctx context.Context, brandID int, percentage float64) error {
+	if err := r.require(roleAdmin); err != nil {
+		return err
+	}
+	return r.Repository.MassPriceUpdate(ctx, brandID, percentage)
+}
+
+func (r *roleRepository) UpdateBrandImage(ctx context.Context, brandID int, imageData []byte, rowVersion []byte) error {
+	if err := r.require(roleAdmin); err != nil {
+		return err
+	}
+	return r.Repository.UpdateBrandImage(ctx, brandID, imageData, rowVersion)
+}
+
+func (r *roleRepository) RestoreOwner(ctx context.Context, id int) error {
+	if err := r.require(roleAdmin); err != nil {
+		return err
+	}
+	return r.Repository.RestoreOwner(ctx, id)
+}
+
+func (r *roleRepository) RestoreCar(ctx context.Context, id int) error {
+	if err := r.require(roleAdmin); err != nil {
+		return err
+	}
+	return r.Repository.RestoreCar(ctx, id)
+}
+
+func (r *roleRepository) PurgeOwner(ctx context.Context, id int) error {
+	if err := r.require(roleAdmin); err != nil {
+		return err
+	}
+	return r.Repository.PurgeOwner(ctx, id)
+}
+
+func (r *roleRepository) PurgeCar(ctx context.Context, id int) error {
+	if err := r.require(roleAdmin); err != nil {
+		return err
+	}
+	return r.Repository.PurgeCar(ctx, id)
+}
+
+func (r *roleRepository) BulkDeleteOwners(ctx context.Context, ids []int) (BulkResult, error) {
+	if err := r.require(roleAdmin); err != nil {
+		return BulkResult{}, err
+	}
+	return r.Repository.BulkDeleteOwners(ctx, ids)
+}
+
+func (r *roleRepository) BulkDeleteCars(ctx context.Context, ids []int) (BulkResult, error) {
+	if err := r.require(roleAdmin); err != nil {
+		return BulkResult{}, err
+	}
+	return r.Repository.BulkDeleteCars(ctx, ids)
+}
+
+func (r *roleRepository) GetAppUsers(ctx context.Context) ([]AppUser, error) {
+	if err := r.require(roleAdmin); err != nil {
+		return nil, err
+	}
+	return r.Repository.GetAppUsers(ctx)
+}
+
+func (r *roleRepository) SetAppUser(ctx context.Context, name, role string) error {
+	if err := r.require(roleAdmin); err != nil {
+		return err
+	}
+	return r.Repository.SetAppUser(ctx, name, role)
+}
+
+func (r *roleRepository) DeleteAppUser(ctx context.Context, name string) error {
+	if err := r.require(roleAdmin); err != nil {
+		return err
+	}
+	return r.Repository.DeleteAppUser(ctx, name)
+}
+
+// signIn looks up the role of the connected user and restricts the repository to it.
+// It runs after the migrations: app_users is created by one of them.
+func (d *DatabaseApp) signIn(ctx context.Context) error {
+	repo := d.repository()
+	user, err := repo.CurrentUser(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось определить роль пользователя: %w", err)
+	}
+	d.repoMu.Lock()
+	d.user = user
+	d.repo = withRole(repo, user.Role)
+	d.repoMu.Unlock()
+	return nil
+}
+
+// can reports whether the signed-in user has the rights of role
+func (d *DatabaseApp) can(role string) bool {
+	_, _, user := d.connection()
+	return roleAllows(user.Role, role)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// searchLimit is the maximum number of results per table
+const searchLimit = 50
+
+// createSearchBar returns the global search field shown above the tabs
+func (d *DatabaseApp) createSearchBar() fyne.CanvasObject {
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Поиск: имя, телефон, email, модель, VIN, цвет, марка")
+
+	searchBtn := widget.NewButtonWithIcon("Найти", theme.SearchIcon(), nil)
+
+	search := func() {
+		text := strings.TrimSpace(searchEntry.Text)
+		if len([]rune(text)) < 2 {
+			d.showMessage("Поиск", "Введите не менее 2 символов")
+			return
+		}
+		d.showSearchResults(text, searchBtn, searchEntry)
+	}
+	searchEntry.OnSubmitted = func(string) { search() }
+	searchBtn.OnTapped = search
+	return container.NewBorder(nil, nil, nil, searchBtn, searchEntry)
+}
+
+// selectTab switches to the tab with the given title
+func (d *DatabaseApp) selectTab(title string) {
+	if d.tabs == nil {
+		return
+	}
+	for _, item := range d.tabs.Items {
+		if item.Text == title {
+			d.tabs.Select(item)
+			return
+		}
+	}
+}
+
+// showSearchResults searches in the background and lists the found records
+// grouped by table
+func (d *DatabaseApp) showSearchResults(text string, busy ...fyne.Disableable) {
+	var results []SearchResult
+	d.runInBackground("Поиск", func(ctx context.Context) error {
+		var err error
+		results, err = d.repository().Search(ctx, text, searchLimit)
+		return err
+	}, func(err error) {
+		if err != nil {
+			d.showMessage("Ошибка", fmt.Sprintf("Ошибка поиска: %v", err))
+			return
+		}
+		d.showSearchResultsDialog(text, results)
+	}, busy...)
+}
+
+// showSearchResultsDialog lists the found records grouped by table
+func (d *DatabaseApp) showSearchResultsDialog(text string, results []SearchResult) {
+
+	groups := []struct {
+		table string
+		title string
+	}{
+		{"owners", "Владельцы"},
+		{"cars", "Автомобили"},
+		{"car_brands", "Марки автомобилей"},
+	}
+
+	content := container.NewVBox()
+	var resultsDialog dialog.Dialog
+
+	for _, group := range groups {
+		var items []SearchResult
+		for _, result := range results {
+			if result.Table == group.table {
+				items = append(items, result)
+			}
+		}
+
+		header := fmt.Sprintf("%s: %d", group.title, len(items))
+		if len(items) == searchLimit {
+			header += " (показаны первые)"
+		}
+		content.Add(widget.NewLabelWithStyle(header, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+
+		for _, item := range items {
+			content.Add(d.searchResultRow(item, func() { resultsDialog.Hide() }))
+		}
+		content.Add(widget.NewSeparator())
+	}
+
+	if len(results) == 0 {
+		content.Add(widget.NewLabel(fmt.Sprintf("По запросу «%s» ничего не найдено", text)))
+	}
+
+	scroll := container.NewVScroll(content)
+	resultsDialog = dialog.NewCustom(fmt.Sprintf("Результаты поиска: «%s»", text), "Закрыть", scroll, d.window)
+	resultsDialog.Resize(fyne.NewSize(750, 500))
+	resultsDialog.Show()
+}
+
+// searchResultRow shows one result with jump actions; closeResults hides the results dialog
+func (d *DatabaseApp) searchResultRow(item SearchResult, closeResults func()) fyne.CanvasObject {
+	label := widget.NewLabel(fmt.Sprintf("#%d  %s — %s", item.ID, item.Title, strings.TrimSpace(item.Details)))
+	label.Truncation = fyne.TextTruncateEllipsis
+
+	var actions []fyne.CanvasObject
+	switch item.Table {
+	case "owners", "cars":
+		editBtn := widget.NewButtonWithIcon("Изменить", theme.DocumentCreateIcon(), func() {
+			closeResults()
+			d.selectTab("✏️ Редактирование")
+			if d.openEditForm != nil {
+				d.openEditForm(item.Table, item.ID)
+			}
+		})
+
+		description := item.Title
+		if item.Table == "cars" {
+			description = "автомобиль " + item.Title
+		}
+		deleteBtn := widget.NewButtonWithIcon("Удалить", theme.DeleteIcon(), func() {
+			closeResults()
+			d.confirmDelete(item.Table, item.ID, description, nil)
+		})
+		deleteBtn.Importance = widget.DangerImportance
+		// Кнопки показываются только при правах роли пользователя
+		if d.can(roleOperator) {
+			actions = append(actions, editBtn)
+		}
+		if d.can(roleAdmin) {
+			actions = append(actions, deleteBtn)
+		}
+
+		if item.Table == "owners" {
+			cardBtn := widget.NewButtonWithIcon("Карточка", theme.AccountIcon(), func() {
+				closeResults()
+				d.showOwnerCard(item.ID)
+			})
+			actions = append([]fyne.CanvasObject{cardBtn}, actions...)
+		}
+
+	case "car_brands":
+		logoBtn := widget.NewButtonWithIcon("Логотип", theme.ColorPaletteIcon(), func() {
+			closeResults()
+			d.selectTab("🎨 Логотипы")
+			if d.openBrandLogo != nil {
+				d.openBrandLogo(item.Title)
+			}
+		})
+		if d.can(roleAdmin) {
+			actions = append(actions, logoBtn)
+		}
+	}
+
+	return container.NewBorder(nil, nil, nil, container.NewHBox(append([]fyne.CanvasObject{layout.NewSpacer()}, actions...)...), label)
+}
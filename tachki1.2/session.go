@@ -0,0 +1,369 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// idleTimeoutKey stores the idle time before the session locks (minutes, 0 — never)
+const idleTimeoutKey = "session.idleTimeout"
+
+// defaultIdleTimeout locks the window of a forgotten session on a shared PC
+const defaultIdleTimeout = 15 * time.Minute
+
+// idlePollInterval is how often the idle watch looks at the focused widget
+const idlePollInterval = 5 * time.Second
+
+// idleTimeout returns the configured idle time before the session locks; 0 — never
+func (d *DatabaseApp) idleTimeout() time.Duration {
+	minutes := d.app.Preferences().IntWithFallback(idleTimeoutKey, int(defaultIdleTimeout/time.Minute))
+	return time.Duration(minutes) * time.Minute
+}
+
+// idleWatch locks the session after a period without user activity.
+// Fyne 2.4 does not report input to the application as a whole, so activity
+// is what the app can observe: database operations, tab switches, keys
+// typed outside of widgets and changes of the focused widget or its text,
+// which is polled.
+type idleWatch struct {
+	d       *DatabaseApp
+	timeout time.Duration
+	stop    chan struct{}
+
+	mu         sync.Mutex
+	lastActive time.Time
+	focus      string // состояние виджета с фокусом при последней проверке
+}
+
+func newIdleWatch(d *DatabaseApp, timeout time.Duration) *idleWatch {
+	return &idleWatch{
+		d:          d,
+		timeout:    timeout,
+		stop:       make(chan struct{}),
+		lastActive: time.Now(),
+	}
+}
+
+// Start begins watching; a zero timeout never locks
+func (w *idleWatch) Start() {
+	if w.timeout <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(idlePollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.d.onUI(w.check)
+			}
+		}
+	}()
+}
+
+func (w *idleWatch) Stop() {
+	close(w.stop)
+}
+
+// Touch records user activity
+func (w *idleWatch) Touch() {
+	w.mu.Lock()
+	w.lastActive = time.Now()
+	w.mu.Unlock()
+}
+
+// check locks the session when the user was idle for the timeout
+func (w *idleWatch) check() {
+	if w.d.lockedState() != nil || w.timeout <= 0 {
+		return
+	}
+
+	focus := focusState(w.d.window.Canvas())
+	w.mu.Lock()
+	if focus != w.focus {
+		w.focus = focus
+		w.lastActive = time.Now()
+	}
+	idle := time.Since(w.lastActive)
+	w.mu.Unlock()
+
+	if idle >= w.timeout {
+		w.d.lockSession()
+	}
+}
+
+// focusState describes the focused widget; typing into an entry changes it
+func focusState(c fyne.Canvas) string {
+	switch f := c.Focused().(type) {
+	case nil:
+		return ""
+	case *widget.Entry:
+		return fmt.Sprintf("%p %d %d:%d", f, len(f.Text), f.CursorRow, f.CursorColumn)
+	default:
+		return fmt.Sprintf("%p", f)
+	}
+}
+
+// touchSession records user activity for the idle lock
+func (d *DatabaseApp) touchSession() {
+	if d.idle != nil {
+		d.idle.Touch()
+	}
+}
+
+// startIdleWatch starts the idle lock of the main window and the Ctrl+L
+// shortcut to lock it by hand
+func (d *DatabaseApp) startIdleWatch() {
+	if d.idle != nil {
+		d.idle.Stop()
+	}
+	d.idle = newIdleWatch(d, d.idleTimeout())
+	d.idle.Start()
+
+	c := d.window.Canvas()
+	c.SetOnTypedKey(func(*fyne.KeyEvent) { d.touchSession() })
+	c.SetOnTypedRune(func(rune) { d.touchSession() })
+	c.AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyL, Modifier: fyne.KeyModifierShortcutDefault},
+		func(fyne.Shortcut) { d.lockSession() })
+}
+
+// lockedSession keeps what the lock screen hides
+type lockedSession struct {
+	content  fyne.CanvasObject
+	overlays []fyne.CanvasObject // открытые диалоги
+	// Строка подключения без пароля; пустая — пароля нет, и сеанс
+	// возобновляется только новым входом
+	connStr string
+}
+
+// errNoSessionPassword is returned when unlocking a connection without a password
+var errNoSessionPassword = errors.New("у подключения нет пароля: войдите заново")
+
+// withoutPassword returns the SQL Server connection string without its
+// password; "" when the connection has no password to ask for
+func withoutPassword(backend, connStr string) string {
+	if backend != backendMSSQL {
+		return ""
+	}
+	u, err := url.Parse(connStr)
+	if err != nil || u.User == nil {
+		return ""
+	}
+	if _, ok := u.User.Password(); !ok {
+		return ""
+	}
+	u.User = url.User(u.User.Username())
+	return u.String()
+}
+
+// withPassword puts password into a connection string of withoutPassword
+func withPassword(connStr, password string) string {
+	u, err := url.Parse(connStr)
+	if err != nil || u.User == nil {
+		return connStr
+	}
+	u.User = url.UserPassword(u.User.Username(), password)
+	return u.String()
+}
+
+// lockedState returns what the lock screen hides; nil — the session is not locked
+func (d *DatabaseApp) lockedState() *lockedSession {
+	d.repoMu.RLock()
+	defer d.repoMu.RUnlock()
+	return d.locked
+}
+
+// beginLock marks the session locked and drops the password from the
+// connection string; false if it is already locked or not connected
+func (d *DatabaseApp) beginLock(locked *lockedSession) bool {
+	d.repoMu.Lock()
+	defer d.repoMu.Unlock()
+	if d.locked != nil || d.repo == nil {
+		return false
+	}
+	locked.connStr = withoutPassword(d.backend, d.connStr)
+	if locked.connStr != "" {
+		d.connStr = locked.connStr
+	}
+	d.locked = locked
+	return true
+}
+
+// lockSession hides the main window and its dialogs behind the login card
+// and closes the connection. The forms are kept: unlocking opens the
+// connection again with the password entered and shows them as they were.
+func (d *DatabaseApp) lockSession() {
+	// Копия списка: Remove очищает массив, который возвращает List
+	overlays := d.window.Canvas().Overlays()
+	locked := &lockedSession{
+		content:  d.window.Content(),
+		overlays: append([]fyne.CanvasObject(nil), overlays.List()...),
+	}
+	if !d.beginLock(locked) {
+		return
+	}
+
+	// Диалог переподключения не должен остаться под экраном блокировки
+	if m := d.monitor(); m != nil {
+		m.Pause()
+	}
+	for _, o := range locked.overlays {
+		overlays.Remove(o)
+	}
+	d.closeConnection()
+	d.showLockScreen()
+}
+
+// reopenSession connects again with the password of the locked session.
+// The role is read anew: the tabs stay as they were, the repository
+// enforces the current role.
+func (d *DatabaseApp) reopenSession(ctx context.Context, password string) error {
+	locked := d.lockedState()
+	if locked == nil {
+		return nil
+	}
+	if locked.connStr == "" {
+		return errNoSessionPassword
+	}
+
+	backend, _, _ := d.connection()
+	connStr := withPassword(locked.connStr, password)
+	repo, err := openRepository(backend, connStr)
+	if err != nil {
+		return err
+	}
+	user, err := repo.CurrentUser(ctx)
+	if err != nil {
+		repo.Close()
+		return err
+	}
+
+	d.repoMu.Lock()
+	old := d.repo
+	d.repo = withRole(repo, user.Role)
+	d.connStr = connStr
+	d.user = user
+	d.repoMu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// unlockSession shows the main window again
+func (d *DatabaseApp) unlockSession() {
+	d.repoMu.Lock()
+	locked := d.locked
+	d.locked = nil
+	d.repoMu.Unlock()
+	if locked == nil {
+		return
+	}
+
+	d.window.SetContent(locked.content)
+	for _, o := range locked.overlays {
+		d.window.Canvas().Overlays().Add(o)
+	}
+	if m := d.monitor(); m != nil {
+		m.Resume()
+	}
+	d.touchSession()
+}
+
+// showLockScreen asks the locked-out user for the password of the connection.
+// Another user switches user instead: that drops the hidden forms. A
+// connection without a password (SQLite, Windows authentication) has no
+// credential to check, so it can only be left by a new login.
+func (d *DatabaseApp) showLockScreen() {
+	passEntry := widget.NewPasswordEntry()
+	passEntry.SetPlaceHolder("Password")
+
+	unlockBtn := widget.NewButtonWithIcon("Unlock", theme.LoginIcon(), nil)
+	unlockBtn.Importance = widget.HighImportance
+	switchBtn := widget.NewButtonWithIcon("Switch User", theme.AccountIcon(), d.returnToLogin)
+
+	unlock := func() {
+		password := passEntry.Text
+		d.runInBackground("Разблокировка", func(ctx context.Context) error {
+			return d.reopenSession(ctx, password)
+		}, func(err error) {
+			if err != nil {
+				passEntry.SetText("")
+				dialog.ShowError(fmt.Errorf("Unable to unlock the session:\n%v", err), d.window)
+				return
+			}
+			d.unlockSession()
+		}, unlockBtn, passEntry)
+	}
+	unlockBtn.OnTapped = unlock
+	passEntry.OnSubmitted = func(string) { unlock() }
+
+	backend, _, user := d.connection()
+	userInfo := fmt.Sprintf("%s (%s) — %s", user.Name, roleTitles[user.Role], backend)
+	items := []fyne.CanvasObject{
+		widget.NewLabelWithStyle(userInfo, fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+	}
+
+	locked := d.lockedState()
+	canUnlock := locked != nil && locked.connStr != ""
+	prompt := "The session is locked. Enter the password to continue"
+	if canUnlock {
+		items = append(items, &widget.Form{
+			Items: []*widget.FormItem{{Text: "Password", Widget: passEntry}},
+		}, container.NewBorder(nil, nil, nil, switchBtn, unlockBtn))
+	} else {
+		prompt = "The session is locked"
+		items = append(items, widget.NewLabelWithStyle("This connection has no password to check: log in again to continue",
+			fyne.TextAlignCenter, fyne.TextStyle{Italic: true}), switchBtn)
+	}
+
+	d.window.SetContent(d.loginCard(prompt, items...))
+	if canUnlock {
+		d.window.Canvas().Focus(passEntry)
+	}
+}
+
+// returnToLogin closes the connection and shows the login screen; the
+// windows hidden by a session lock are dropped
+func (d *DatabaseApp) returnToLogin() {
+	d.repoMu.Lock()
+	d.locked = nil
+	d.repoMu.Unlock()
+	if d.idle != nil {
+		d.idle.Stop()
+		d.idle = nil
+	}
+	if m := d.setMonitor(nil); m != nil {
+		m.Stop()
+	}
+	d.closeConnection()
+	d.showLoginScreen()
+}
+
+// lockButtons returns the Lock and Switch user buttons of the main window
+func (d *DatabaseApp) lockButtons() fyne.CanvasObject {
+	lockBtn := widget.NewButtonWithIcon("Заблокировать", theme.VisibilityOffIcon(), d.lockSession)
+	switchBtn := widget.NewButtonWithIcon("Сменить пользователя", theme.LogoutIcon(), func() {
+		dialog.ShowConfirm("Смена пользователя",
+			"Подключение к базе данных будет закрыто, несохранённые данные на вкладках будут потеряны. Продолжить?",
+			func(ok bool) {
+				if ok {
+					d.returnToLogin()
+				}
+			}, d.window)
+	})
+	return container.NewHBox(lockBtn, switchBtn)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestWithoutPassword(t *testing.T) {
+	connStr := connectionProfile{Backend: backendMSSQL, Server: "db", User: "sa", Database: "cars"}.connString("p@ss:word")
+
+	stripped := withoutPassword(backendMSSQL, connStr)
+	if stripped == "" || stripped == connStr {
+		t.Fatalf("пароль не удалён: %q", stripped)
+	}
+	if got := withPassword(stripped, "p@ss:word"); got != connStr {
+		t.Errorf("withPassword = %q, ожидалось %q", got, connStr)
+	}
+
+	// Без пароля проверить нечего
+	for _, tt := range []struct{ backend, connStr string }{
+		{backendSQLite, "cars.db"},
+		{backendMSSQL, "sqlserver://db?database=cars"},
+		{backendMSSQL, "sqlserver://sa@db?database=cars"},
+	} {
+		if got := withoutPassword(tt.backend, tt.connStr); got != "" {
+			t.Errorf("withoutPassword(%q) = %q, ожидалась пустая строка", tt.connStr, got)
+		}
+	}
+}
+
+// TestLockSessionClosesConnection checks that the lock closes the connection
+// and that a connection without a password cannot be unlocked; run with -race
+func TestLockSessionClosesConnection(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	d := &DatabaseApp{app: a, window: a.NewWindow("test"), activity: newActivityIndicator()}
+	if err := d.connectDB(backendSQLite, filepath.Join(t.TempDir(), "cars.db")); err != nil {
+		t.Fatal(err)
+	}
+	d.user = AppUser{Name: "test", Role: roleAdmin}
+	content := widget.NewLabel("вкладки")
+	d.window.SetContent(content)
+
+	// Проверка бездействия читает состояние блокировки в своей горутине
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			d.lockedState()
+		}
+	}()
+	d.lockSession()
+	<-done
+
+	if d.lockedState() == nil {
+		t.Fatal("сеанс не заблокирован")
+	}
+	if err := d.repository().Ping(context.Background()); !errors.Is(err, errNotConnected) {
+		t.Errorf("подключение осталось открытым: %v", err)
+	}
+	if d.window.Content() == content {
+		t.Error("главное окно не скрыто")
+	}
+
+	if err := d.reopenSession(context.Background(), ""); !errors.Is(err, errNoSessionPassword) {
+		t.Errorf("разблокировка SQLite: %v", err)
+	}
+	if d.lockedState() == nil || !errors.Is(d.repository().Ping(context.Background()), errNotConnected) {
+		t.Error("сеанс без пароля разблокирован")
+	}
+}
+
+// TestLockDuringOperation locks the session while a background operation
+// uses the repository: its next call fails instead of dereferencing nil
+func TestLockDuringOperation(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	d := &DatabaseApp{app: a, window: a.NewWindow("test"), activity: newActivityIndicator()}
+	if err := d.connectDB(backendSQLite, filepath.Join(t.TempDir(), "cars.db")); err != nil {
+		t.Fatal(err)
+	}
+	d.user = AppUser{Name: "test", Role: roleAdmin}
+	d.window.SetContent(widget.NewLabel("вкладки"))
+
+	started, locked := make(chan struct{}), make(chan struct{})
+	result := make(chan error, 1)
+	d.runInBackground("Проверка", func(ctx context.Context) error {
+		if err := d.repository().Ping(ctx); err != nil {
+			return err
+		}
+		close(started)
+		<-locked
+		_, err := d.repository().GetOwners(ctx)
+		return err
+	}, func(err error) { result <- err })
+
+	<-started
+	d.lockSession()
+	close(locked)
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, errNotConnected) {
+			t.Errorf("операция после блокировки вернула %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("операция не завершилась")
+	}
+}
@@ -1,10 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strconv"
-	"time"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -20,55 +21,33 @@ func (d *DatabaseApp) createAddOwnerTab() *container.Scroll {
 
 	firstNameEntry := widget.NewEntry()
 	firstNameEntry.SetPlaceHolder("Введите имя")
-	firstNameEntry.Validator = func(s string) error {
-		if len(s) < 2 {
-			return fmt.Errorf("имя должно быть не менее 2 символов")
-		}
-		return nil
-	}
+	firstNameEntry.Validator = validateFirstName
 
 	lastNameEntry := widget.NewEntry()
 	lastNameEntry.SetPlaceHolder("Введите фамилию")
-	lastNameEntry.Validator = func(s string) error {
-		if len(s) < 2 {
-			return fmt.Errorf("фамилия должна быть не менее 2 символов")
-		}
-		return nil
-	}
+	lastNameEntry.Validator = validateLastName
 
 	phoneEntry := widget.NewEntry()
 	phoneEntry.SetPlaceHolder("Введите телефон")
-	phoneEntry.Validator = func(s string) error {
-		if s == "" {
-			return nil
-		}
-		if len(s) < 5 {
-			return fmt.Errorf("телефон должен быть не менее 5 символов")
-		}
-		return nil
-	}
+	phoneEntry.Validator = validatePhone
 
 	emailEntry := widget.NewEntry()
 	emailEntry.SetPlaceHolder("Введите email")
-	emailEntry.Validator = func(s string) error {
-		if s == "" {
-			return nil
-		}
-		if !contains(s, "@") {
-			return fmt.Errorf("email должен содержать @")
-		}
-		return nil
-	}
+	emailEntry.Validator = validateEmail
 
 	categorySelect := widget.NewSelect([]string{}, nil)
 	categorySelect.PlaceHolder = "Выберите категорию прав"
 
-	addBtn := widget.NewButtonWithIcon("Добавить владельца", theme.ContentAddIcon(), func() {
-		d.addOwnerHandler(firstNameEntry, lastNameEntry, phoneEntry, emailEntry, categorySelect)
-	})
+	addBtn := widget.NewButtonWithIcon("Добавить владельца", theme.ContentAddIcon(), nil)
 	addBtn.Importance = widget.HighImportance
 
-	form := &widget.Form{
+	var form *widget.Form
+	addOwner := func() {
+		d.addOwnerHandler(firstNameEntry, lastNameEntry, phoneEntry, emailEntry, categorySelect, addBtn, form)
+	}
+	addBtn.OnTapped = addOwner
+
+	form = &widget.Form{
 		Items: []*widget.FormItem{
 			widget.NewFormItem("Имя:", firstNameEntry),
 			widget.NewFormItem("Фамилия:", lastNameEntry),
@@ -76,9 +55,7 @@ func (d *DatabaseApp) createAddOwnerTab() *container.Scroll {
 			widget.NewFormItem("Email:", emailEntry),
 			widget.NewFormItem("Категория прав:", categorySelect),
 		},
-		OnSubmit: func() {
-			d.addOwnerHandler(firstNameEntry, lastNameEntry, phoneEntry, emailEntry, categorySelect)
-		},
+		OnSubmit:   addOwner,
 		SubmitText: "Добавить владельца",
 	}
 
@@ -90,40 +67,26 @@ func (d *DatabaseApp) createAddOwnerTab() *container.Scroll {
 		addBtn,
 	)
 
-	updateCategories := func() {
-		categories, err := d.getDriverCategories()
-		if err != nil {
-			log.Printf("Ошибка получения категорий: %v", err)
-			return
-		}
-
-		categoryOptions := make([]string, 0, len(categories))
-		for _, cat := range categories {
-			option := fmt.Sprintf("%s - %s", cat.Code, cat.Name)
-			categoryOptions = append(categoryOptions, option)
-		}
+	d.fillCategorySelect(categorySelect, nil)
 
-		categorySelect.Options = categoryOptions
-		if len(categoryOptions) > 0 {
-			categorySelect.SetSelected(categoryOptions[0])
-		} else {
-			categorySelect.SetSelected("")
-		}
-		categorySelect.Refresh()
+	refreshBtn := widget.NewButtonWithIcon("Обновить категории", theme.ViewRefreshIcon(), nil)
+	refreshBtn.OnTapped = func() {
+		d.fillCategorySelect(categorySelect, func() {
+			d.showMessage("Обновлено", "Список категорий обновлен")
+		}, refreshBtn)
 	}
-	updateCategories()
-
-	refreshBtn := widget.NewButtonWithIcon("Обновить категории", theme.ViewRefreshIcon(), func() {
-		updateCategories()
-		d.showMessage("Обновлено", "Список категорий обновлен")
-	})
 	refreshBtn.Importance = widget.MediumImportance
 	content.Add(refreshBtn)
 
+	importBtn := widget.NewButtonWithIcon("Импорт из CSV", theme.UploadIcon(), func() {
+		d.showImportWizard("owners")
+	})
+	content.Add(importBtn)
+
 	return container.NewScroll(container.NewPadded(content))
 }
 
-func (d *DatabaseApp) addOwnerHandler(firstNameEntry, lastNameEntry, phoneEntry, emailEntry *widget.Entry, categorySelect *widget.Select) {
+func (d *DatabaseApp) addOwnerHandler(firstNameEntry, lastNameEntry, phoneEntry, emailEntry *widget.Entry, categorySelect *widget.Select, busy ...fyne.Disableable) {
 	if firstNameEntry.Text == "" || lastNameEntry.Text == "" {
 		d.showMessage("Ошибка", "Имя и фамилия обязательны для заполнения")
 		return
@@ -134,31 +97,42 @@ func (d *DatabaseApp) addOwnerHandler(firstNameEntry, lastNameEntry, phoneEntry,
 		return
 	}
 
-	categories, err := d.getDriverCategories()
-	if err != nil {
-		d.showMessage("Ошибка", fmt.Sprintf("Ошибка получения категорий: %v", err))
-		return
-	}
+	// Значения формы читаются до запуска фоновой операции
+	firstName, lastName := firstNameEntry.Text, lastNameEntry.Text
+	phone, email := phoneEntry.Text, emailEntry.Text
+	selected := categorySelect.Selected
 
-	var categoryID int
-	for _, cat := range categories {
-		option := fmt.Sprintf("%s - %s", cat.Code, cat.Name)
-		if option == categorySelect.Selected {
-			categoryID = cat.ID
-			break
+	var stage string
+	d.runInBackground("Добавление владельца", func(ctx context.Context) error {
+		stage = "Ошибка получения категорий"
+		categories, err := d.repository().GetDriverCategories(ctx)
+		if err != nil {
+			return err
 		}
-	}
 
-	err = d.addOwner(firstNameEntry.Text, lastNameEntry.Text, phoneEntry.Text, emailEntry.Text, categoryID)
-	if err != nil {
-		d.showMessage("Ошибка", fmt.Sprintf("Не удалось добавить владельца: %v", err))
-	} else {
+		var categoryID int
+		for _, cat := range categories {
+			option := fmt.Sprintf("%s - %s", cat.Code, cat.Name)
+			if option == selected {
+				categoryID = cat.ID
+				break
+			}
+		}
+
+		stage = "Не удалось добавить владельца"
+		_, err = d.repository().AddOwner(ctx, firstName, lastName, phone, email, categoryID)
+		return err
+	}, func(err error) {
+		if err != nil {
+			d.showMessage("Ошибка", fmt.Sprintf("%s: %v", stage, err))
+			return
+		}
 		d.showMessage("Успех", "Владелец успешно добавлен")
 		firstNameEntry.SetText("")
 		lastNameEntry.SetText("")
 		phoneEntry.SetText("")
 		emailEntry.SetText("")
-	}
+	}, busy...)
 }
 
 func (d *DatabaseApp) refreshAddOwnerTab(content *fyne.Container) {
@@ -180,25 +154,41 @@ func (d *DatabaseApp) refreshAddOwnerTab(content *fyne.Container) {
 		return
 	}
 
-	categories, err := d.getDriverCategories()
-	if err != nil {
-		log.Printf("Ошибка получения категорий: %v", err)
-		return
-	}
+	d.fillCategorySelect(categorySelect, nil)
+}
 
-	categoryOptions := make([]string, 0, len(categories))
-	for _, cat := range categories {
-		option := fmt.Sprintf("%s - %s", cat.Code, cat.Name)
-		categoryOptions = append(categoryOptions, option)
-	}
+// fillCategorySelect loads the driver categories into the select in the
+// background and selects the first one; then runs after a successful load
+func (d *DatabaseApp) fillCategorySelect(categorySelect *widget.Select, then func(), busy ...fyne.Disableable) {
+	var categories []DriverCategory
+	d.runInBackground("Загрузка категорий", func(ctx context.Context) error {
+		var err error
+		categories, err = d.repository().GetDriverCategories(ctx)
+		return err
+	}, func(err error) {
+		if err != nil {
+			log.Printf("Ошибка получения категорий: %v", err)
+			return
+		}
 
-	categorySelect.Options = categoryOptions
-	if len(categoryOptions) > 0 {
-		categorySelect.SetSelected(categoryOptions[0])
-	} else {
-		categorySelect.SetSelected("")
-	}
-	categorySelect.Refresh()
+		categoryOptions := make([]string, 0, len(categories))
+		for _, cat := range categories {
+			option := fmt.Sprintf("%s - %s", cat.Code, cat.Name)
+			categoryOptions = append(categoryOptions, option)
+		}
+
+		categorySelect.Options = categoryOptions
+		if len(categoryOptions) > 0 {
+			categorySelect.SetSelected(categoryOptions[0])
+		} else {
+			categorySelect.SetSelected("")
+		}
+		categorySelect.Refresh()
+
+		if then != nil {
+			then()
+		}
+	}, append(busy, categorySelect)...)
 }
 
 // --- ADD CAR TAB ---
@@ -214,64 +204,33 @@ func (d *DatabaseApp) createAddCarTab() *container.Scroll {
 
 	modelEntry := widget.NewEntry()
 	modelEntry.SetPlaceHolder("Введите модель")
-	modelEntry.Validator = func(s string) error {
-		if len(s) < 1 {
-			return fmt.Errorf("модель не может быть пустой")
-		}
-		return nil
-	}
+	modelEntry.Validator = validateModel
 
 	yearEntry := widget.NewEntry()
 	yearEntry.SetPlaceHolder("Введите год выпуска")
-	yearEntry.Validator = func(s string) error {
-		if s == "" {
-			return nil
-		}
-		year, err := strconv.Atoi(s)
-		if err != nil {
-			return fmt.Errorf("год должен быть числом")
-		}
-		currentYear := time.Now().Year()
-		if year < 1886 || year > currentYear+1 {
-			return fmt.Errorf("год должен быть между 1886 и %d", currentYear+1)
-		}
-		return nil
-	}
+	yearEntry.Validator = validateYear
 
 	colorEntry := widget.NewEntry()
 	colorEntry.SetPlaceHolder("Введите цвет")
 
 	vinEntry := widget.NewEntry()
 	vinEntry.SetPlaceHolder("Введите VIN код")
-	vinEntry.Validator = func(s string) error {
-		if s == "" {
-			return nil
-		}
-		if len(s) < 10 {
-			return fmt.Errorf("VIN должен быть не менее 10 символов")
-		}
-		return nil
-	}
+	vinEntry.Validator = validateVIN
 
 	priceEntry := widget.NewEntry()
 	priceEntry.SetPlaceHolder("Введите цену")
-	priceEntry.Validator = func(s string) error {
-		if s == "" {
-			return nil
-		}
-		_, err := strconv.ParseFloat(s, 64)
-		if err != nil {
-			return fmt.Errorf("цена должна быть числом")
-		}
-		return nil
-	}
+	priceEntry.Validator = validatePrice
 
-	addBtn := widget.NewButtonWithIcon("Добавить автомобиль", theme.ContentAddIcon(), func() {
-		d.addCarHandler(ownerSelect, brandSelect, modelEntry, yearEntry, colorEntry, vinEntry, priceEntry)
-	})
+	addBtn := widget.NewButtonWithIcon("Добавить автомобиль", theme.ContentAddIcon(), nil)
 	addBtn.Importance = widget.HighImportance
 
-	form := &widget.Form{
+	var form *widget.Form
+	addCar := func() {
+		d.addCarHandler(ownerSelect, brandSelect, modelEntry, yearEntry, colorEntry, vinEntry, priceEntry, addBtn, form)
+	}
+	addBtn.OnTapped = addCar
+
+	form = &widget.Form{
 		Items: []*widget.FormItem{
 			widget.NewFormItem("Владелец:", ownerSelect),
 			widget.NewFormItem("Марка:", brandSelect),
@@ -281,9 +240,7 @@ func (d *DatabaseApp) createAddCarTab() *container.Scroll {
 			widget.NewFormItem("VIN код:", vinEntry),
 			widget.NewFormItem("Цена:", priceEntry),
 		},
-		OnSubmit: func() {
-			d.addCarHandler(ownerSelect, brandSelect, modelEntry, yearEntry, colorEntry, vinEntry, priceEntry)
-		},
+		OnSubmit:   addCar,
 		SubmitText: "Добавить автомобиль",
 	}
 
@@ -295,51 +252,38 @@ func (d *DatabaseApp) createAddCarTab() *container.Scroll {
 		addBtn,
 	)
 
-	updateLists := func() {
-		owners, err := d.getOwners()
-		if err != nil {
-			log.Printf("Ошибка получения владельцев: %v", err)
-		} else {
-			ownerOptions := make([]string, 0, len(owners))
-			for _, owner := range owners {
-				option := fmt.Sprintf("%d: %s %s", owner.ID, owner.FirstName, owner.LastName)
-				ownerOptions = append(ownerOptions, option)
-			}
-			ownerSelect.Options = ownerOptions
-			if len(ownerOptions) > 0 {
-				ownerSelect.SetSelected(ownerOptions[0])
-			}
-			ownerSelect.Refresh()
-		}
+	d.fillOwnerBrandSelects(ownerSelect, brandSelect, nil)
 
-		brands, err := d.getCarBrands()
-		if err != nil {
-			log.Printf("Ошибка получения брендов: %v", err)
-		} else {
-			brandOptions := make([]string, 0, len(brands))
-			for _, brand := range brands {
-				brandOptions = append(brandOptions, brand.Name)
-			}
-			brandSelect.Options = brandOptions
-			if len(brandOptions) > 0 {
-				brandSelect.SetSelected(brandOptions[0])
+	d.openAddCar = func(ownerID int) {
+		d.fillOwnerBrandSelects(ownerSelect, brandSelect, func() {
+			prefix := fmt.Sprintf("%d: ", ownerID)
+			for _, option := range ownerSelect.Options {
+				if strings.HasPrefix(option, prefix) {
+					ownerSelect.SetSelected(option)
+					break
+				}
 			}
-			brandSelect.Refresh()
-		}
+		})
 	}
-	updateLists()
 
-	refreshBtn := widget.NewButtonWithIcon("Обновить списки", theme.ViewRefreshIcon(), func() {
-		updateLists()
-		d.showMessage("Обновлено", "Списки владельцев и марок обновлены")
-	})
+	refreshBtn := widget.NewButtonWithIcon("Обновить списки", theme.ViewRefreshIcon(), nil)
+	refreshBtn.OnTapped = func() {
+		d.fillOwnerBrandSelects(ownerSelect, brandSelect, func() {
+			d.showMessage("Обновлено", "Списки владельцев и марок обновлены")
+		}, refreshBtn)
+	}
 	refreshBtn.Importance = widget.MediumImportance
 	content.Add(refreshBtn)
 
+	importBtn := widget.NewButtonWithIcon("Импорт из CSV", theme.UploadIcon(), func() {
+		d.showImportWizard("cars")
+	})
+	content.Add(importBtn)
+
 	return container.NewScroll(container.NewPadded(content))
 }
 
-func (d *DatabaseApp) addCarHandler(ownerSelect, brandSelect *widget.Select, modelEntry, yearEntry, colorEntry, vinEntry, priceEntry *widget.Entry) {
+func (d *DatabaseApp) addCarHandler(ownerSelect, brandSelect *widget.Select, modelEntry, yearEntry, colorEntry, vinEntry, priceEntry *widget.Entry, busy ...fyne.Disableable) {
 	if modelEntry.Text == "" {
 		d.showMessage("Ошибка", "Модель обязательна для заполнения")
 		return
@@ -350,49 +294,58 @@ func (d *DatabaseApp) addCarHandler(ownerSelect, brandSelect *widget.Select, mod
 		return
 	}
 
-	owners, err := d.getOwners()
-	if err != nil {
-		d.showMessage("Ошибка", fmt.Sprintf("Ошибка получения владельцев: %v", err))
-		return
-	}
+	// Значения формы читаются до запуска фоновой операции
+	selectedOwner, selectedBrand := ownerSelect.Selected, brandSelect.Selected
+	model, color, vin := modelEntry.Text, colorEntry.Text, vinEntry.Text
+	year, _ := strconv.Atoi(yearEntry.Text)
+	price, _ := strconv.ParseFloat(priceEntry.Text, 64)
 
-	var ownerID int
-	for _, owner := range owners {
-		option := fmt.Sprintf("%d: %s %s", owner.ID, owner.FirstName, owner.LastName)
-		if option == ownerSelect.Selected {
-			ownerID = owner.ID
-			break
+	var stage string
+	d.runInBackground("Добавление автомобиля", func(ctx context.Context) error {
+		stage = "Ошибка получения владельцев"
+		owners, err := d.repository().GetOwners(ctx)
+		if err != nil {
+			return err
 		}
-	}
 
-	brands, err := d.getCarBrands()
-	if err != nil {
-		d.showMessage("Ошибка", fmt.Sprintf("Ошибка получения брендов: %v", err))
-		return
-	}
+		var ownerID int
+		for _, owner := range owners {
+			option := fmt.Sprintf("%d: %s %s", owner.ID, owner.FirstName, owner.LastName)
+			if option == selectedOwner {
+				ownerID = owner.ID
+				break
+			}
+		}
 
-	var brandID int
-	for _, brand := range brands {
-		if brand.Name == brandSelect.Selected {
-			brandID = brand.ID
-			break
+		stage = "Ошибка получения брендов"
+		brands, err := d.repository().GetCarBrands(ctx)
+		if err != nil {
+			return err
 		}
-	}
 
-	year, _ := strconv.Atoi(yearEntry.Text)
-	price, _ := strconv.ParseFloat(priceEntry.Text, 64)
+		var brandID int
+		for _, brand := range brands {
+			if brand.Name == selectedBrand {
+				brandID = brand.ID
+				break
+			}
+		}
 
-	err = d.addCar(ownerID, brandID, modelEntry.Text, year, colorEntry.Text, vinEntry.Text, price)
-	if err != nil {
-		d.showMessage("Ошибка", fmt.Sprintf("Не удалось добавить автомобиль: %v", err))
-	} else {
+		stage = "Не удалось добавить автомобиль"
+		_, err = d.repository().AddCar(ctx, ownerID, brandID, model, year, color, vin, price)
+		return err
+	}, func(err error) {
+		if err != nil {
+			d.showMessage("Ошибка", fmt.Sprintf("%s: %v", stage, err))
+			return
+		}
 		d.showMessage("Успех", "Автомобиль успешно добавлен")
 		modelEntry.SetText("")
 		yearEntry.SetText("")
 		colorEntry.SetText("")
 		vinEntry.SetText("")
 		priceEntry.SetText("")
-	}
+	}, busy...)
 }
 
 func (d *DatabaseApp) refreshAddCarTab(content *fyne.Container) {
@@ -417,34 +370,51 @@ func (d *DatabaseApp) refreshAddCarTab(content *fyne.Container) {
 		return
 	}
 
-	owners, err := d.getOwners()
-	if err != nil {
-		log.Printf("Ошибка получения владельцев: %v", err)
-	} else {
-		ownerOptions := make([]string, 0, len(owners))
-		for _, owner := range owners {
-			option := fmt.Sprintf("%d: %s %s", owner.ID, owner.FirstName, owner.LastName)
-			ownerOptions = append(ownerOptions, option)
-		}
-		ownerSelect.Options = ownerOptions
-		if len(ownerOptions) > 0 {
-			ownerSelect.SetSelected(ownerOptions[0])
+	d.fillOwnerBrandSelects(ownerSelect, brandSelect, nil)
+}
+
+// fillOwnerBrandSelects loads the owners and brands into the selects in the
+// background and selects the first of each; then runs after the load
+func (d *DatabaseApp) fillOwnerBrandSelects(ownerSelect, brandSelect *widget.Select, then func(), busy ...fyne.Disableable) {
+	var owners []Owner
+	var brands []CarBrand
+	var ownersErr, brandsErr error
+	d.runInBackground("Загрузка владельцев и марок", func(ctx context.Context) error {
+		owners, ownersErr = d.repository().GetOwners(ctx)
+		brands, brandsErr = d.repository().GetCarBrands(ctx)
+		return nil
+	}, func(error) {
+		if ownersErr != nil {
+			log.Printf("Ошибка получения владельцев: %v", ownersErr)
+		} else {
+			ownerOptions := make([]string, 0, len(owners))
+			for _, owner := range owners {
+				option := fmt.Sprintf("%d: %s %s", owner.ID, owner.FirstName, owner.LastName)
+				ownerOptions = append(ownerOptions, option)
+			}
+			ownerSelect.Options = ownerOptions
+			if len(ownerOptions) > 0 {
+				ownerSelect.SetSelected(ownerOptions[0])
+			}
+			ownerSelect.Refresh()
 		}
-		ownerSelect.Refresh()
-	}
 
-	brands, err := d.getCarBrands()
-	if err != nil {
-		log.Printf("Ошибка получения брендов: %v", err)
-	} else {
-		brandOptions := make([]string, 0, len(brands))
-		for _, brand := range brands {
-			brandOptions = append(brandOptions, brand.Name)
+		if brandsErr != nil {
+			log.Printf("Ошибка получения брендов: %v", brandsErr)
+		} else {
+			brandOptions := make([]string, 0, len(brands))
+			for _, brand := range brands {
+				brandOptions = append(brandOptions, brand.Name)
+			}
+			brandSelect.Options = brandOptions
+			if len(brandOptions) > 0 {
+				brandSelect.SetSelected(brandOptions[0])
+			}
+			brandSelect.Refresh()
 		}
-		brandSelect.Options = brandOptions
-		if len(brandOptions) > 0 {
-			brandSelect.SetSelected(brandOptions[0])
+
+		if then != nil {
+			then()
 		}
-		brandSelect.Refresh()
-	}
+	}, append(busy, ownerSelect, brandSelect)...)
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+// newTestApp returns an application connected to repo, without a main window
+func newTestApp(t *testing.T, repo Repository) *DatabaseApp {
+	t.Helper()
+	a := test.NewApp()
+	t.Cleanup(a.Quit)
+
+	d := &DatabaseApp{app: a, window: a.NewWindow("test"), activity: newActivityIndicator()}
+	d.setRepository(repo)
+	return d
+}
+
+// waitBackground waits until the operation that disabled busy has finished
+// and its result was shown
+func waitBackground(t *testing.T, d *DatabaseApp, busy *widget.Button) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for busy.Disabled() {
+		if time.Now().After(deadline) {
+			t.Fatal("фоновая операция не завершилась")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// done выполняется в той же очереди onUI, что и эта функция
+	shown := make(chan struct{})
+	d.onUI(func() { close(shown) })
+	<-shown
+}
+
+func TestAddOwnerHandler(t *testing.T) {
+	repo := newMemoryRepository()
+	d := newTestApp(t, repo)
+
+	first, last := widget.NewEntry(), widget.NewEntry()
+	phone, email := widget.NewEntry(), widget.NewEntry()
+	category := widget.NewSelect([]string{"B - Категория B", "C - Категория C"}, nil)
+	btn := widget.NewButton("Добавить", nil)
+
+	// Без фамилии владелец не добавляется
+	first.SetText("Иван")
+	category.SetSelected("C - Категория C")
+	d.addOwnerHandler(first, last, phone, email, category, btn)
+	if n := countOwners(t, repo); n != 0 {
+		t.Fatalf("добавлен владелец без фамилии: %d", n)
+	}
+
+	last.SetText("Петров")
+	phone.SetText("+79001234567")
+	d.addOwnerHandler(first, last, phone, email, category, btn)
+	waitBackground(t, d, btn)
+
+	owners, err := repo.GetOwners(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(owners) != 1 || owners[0].FirstName != "Иван" || owners[0].LastName != "Петров" ||
+		owners[0].Phone != "+79001234567" || owners[0].Category != "C" {
+		t.Fatalf("владельцы: %+v", owners)
+	}
+	if first.Text != "" || last.Text != "" || phone.Text != "" {
+		t.Error("форма не очищена после добавления")
+	}
+}
+
+func TestAddCarHandler(t *testing.T) {
+	repo := newMemoryRepository()
+	d := newTestApp(t, repo)
+	ownerID, err := repo.AddOwner(context.Background(), "Иван", "Петров", "", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner := widget.NewSelect([]string{"1: Иван Петров"}, nil)
+	brand := widget.NewSelect([]string{"Toyota", "BMW"}, nil)
+	model, year, color := widget.NewEntry(), widget.NewEntry(), widget.NewEntry()
+	vin, price := widget.NewEntry(), widget.NewEntry()
+	btn := widget.NewButton("Добавить", nil)
+
+	owner.SetSelected("1: Иван Петров")
+	brand.SetSelected("BMW")
+	model.SetText("X5")
+	year.SetText("2020")
+	vin.SetText("WBAFR9C50BC123456")
+	price.SetText("45000")
+	d.addCarHandler(owner, brand, model, year, color, vin, price, btn)
+	waitBackground(t, d, btn)
+
+	cars, err := repo.GetCarsByOwner(context.Background(), ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cars) != 1 || cars[0].Model != "X5" || cars[0].BrandID != 2 || cars[0].Year != 2020 || cars[0].Price != 45000 {
+		t.Fatalf("автомобили владельца: %+v", cars)
+	}
+	if model.Text != "" || vin.Text != "" {
+		t.Error("форма не очищена после добавления")
+	}
+}
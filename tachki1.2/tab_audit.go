@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// auditLimit is the maximum number of entries shown in the Audit tab
+const auditLimit = 500
+
+// auditTableTitles are the audited tables as shown in the filter
+var auditTableTitles = []struct {
+	Title string
+	Name  string
+}{
+	{"Владельцы", "owners"},
+	{"Автомобили", "cars"},
+	{"Марки автомобилей", "car_brands"},
+}
+
+// auditOperationTitles names the audit_log operations in the UI
+var auditOperationTitles = map[string]string{
+	auditInsert: "Добавление",
+	auditUpdate: "Изменение",
+	auditDelete: "Удаление",
+}
+
+func (d *DatabaseApp) createAuditTab() fyne.CanvasObject {
+	titleLabel := widget.NewLabelWithStyle("Журнал изменений", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+
+	// --- Фильтры ---
+	const anyTable, anyOperation = "Все таблицы", "Все операции"
+
+	tableOptions := []string{anyTable}
+	tableByTitle := make(map[string]string)
+	for _, t := range auditTableTitles {
+		tableOptions = append(tableOptions, t.Title)
+		tableByTitle[t.Title] = t.Name
+	}
+	tableSelect := widget.NewSelect(tableOptions, nil)
+	tableSelect.SetSelected(anyTable)
+
+	operationOptions := []string{anyOperation}
+	operationByTitle := make(map[string]string)
+	for _, op := range []string{auditInsert, auditUpdate, auditDelete} {
+		operationOptions = append(operationOptions, auditOperationTitles[op])
+		operationByTitle[auditOperationTitles[op]] = op
+	}
+	operationSelect := widget.NewSelect(operationOptions, nil)
+	operationSelect.SetSelected(anyOperation)
+
+	userEntry := widget.NewEntry()
+	userEntry.SetPlaceHolder("Пользователь")
+
+	idEntry := widget.NewEntry()
+	idEntry.SetPlaceHolder("ID записи")
+
+	fromEntry := widget.NewEntry()
+	fromEntry.SetPlaceHolder("с ДД.ММ.ГГГГ")
+
+	toEntry := widget.NewEntry()
+	toEntry.SetPlaceHolder("по ДД.ММ.ГГГГ")
+
+	// --- Список записей и подробности ---
+	var entries []AuditEntry
+
+	details := widget.NewLabel("Выберите запись журнала, чтобы увидеть изменения")
+	details.Wrapping = fyne.TextWrapWord
+
+	list := widget.NewList(
+		func() int { return len(entries) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(auditEntrySummary(entries[id]))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		details.SetText(auditEntryDetails(entries[id]))
+	}
+
+	countLabel := widget.NewLabel("")
+
+	findBtn := widget.NewButtonWithIcon("Найти", theme.SearchIcon(), nil)
+	findBtn.Importance = widget.HighImportance
+
+	reload := func() {
+		filter := AuditFilter{
+			Table:     tableByTitle[tableSelect.Selected],
+			Operation: operationByTitle[operationSelect.Selected],
+			User:      strings.TrimSpace(userEntry.Text),
+			Limit:     auditLimit,
+		}
+
+		if text := strings.TrimSpace(idEntry.Text); text != "" {
+			id, err := strconv.Atoi(text)
+			if err != nil {
+				d.showMessage("Ошибка", "ID должен быть числом")
+				return
+			}
+			filter.RecordID = id
+		}
+
+		var err error
+		if filter.From, err = parseFilterDate(fromEntry.Text); err == nil {
+			filter.To, err = parseFilterDate(toEntry.Text)
+		}
+		if err != nil {
+			d.showMessage("Ошибка", err.Error())
+			return
+		}
+
+		var result []AuditEntry
+		d.runInBackground("Загрузка журнала", func(ctx context.Context) error {
+			var err error
+			result, err = d.repository().GetAuditLog(ctx, filter)
+			return err
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Не удалось загрузить журнал: %v", err))
+				return
+			}
+
+			entries = result
+			list.UnselectAll()
+			list.Refresh()
+			details.SetText("Выберите запись журнала, чтобы увидеть изменения")
+
+			count := fmt.Sprintf("Записей: %d", len(entries))
+			if len(entries) == auditLimit {
+				count += fmt.Sprintf(" (показаны последние %d)", auditLimit)
+			}
+			countLabel.SetText(count)
+		}, findBtn)
+	}
+	d.reloadAudit = reload
+	findBtn.OnTapped = reload
+
+	resetBtn := widget.NewButtonWithIcon("Сбросить", theme.ContentClearIcon(), func() {
+		tableSelect.SetSelected(anyTable)
+		operationSelect.SetSelected(anyOperation)
+		userEntry.SetText("")
+		idEntry.SetText("")
+		fromEntry.SetText("")
+		toEntry.SetText("")
+		reload()
+	})
+
+	filters := container.NewVBox(
+		titleLabel,
+		widget.NewSeparator(),
+		container.NewGridWithColumns(3, tableSelect, operationSelect, userEntry),
+		container.NewGridWithColumns(3, idEntry, fromEntry, toEntry),
+		container.NewBorder(nil, nil, nil, container.NewHBox(resetBtn, findBtn), countLabel),
+	)
+
+	split := container.NewVSplit(list, container.NewVScroll(details))
+	split.Offset = 0.6
+
+	reload()
+
+	return container.NewBorder(filters, nil, nil, nil, split)
+}
+
+// auditEntrySummary is the one-line view of an entry in the list
+func auditEntrySummary(e AuditEntry) string {
+	table := e.Table
+	for _, t := range auditTableTitles {
+		if t.Name == e.Table {
+			table = t.Title
+		}
+	}
+
+	summary := fmt.Sprintf("%s | %s | %s #%d | %s",
+		e.Time.Format("02.01.2006 15:04:05"), e.User, table, e.RecordID, auditOperationTitles[e.Operation])
+	if e.Operation == auditUpdate {
+		summary += ": " + strings.Join(auditChangedColumns(e), ", ")
+	}
+	return summary
+}
+
+// auditEntryDetails lists the field values before and after the write
+func auditEntryDetails(e AuditEntry) string {
+	var lines []string
+	lines = append(lines, auditEntrySummary(e), "")
+
+	switch e.Operation {
+	case auditInsert:
+		for _, column := range auditColumns(e.Table, e.After) {
+			lines = append(lines, fmt.Sprintf("%s: %s", column, e.After[column]))
+		}
+	case auditDelete:
+		for _, column := range auditColumns(e.Table, e.Before) {
+			lines = append(lines, fmt.Sprintf("%s: %s", column, e.Before[column]))
+		}
+	default:
+		for _, column := range auditChangedColumns(e) {
+			lines = append(lines, fmt.Sprintf("%s: %s → %s", column, e.Before[column], e.After[column]))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// auditChangedColumns returns the columns whose values differ, in table order
+func auditChangedColumns(e AuditEntry) []string {
+	var changed []string
+	for _, column := range auditColumns(e.Table, e.After) {
+		if e.Before[column] != e.After[column] {
+			changed = append(changed, column)
+		}
+	}
+	return changed
+}
+
+// auditColumns orders the columns of a record as in auditedTables, unknown ones last
+func auditColumns(table string, values map[string]string) []string {
+	var columns []string
+	known := make(map[string]bool)
+	for _, column := range auditedTables[table].columns {
+		known[column] = true
+		if _, ok := values[column]; ok {
+			columns = append(columns, column)
+		}
+	}
+
+	var unknown []string
+	for column := range values {
+		if !known[column] {
+			unknown = append(unknown, column)
+		}
+	}
+	sort.Strings(unknown)
+	return append(columns, unknown...)
+}
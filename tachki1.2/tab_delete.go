@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
@@ -36,26 +39,26 @@ func (d *DatabaseApp) createDeleteTab() *container.Scroll {
 		return nil
 	}
 
-	deleteBtn := widget.NewButtonWithIcon("Удалить запись", theme.DeleteIcon(), func() {
+	deleteBtn := widget.NewButtonWithIcon("Удалить запись", theme.DeleteIcon(), nil)
+	deleteBtn.Importance = widget.DangerImportance
+
+	var form *widget.Form
+	deleteSelected := func() {
 		if tableName, ok := tableMap[tableSelect.Selected]; ok {
-			d.deleteRecordHandler(tableSelect, idEntry, tableName)
+			d.deleteRecordHandler(tableSelect, idEntry, tableName, deleteBtn, form)
 		}
-	})
-	deleteBtn.Importance = widget.DangerImportance
+	}
+	deleteBtn.OnTapped = deleteSelected
 
-	warningLabel := widget.NewLabel("⚠️ Внимание:\nПри удалении владельца также удаляются все его автомобили (CASCADE).\nОперация необратима!")
+	warningLabel := widget.NewLabel("⚠️ Внимание:\nПри удалении владельца в корзину попадают и все его автомобили.\nЗаписи можно восстановить или удалить навсегда на вкладке «Корзина».")
 	warningLabel.Wrapping = fyne.TextWrapWord
 
-	form := &widget.Form{
+	form = &widget.Form{
 		Items: []*widget.FormItem{
 			widget.NewFormItem("Таблица:", tableSelect),
 			widget.NewFormItem("ID записи:", idEntry),
 		},
-		OnSubmit: func() {
-			if tableName, ok := tableMap[tableSelect.Selected]; ok {
-				d.deleteRecordHandler(tableSelect, idEntry, tableName)
-			}
-		},
+		OnSubmit:   deleteSelected,
 		SubmitText: "Удалить запись",
 	}
 
@@ -72,7 +75,7 @@ func (d *DatabaseApp) createDeleteTab() *container.Scroll {
 	return container.NewScroll(container.NewPadded(content))
 }
 
-func (d *DatabaseApp) deleteRecordHandler(tableSelect *widget.Select, idEntry *widget.Entry, tableName string) {
+func (d *DatabaseApp) deleteRecordHandler(tableSelect *widget.Select, idEntry *widget.Entry, tableName string, busy ...fyne.Disableable) {
 	if idEntry.Text == "" {
 		d.showMessage("Ошибка", "Введите ID записи")
 		return
@@ -84,11 +87,169 @@ func (d *DatabaseApp) deleteRecordHandler(tableSelect *widget.Select, idEntry *w
 		return
 	}
 
-	err = d.deleteRecord(tableName, id)
-	if err != nil {
-		d.showMessage("Ошибка", fmt.Sprintf("Не удалось удалить запись: %v", err))
-	} else {
-		d.showMessage("Успех", fmt.Sprintf("Запись с ID %d успешно удалена из таблицы %s", id, tableSelect.Selected))
+	// Владелец удаляется только после просмотра каскада и ввода фамилии
+	if tableName == "owners" {
+		d.confirmOwnerDelete(id, func() { idEntry.SetText("") })
+		return
+	}
+
+	tableTitle := tableSelect.Selected
+	d.runInBackground("Удаление записи", func(ctx context.Context) error {
+		return d.deleteRecord(ctx, tableName, id)
+	}, func(err error) {
+		if err != nil {
+			d.showMessage("Ошибка", fmt.Sprintf("Не удалось удалить запись: %v", err))
+			return
+		}
+		d.showMessage("Успех", fmt.Sprintf("Запись с ID %d из таблицы %s перемещена в корзину", id, tableTitle))
 		idEntry.SetText("")
+	}, busy...)
+}
+
+// confirmDelete asks before deleting one record; description names it in the question
+func (d *DatabaseApp) confirmDelete(tableName string, id int, description string, onDeleted func()) {
+	if tableName == "owners" {
+		d.confirmOwnerDelete(id, onDeleted)
+		return
 	}
+	message := fmt.Sprintf("Удалить %s (ID %d)?\nЗапись можно будет восстановить из корзины.", description, id)
+
+	dialog.ShowConfirm("Подтверждение удаления", message, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		d.runInBackground("Удаление записи", func(ctx context.Context) error {
+			return d.deleteRecord(ctx, tableName, id)
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Не удалось удалить запись: %v", err))
+				return
+			}
+			d.showMessage("Успех", fmt.Sprintf("Запись с ID %d перемещена в корзину", id))
+			if onDeleted != nil {
+				onDeleted()
+			}
+		})
+	}, d.window)
+}
+
+// confirmOwnerDelete lists the cars that go to the recycle bin with the owner
+// and deletes only after the owner's surname is typed
+func (d *DatabaseApp) confirmOwnerDelete(id int, onDeleted func()) {
+	var owner *Owner
+	var cars []Car
+	brandNames := make(map[int]string)
+
+	var stage string
+	d.runInBackground("Загрузка владельца", func(ctx context.Context) error {
+		var err error
+		stage = "Не удалось найти владельца"
+		if owner, err = d.repository().GetOwnerByID(ctx, id); err != nil {
+			return err
+		}
+
+		stage = "Не удалось получить автомобили владельца"
+		if cars, err = d.repository().GetCarsByOwner(ctx, id); err != nil {
+			return err
+		}
+
+		stage = "Ошибка получения брендов"
+		brands, err := d.repository().GetCarBrands(ctx)
+		if err != nil {
+			return err
+		}
+		for _, b := range brands {
+			brandNames[b.ID] = b.Name
+		}
+		return nil
+	}, func(err error) {
+		if err != nil {
+			d.showMessage("Ошибка", fmt.Sprintf("%s: %v", stage, err))
+			return
+		}
+		d.showOwnerDeleteDialog(owner, cars, brandNames, onDeleted)
+	})
+}
+
+// showOwnerDeleteDialog shows the cascade of an owner's deletion and deletes
+// once the surname is confirmed
+func (d *DatabaseApp) showOwnerDeleteDialog(owner *Owner, cars []Car, brandNames map[int]string, onDeleted func()) {
+	id := owner.ID
+
+	// Что удалится каскадом
+	carList := container.NewVBox()
+	var totalValue float64
+	for _, car := range cars {
+		totalValue += car.CurrentPrice
+		vin := car.VIN
+		if vin == "" {
+			vin = "-"
+		}
+		carList.Add(widget.NewLabel(fmt.Sprintf("#%d %s %s | VIN: %s | Тек. цена: %.0f",
+			car.ID, brandNames[car.BrandID], car.Model, vin, car.CurrentPrice)))
+	}
+	if len(cars) == 0 {
+		carList.Add(widget.NewLabel("У владельца нет автомобилей"))
+	}
+
+	header := widget.NewLabelWithStyle(fmt.Sprintf("Владелец: %s %s (ID %d)", owner.FirstName, owner.LastName, owner.ID),
+		fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	totals := widget.NewLabelWithStyle(fmt.Sprintf("Вместе с владельцем будут удалены автомобили: %d на сумму %.0f",
+		len(cars), totalValue), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	totals.Wrapping = fyne.TextWrapWord
+
+	surnameEntry := widget.NewEntry()
+	surnameEntry.SetPlaceHolder(owner.LastName)
+
+	var confirmDialog *dialog.CustomDialog
+
+	deleteBtn := widget.NewButtonWithIcon("Удалить", theme.DeleteIcon(), func() {
+		confirmDialog.Hide()
+
+		d.runInBackground("Удаление владельца", func(ctx context.Context) error {
+			return d.deleteRecord(ctx, "owners", id)
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Не удалось удалить запись: %v", err))
+				return
+			}
+			d.showMessage("Успех", fmt.Sprintf("Владелец %s %s и автомобили (%d) перемещены в корзину",
+				owner.FirstName, owner.LastName, len(cars)))
+			if onDeleted != nil {
+				onDeleted()
+			}
+		})
+	})
+	deleteBtn.Importance = widget.DangerImportance
+	deleteBtn.Disable()
+
+	surnameEntry.OnChanged = func(text string) {
+		if strings.EqualFold(strings.TrimSpace(text), strings.TrimSpace(owner.LastName)) {
+			deleteBtn.Enable()
+		} else {
+			deleteBtn.Disable()
+		}
+	}
+
+	cancelBtn := widget.NewButton("Отмена", func() { confirmDialog.Hide() })
+
+	content := container.NewBorder(
+		header,
+		container.NewVBox(
+			widget.NewSeparator(),
+			totals,
+			widget.NewLabel("Записи можно будет восстановить из корзины."),
+			widget.NewLabel(fmt.Sprintf("Для подтверждения введите фамилию владельца (%s):", owner.LastName)),
+			surnameEntry,
+		),
+		nil, nil,
+		container.NewVScroll(carList),
+	)
+
+	confirmDialog = dialog.NewCustomWithoutButtons("Подтверждение удаления владельца", content, d.window)
+	confirmDialog.SetButtons([]fyne.CanvasObject{cancelBtn, deleteBtn})
+	confirmDialog.Resize(fyne.NewSize(650, 500))
+	confirmDialog.Show()
+	d.window.Canvas().Focus(surnameEntry)
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestDeleteRecordHandler(t *testing.T) {
+	repo := newMemoryRepository()
+	d := newTestApp(t, repo)
+	ctx := context.Background()
+	ownerID, err := repo.AddOwner(ctx, "Иван", "Петров", "", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	carID, err := repo.AddCar(ctx, ownerID, 1, "Camry", 2020, "", "JT2BF22K1W0123456", 25000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table := widget.NewSelect([]string{"Автомобили"}, nil)
+	table.SetSelected("Автомобили")
+	id := widget.NewEntry()
+	btn := widget.NewButton("Удалить", nil)
+
+	// Нечисловой ID не доходит до базы
+	id.SetText("abc")
+	d.deleteRecordHandler(table, id, "cars", btn)
+	if _, err := repo.GetCarByID(ctx, carID); err != nil {
+		t.Fatalf("автомобиль удалён по неверному ID: %v", err)
+	}
+
+	id.SetText("1")
+	d.deleteRecordHandler(table, id, "cars", btn)
+	waitBackground(t, d, btn)
+	if id.Text != "" {
+		t.Error("ID не очищен после удаления")
+	}
+
+	deleted, err := repo.GetDeleted(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || deleted[0].Table != "cars" || deleted[0].ID != carID {
+		t.Fatalf("корзина: %+v", deleted)
+	}
+}
+
+// TestRecordActions checks the table dispatch of delete, restore and purge
+func TestRecordActions(t *testing.T) {
+	repo := newMemoryRepository()
+	d := newTestApp(t, repo)
+	ctx := context.Background()
+	ownerID, err := repo.AddOwner(ctx, "Иван", "Петров", "", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.deleteRecord(ctx, "owners", ownerID); err != nil {
+		t.Fatal(err)
+	}
+	if n := countOwners(t, repo); n != 0 {
+		t.Fatalf("после удаления владельцев %d", n)
+	}
+	if err := d.restoreRecord(ctx, "owners", ownerID); err != nil {
+		t.Fatal(err)
+	}
+	if n := countOwners(t, repo); n != 1 {
+		t.Fatalf("после восстановления владельцев %d", n)
+	}
+
+	if err := d.deleteRecord(ctx, "owners", ownerID); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.purgeRecord(ctx, "owners", ownerID); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.restoreRecord(ctx, "owners", ownerID); !errors.Is(err, errNotInRecycleBin) {
+		t.Errorf("восстановление окончательно удалённого владельца: %v", err)
+	}
+
+	for _, action := range []func(context.Context, string, int) error{d.deleteRecord, d.restoreRecord, d.purgeRecord} {
+		if err := action(ctx, "car_brands", 1); err == nil {
+			t.Error("действие с неизвестной таблицей выполнено")
+		}
+	}
+}
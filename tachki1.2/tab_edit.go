@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -55,13 +57,23 @@ func (d *DatabaseApp) createEditTab() *container.Scroll {
 	)
 
 	searchBtn.OnTapped = func() {
-		d.searchRecordHandlerWithContainers(tableSelect, idEntry, resultContainer, editContainer)
+		d.searchRecordHandlerWithContainers(tableSelect, idEntry, resultContainer, editContainer, searchBtn)
+	}
+
+	d.openEditForm = func(tableName string, id int) {
+		if tableName == "cars" {
+			tableSelect.SetSelected("Автомобили")
+		} else {
+			tableSelect.SetSelected("Владельцы")
+		}
+		idEntry.SetText(strconv.Itoa(id))
+		d.searchRecordHandlerWithContainers(tableSelect, idEntry, resultContainer, editContainer, searchBtn)
 	}
 
 	return container.NewScroll(container.NewPadded(contentWrapper))
 }
 
-func (d *DatabaseApp) searchRecordHandlerWithContainers(tableSelect *widget.Select, idEntry *widget.Entry, resultContainer, editContainer *fyne.Container) {
+func (d *DatabaseApp) searchRecordHandlerWithContainers(tableSelect *widget.Select, idEntry *widget.Entry, resultContainer, editContainer *fyne.Container, busy ...fyne.Disableable) {
 	if idEntry.Text == "" {
 		d.showMessage("Ошибка", "Введите ID записи")
 		return
@@ -85,25 +97,48 @@ func (d *DatabaseApp) searchRecordHandlerWithContainers(tableSelect *widget.Sele
 		return
 	}
 
-	resultContainer.Objects = nil
-	editContainer.Objects = nil
-
+	// Запись загружается в фоне, форма строится по готовым данным
 	if tableName == "owners" {
-		d.handleOwnerEdit(id, resultContainer, editContainer)
+		d.handleOwnerEdit(id, resultContainer, editContainer, busy...)
 	} else if tableName == "cars" {
-		d.handleCarEdit(id, resultContainer, editContainer)
+		d.handleCarEdit(id, resultContainer, editContainer, busy...)
 	}
+}
 
+// clearEditContainers prepares the result and edit areas for a new form
+func clearEditContainers(resultContainer, editContainer *fyne.Container) {
+	resultContainer.Objects = nil
+	editContainer.Objects = nil
 	resultContainer.Refresh()
 	editContainer.Refresh()
 }
 
-func (d *DatabaseApp) handleOwnerEdit(id int, resultContainer, editContainer *fyne.Container) {
-	owner, err := d.searchOwnerByID(id)
-	if err != nil {
-		d.showMessage("Ошибка", fmt.Sprintf("Не удалось найти владельца: %v", err))
-		return
-	}
+func (d *DatabaseApp) handleOwnerEdit(id int, resultContainer, editContainer *fyne.Container, busy ...fyne.Disableable) {
+	var owner *Owner
+	var categories []DriverCategory
+
+	var stage string
+	d.runInBackground("Загрузка владельца", func(ctx context.Context) error {
+		var err error
+		stage = "Не удалось найти владельца"
+		if owner, err = d.repository().GetOwnerByID(ctx, id); err != nil {
+			return err
+		}
+		stage = "Ошибка получения категорий"
+		categories, err = d.repository().GetDriverCategories(ctx)
+		return err
+	}, func(err error) {
+		clearEditContainers(resultContainer, editContainer)
+		if err != nil {
+			d.showMessage("Ошибка", fmt.Sprintf("%s: %v", stage, err))
+			return
+		}
+		d.showOwnerEditForm(id, owner, categories, resultContainer, editContainer)
+	}, busy...)
+}
+
+// showOwnerEditForm fills the edit tab with the loaded owner
+func (d *DatabaseApp) showOwnerEditForm(id int, owner *Owner, categories []DriverCategory, resultContainer, editContainer *fyne.Container) {
 
 	// Show Search Results
 	resultContainer.Add(widget.NewLabel(fmt.Sprintf("Найден владелец: %s %s", owner.FirstName, owner.LastName)))
@@ -122,12 +157,6 @@ func (d *DatabaseApp) handleOwnerEdit(id int, resultContainer, editContainer *fy
 	emailEdit := widget.NewEntry()
 	emailEdit.SetText(owner.Email)
 
-	categories, err := d.getDriverCategories()
-	if err != nil {
-		d.showMessage("Ошибка", fmt.Sprintf("Ошибка получения категорий: %v", err))
-		return
-	}
-
 	categoryOptions := make([]string, 0, len(categories))
 	for _, cat := range categories {
 		option := fmt.Sprintf("%s - %s", cat.Code, cat.Name)
@@ -150,7 +179,10 @@ func (d *DatabaseApp) handleOwnerEdit(id int, resultContainer, editContainer *fy
 	versionLabel := widget.NewLabel(fmt.Sprintf("Версия: %x", owner.RowVersion))
 	versionLabel.Hidden = true
 
-	updateBtn := widget.NewButton("Обновить владельца", func() {
+	updateBtn := widget.NewButton("Обновить владельца", nil)
+	refreshBtn := widget.NewButton("Обновить данные", nil)
+
+	updateBtn.OnTapped = func() {
 		var categoryID int
 		for _, cat := range categories {
 			option := fmt.Sprintf("%s - %s", cat.Code, cat.Name)
@@ -160,52 +192,67 @@ func (d *DatabaseApp) handleOwnerEdit(id int, resultContainer, editContainer *fy
 			}
 		}
 
-		err := d.updateOwner(id, firstNameEdit.Text, lastNameEdit.Text, phoneEdit.Text, emailEdit.Text, categoryID, owner.RowVersion)
-		if err != nil {
-			if err.Error() == "запись была изменена другим пользователем" {
-				d.showMessage("Конфликт редактирования", "Запись была изменена другим пользователем. Пожалуйста, обновите данные и попробуйте снова.")
-			} else {
-				d.showMessage("Ошибка", fmt.Sprintf("Не удалось обновить владельца: %v", err))
+		firstName, lastName, phone, email := firstNameEdit.Text, lastNameEdit.Text, phoneEdit.Text, emailEdit.Text
+		var updatedOwner *Owner
+		d.runInBackground("Сохранение владельца", func(ctx context.Context) error {
+			err := d.repository().UpdateOwner(ctx, id, firstName, lastName, phone, email, categoryID, owner.RowVersion)
+			if err == nil {
+				// Новая версия строки нужна для следующего сохранения
+				updatedOwner, _ = d.repository().GetOwnerByID(ctx, id)
+			}
+			return err
+		}, func(err error) {
+			if err != nil {
+				if errors.Is(err, errConcurrentUpdate) {
+					d.showMessage("Конфликт редактирования", "Запись была изменена другим пользователем. Пожалуйста, обновите данные и попробуйте снова.")
+				} else {
+					d.showMessage("Ошибка", fmt.Sprintf("Не удалось обновить владельца: %v", err))
+				}
+				return
 			}
-		} else {
 			d.showMessage("Успех", "Владелец успешно обновлен")
-			updatedOwner, err := d.searchOwnerByID(id)
-			if err == nil {
+			if updatedOwner != nil {
 				owner.RowVersion = updatedOwner.RowVersion
 				versionLabel.SetText(fmt.Sprintf("Версия: %x", owner.RowVersion))
 				resultContainer.Objects[4].(*widget.Label).SetText(fmt.Sprintf("Версия записи: обновлена %s", time.Now().Format("15:04:05")))
 				resultContainer.Refresh()
 			}
-		}
-	})
+		}, updateBtn, refreshBtn)
+	}
 
-	refreshBtn := widget.NewButton("Обновить данные", func() {
-		updatedOwner, err := d.searchOwnerByID(id)
-		if err != nil {
-			d.showMessage("Ошибка", fmt.Sprintf("Не удалось обновить данные: %v", err))
-			return
-		}
-		firstNameEdit.SetText(updatedOwner.FirstName)
-		lastNameEdit.SetText(updatedOwner.LastName)
-		phoneEdit.SetText(updatedOwner.Phone)
-		emailEdit.SetText(updatedOwner.Email)
-		for _, cat := range categories {
-			if cat.Code == updatedOwner.Category {
-				categorySelect.SetSelected(fmt.Sprintf("%s - %s", cat.Code, cat.Name))
-				break
+	refreshBtn.OnTapped = func() {
+		var updatedOwner *Owner
+		d.runInBackground("Загрузка владельца", func(ctx context.Context) error {
+			var err error
+			updatedOwner, err = d.repository().GetOwnerByID(ctx, id)
+			return err
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Не удалось обновить данные: %v", err))
+				return
 			}
-		}
-		owner.RowVersion = updatedOwner.RowVersion
-		versionLabel.SetText(fmt.Sprintf("Версия: %x", owner.RowVersion))
-
-		resultContainer.Objects[0].(*widget.Label).SetText(fmt.Sprintf("Найден владелец: %s %s", updatedOwner.FirstName, updatedOwner.LastName))
-		resultContainer.Objects[1].(*widget.Label).SetText(fmt.Sprintf("Телефон: %s", updatedOwner.Phone))
-		resultContainer.Objects[2].(*widget.Label).SetText(fmt.Sprintf("Email: %s", updatedOwner.Email))
-		resultContainer.Objects[3].(*widget.Label).SetText(fmt.Sprintf("Категория прав: %s", updatedOwner.Category))
-		resultContainer.Objects[4].(*widget.Label).SetText(fmt.Sprintf("Версия записи: обновлена %s", time.Now().Format("15:04:05")))
-		resultContainer.Refresh()
-		d.showMessage("Успех", "Данные успешно обновлены")
-	})
+			firstNameEdit.SetText(updatedOwner.FirstName)
+			lastNameEdit.SetText(updatedOwner.LastName)
+			phoneEdit.SetText(updatedOwner.Phone)
+			emailEdit.SetText(updatedOwner.Email)
+			for _, cat := range categories {
+				if cat.Code == updatedOwner.Category {
+					categorySelect.SetSelected(fmt.Sprintf("%s - %s", cat.Code, cat.Name))
+					break
+				}
+			}
+			owner.RowVersion = updatedOwner.RowVersion
+			versionLabel.SetText(fmt.Sprintf("Версия: %x", owner.RowVersion))
+
+			resultContainer.Objects[0].(*widget.Label).SetText(fmt.Sprintf("Найден владелец: %s %s", updatedOwner.FirstName, updatedOwner.LastName))
+			resultContainer.Objects[1].(*widget.Label).SetText(fmt.Sprintf("Телефон: %s", updatedOwner.Phone))
+			resultContainer.Objects[2].(*widget.Label).SetText(fmt.Sprintf("Email: %s", updatedOwner.Email))
+			resultContainer.Objects[3].(*widget.Label).SetText(fmt.Sprintf("Категория прав: %s", updatedOwner.Category))
+			resultContainer.Objects[4].(*widget.Label).SetText(fmt.Sprintf("Версия записи: обновлена %s", time.Now().Format("15:04:05")))
+			resultContainer.Refresh()
+			d.showMessage("Успех", "Данные успешно обновлены")
+		}, updateBtn, refreshBtn)
+	}
 
 	editContainer.Add(widget.NewLabel("Редактирование владельца:"))
 	editContainer.Add(widget.NewLabel("Имя:"))
@@ -220,35 +267,50 @@ func (d *DatabaseApp) handleOwnerEdit(id int, resultContainer, editContainer *fy
 	editContainer.Add(categorySelect)
 	editContainer.Add(versionLabel)
 	editContainer.Add(container.NewHBox(updateBtn, refreshBtn))
+
+	resultContainer.Refresh()
+	editContainer.Refresh()
 }
 
-func (d *DatabaseApp) handleCarEdit(id int, resultContainer, editContainer *fyne.Container) {
-	// 1. Получаем данные автомобиля
-	car, err := d.searchCarByID(id)
-	if err != nil {
-		d.showMessage("Ошибка", fmt.Sprintf("Не удалось найти автомобиль: %v", err))
-		return
-	}
+func (d *DatabaseApp) handleCarEdit(id int, resultContainer, editContainer *fyne.Container, busy ...fyne.Disableable) {
+	var car *Car
+	var owners []Owner
+	var brands []CarBrand
+
+	var stage string
+	d.runInBackground("Загрузка автомобиля", func(ctx context.Context) error {
+		var err error
+		// 1. Получаем данные автомобиля
+		stage = "Не удалось найти автомобиль"
+		if car, err = d.repository().GetCarByID(ctx, id); err != nil {
+			return err
+		}
 
-	// 2. Очищаем контейнер результатов (по просьбе не выводим характеристики отдельно)
-	resultContainer.Objects = nil
-	// Можно оставить только заголовок, чтобы подтвердить, что поиск прошел успешно
+		// 2. Получаем списки для выпадающих меню
+		stage = "Ошибка получения владельцев"
+		if owners, err = d.repository().GetOwners(ctx); err != nil {
+			return err
+		}
+		stage = "Ошибка получения брендов"
+		brands, err = d.repository().GetCarBrands(ctx)
+		return err
+	}, func(err error) {
+		clearEditContainers(resultContainer, editContainer)
+		if err != nil {
+			d.showMessage("Ошибка", fmt.Sprintf("%s: %v", stage, err))
+			return
+		}
+		d.showCarEditForm(id, car, owners, brands, resultContainer, editContainer)
+	}, busy...)
+}
+
+// showCarEditForm fills the edit tab with the loaded car
+func (d *DatabaseApp) showCarEditForm(id int, car *Car, owners []Owner, brands []CarBrand, resultContainer, editContainer *fyne.Container) {
+	// 3. Заголовок и история владения (характеристики отдельно не выводим)
 	resultContainer.Add(widget.NewLabelWithStyle(fmt.Sprintf("Редактирование записи #%d", car.ID), fyne.TextAlignCenter, fyne.TextStyle{Bold: true}))
+	resultContainer.Add(d.ownershipHistoryView(car.ID))
 	resultContainer.Refresh()
 
-	// 3. Получаем списки для выпадающих меню
-	owners, err := d.getOwners()
-	if err != nil {
-		d.showMessage("Ошибка", fmt.Sprintf("Ошибка получения владельцев: %v", err))
-		return
-	}
-
-	brands, err := d.getCarBrands()
-	if err != nil {
-		d.showMessage("Ошибка", fmt.Sprintf("Ошибка получения брендов: %v", err))
-		return
-	}
-
 	// 4. Подготавливаем виджеты редактирования
 	ownerOptions := make([]string, 0, len(owners))
 	for _, owner := range owners {
@@ -261,7 +323,9 @@ func (d *DatabaseApp) handleCarEdit(id int, resultContainer, editContainer *fyne
 		brandOptions = append(brandOptions, brand.Name)
 	}
 
+	// Владелец меняется только через передачу, чтобы сохранялась история
 	ownerSelect := widget.NewSelect(ownerOptions, nil)
+	ownerSelect.Disable()
 	brandSelect := widget.NewSelect(brandOptions, nil)
 
 	// Установка текущего владельца
@@ -316,17 +380,13 @@ func (d *DatabaseApp) handleCarEdit(id int, resultContainer, editContainer *fyne
 	versionLabel := widget.NewLabel(fmt.Sprintf("Версия: %x", car.RowVersion))
 	versionLabel.Hidden = true
 
+	updateBtn := widget.NewButton("Сохранить изменения", nil)
+	refreshBtn := widget.NewButton("Сбросить", nil)
+
 	// 5. Логика кнопки "Обновить автомобиль"
-	updateBtn := widget.NewButton("Сохранить изменения", func() {
-		// Ищем ID владельца
-		var ownerID int
-		for _, owner := range owners {
-			option := fmt.Sprintf("%d: %s %s", owner.ID, owner.FirstName, owner.LastName)
-			if option == ownerSelect.Selected {
-				ownerID = owner.ID
-				break
-			}
-		}
+	updateBtn.OnTapped = func() {
+		// Владелец меняется только кнопкой «Передать…»
+		ownerID := car.OwnerID
 
 		// Ищем ID бренда
 		var brandID int
@@ -339,21 +399,29 @@ func (d *DatabaseApp) handleCarEdit(id int, resultContainer, editContainer *fyne
 
 		year, _ := strconv.Atoi(yearEdit.Text)
 		price, _ := strconv.ParseFloat(priceEdit.Text, 64)
+		model, color, vin := modelEdit.Text, colorEdit.Text, vinEdit.Text
 
 		// Отправляем запрос в БД
-		err := d.updateCar(id, ownerID, brandID, modelEdit.Text, year, colorEdit.Text, vinEdit.Text, price, car.RowVersion)
-		if err != nil {
-			if err.Error() == "запись была изменена другим пользователем" {
-				d.showMessage("Конфликт редактирования", "Запись была изменена другим пользователем. Пожалуйста, обновите данные.")
-			} else {
-				d.showMessage("Ошибка", fmt.Sprintf("Не удалось обновить автомобиль: %v", err))
+		var updatedCar *Car
+		d.runInBackground("Сохранение автомобиля", func(ctx context.Context) error {
+			err := d.repository().UpdateCar(ctx, id, ownerID, brandID, model, year, color, vin, price, car.RowVersion)
+			if err == nil {
+				// Получаем обновленные данные (включая пересчитанную CurrentPrice)
+				updatedCar, _ = d.repository().GetCarByID(ctx, id)
+			}
+			return err
+		}, func(err error) {
+			if err != nil {
+				if errors.Is(err, errConcurrentUpdate) {
+					d.showMessage("Конфликт редактирования", "Запись была изменена другим пользователем. Пожалуйста, обновите данные.")
+				} else {
+					d.showMessage("Ошибка", fmt.Sprintf("Не удалось обновить автомобиль: %v", err))
+				}
+				return
 			}
-		} else {
 			d.showMessage("Успех", "Автомобиль успешно обновлен")
 
-			// Получаем обновленные данные (включая пересчитанную CurrentPrice)
-			updatedCar, err := d.searchCarByID(id)
-			if err == nil {
+			if updatedCar != nil {
 				car = updatedCar // Обновляем локальную переменную
 				versionLabel.SetText(fmt.Sprintf("Версия: %x", car.RowVersion))
 
@@ -361,50 +429,65 @@ func (d *DatabaseApp) handleCarEdit(id int, resultContainer, editContainer *fyne
 				currentPriceLabel.SetText(fmt.Sprintf("Текущая рыночная цена (~): %.0f", car.CurrentPrice))
 				currentPriceLabel.Refresh()
 			}
-		}
-	})
+		}, updateBtn, refreshBtn)
+	}
 
 	// 6. Логика кнопки "Сбросить / Обновить"
-	refreshBtn := widget.NewButton("Сбросить", func() {
-		updatedCar, err := d.searchCarByID(id)
-		if err != nil {
-			d.showMessage("Ошибка", fmt.Sprintf("Не удалось обновить данные: %v", err))
-			return
-		}
+	refreshBtn.OnTapped = func() {
+		var updatedCar *Car
+		d.runInBackground("Загрузка автомобиля", func(ctx context.Context) error {
+			var err error
+			updatedCar, err = d.repository().GetCarByID(ctx, id)
+			return err
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Не удалось обновить данные: %v", err))
+				return
+			}
 
-		// Обновляем поля ввода
-		modelEdit.SetText(updatedCar.Model)
-		yearEdit.SetText(strconv.Itoa(updatedCar.Year))
-		colorEdit.SetText(updatedCar.Color)
-		vinEdit.SetText(updatedCar.VIN)
-		priceEdit.SetText(fmt.Sprintf("%.0f", updatedCar.Price))
-
-		// Обновляем селекторы
-		for _, owner := range owners {
-			if owner.ID == updatedCar.OwnerID {
-				ownerSelect.SetSelected(fmt.Sprintf("%d: %s %s", owner.ID, owner.FirstName, owner.LastName))
-				break
+			// Обновляем поля ввода
+			modelEdit.SetText(updatedCar.Model)
+			yearEdit.SetText(strconv.Itoa(updatedCar.Year))
+			colorEdit.SetText(updatedCar.Color)
+			vinEdit.SetText(updatedCar.VIN)
+			priceEdit.SetText(fmt.Sprintf("%.0f", updatedCar.Price))
+
+			// Обновляем селекторы
+			for _, owner := range owners {
+				if owner.ID == updatedCar.OwnerID {
+					ownerSelect.SetSelected(fmt.Sprintf("%d: %s %s", owner.ID, owner.FirstName, owner.LastName))
+					break
+				}
 			}
-		}
-		for _, brand := range brands {
-			if brand.ID == updatedCar.BrandID {
-				brandSelect.SetSelected(brand.Name)
-				break
+			for _, brand := range brands {
+				if brand.ID == updatedCar.BrandID {
+					brandSelect.SetSelected(brand.Name)
+					break
+				}
 			}
-		}
 
-		car = updatedCar // Обновляем ссылку
-		versionLabel.SetText(fmt.Sprintf("Версия: %x", car.RowVersion))
+			car = updatedCar // Обновляем ссылку
+			versionLabel.SetText(fmt.Sprintf("Версия: %x", car.RowVersion))
 
-		// Обновляем метку с текущей ценой
-		currentPriceLabel.SetText(fmt.Sprintf("Текущая рыночная цена (~): %.0f", updatedCar.CurrentPrice))
-		d.showMessage("Успех", "Данные формы сброшены к значениям из БД")
+			// Обновляем метку с текущей ценой
+			currentPriceLabel.SetText(fmt.Sprintf("Текущая рыночная цена (~): %.0f", updatedCar.CurrentPrice))
+			d.showMessage("Успех", "Данные формы сброшены к значениям из БД")
+		}, updateBtn, refreshBtn)
+	}
+
+	// 7. Передача другому владельцу — с записью в ownership_history
+	transferBtn := widget.NewButton("Передать…", func() {
+		d.showTransferDialog(car, func() {
+			if d.openEditForm != nil {
+				d.openEditForm("cars", id)
+			}
+		})
 	})
 
-	// 7. Сборка формы
+	// 8. Сборка формы
 	// Используем FormLayout или просто добавляем метки и поля последовательно
 	editContainer.Add(widget.NewLabel("Владелец:"))
-	editContainer.Add(ownerSelect)
+	editContainer.Add(container.NewBorder(nil, nil, nil, transferBtn, ownerSelect))
 
 	editContainer.Add(widget.NewLabel("Марка:"))
 	editContainer.Add(brandSelect)
@@ -431,4 +514,7 @@ func (d *DatabaseApp) handleCarEdit(id int, resultContainer, editContainer *fyne
 
 	editContainer.Add(layout.NewSpacer())
 	editContainer.Add(container.NewHBox(updateBtn, refreshBtn))
+
+	resultContainer.Refresh()
+	editContainer.Refresh()
 }
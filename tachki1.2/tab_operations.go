@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
@@ -27,7 +28,8 @@ func (d *DatabaseApp) createOperationsTab() *container.Scroll {
 	percentEntry.PlaceHolder = "Процент (например: 10 или -5)"
 
 	// Кнопка выполнения
-	execBtn := widget.NewButtonWithIcon("Применить индексацию", theme.MediaPlayIcon(), func() {
+	var execBtn *widget.Button
+	execBtn = widget.NewButtonWithIcon("Применить индексацию", theme.MediaPlayIcon(), func() {
 		if brandSelect.Selected == "" {
 			d.showMessage("Ошибка", "Выберите марку автомобиля")
 			return
@@ -44,42 +46,58 @@ func (d *DatabaseApp) createOperationsTab() *container.Scroll {
 			return
 		}
 
-		// Получаем ID бренда (нужно снова найти ID по имени)
-		brands, _ := d.getCarBrands() // В реальном коде лучше кэшировать или хранить map
-		var brandID int
-		for _, b := range brands {
-			if b.Name == brandSelect.Selected {
-				brandID = b.ID
-				break
+		brandName := brandSelect.Selected
+
+		// Процедура может выполняться долго — в фоне, с возможностью отмены
+		d.runWithProgress("Индексация цен", func(ctx context.Context) error {
+			// Получаем ID бренда (нужно снова найти ID по имени)
+			brands, err := d.repository().GetCarBrands(ctx) // В реальном коде лучше кэшировать или хранить map
+			if err != nil {
+				return err
+			}
+			var brandID int
+			for _, b := range brands {
+				if b.Name == brandName {
+					brandID = b.ID
+					break
+				}
 			}
-		}
 
-		// Вызов процедуры
-		err = d.massPriceUpdate(brandID, percent)
-		if err != nil {
-			d.showMessage("Ошибка", fmt.Sprintf("Сбой процедуры: %v", err))
-		} else {
-			d.showMessage("Успех", fmt.Sprintf("Цены для %s успешно изменены на %.1f%%!", brandSelect.Selected, percent))
-		}
+			// Вызов процедуры
+			return d.repository().MassPriceUpdate(ctx, brandID, percent)
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Сбой процедуры: %v", err))
+			} else {
+				d.showMessage("Успех", fmt.Sprintf("Цены для %s успешно изменены на %.1f%%!", brandName, percent))
+			}
+		}, execBtn)
 	})
 	execBtn.Importance = widget.WarningImportance // Оранжевая кнопка (опасно!)
 
-	// Функция обновления списка брендов
+	// Кнопка обновления списка
+	refreshBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), nil)
+
+	// Функция обновления списка брендов (в фоне)
 	updateBrands := func() {
-		brands, err := d.getCarBrands()
-		if err != nil {
-			return
-		}
-		options := make([]string, 0, len(brands))
-		for _, b := range brands {
-			options = append(options, b.Name)
-		}
-		brandSelect.Options = options
-		brandSelect.Refresh()
+		var brands []CarBrand
+		d.runInBackground("Загрузка марок", func(ctx context.Context) error {
+			var err error
+			brands, err = d.repository().GetCarBrands(ctx)
+			return err
+		}, func(err error) {
+			if err != nil {
+				return
+			}
+			options := make([]string, 0, len(brands))
+			for _, b := range brands {
+				options = append(options, b.Name)
+			}
+			brandSelect.Options = options
+			brandSelect.Refresh()
+		}, brandSelect, refreshBtn)
 	}
-
-	// Кнопка обновления списка
-	refreshBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), updateBrands)
+	refreshBtn.OnTapped = updateBrands
 
 	// Начальная загрузка
 	updateBrands()
@@ -2,6 +2,8 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
@@ -225,38 +227,45 @@ func (d *DatabaseApp) createPaintTab() *container.Scroll {
 	)
 
 	// 5. Кнопки действий
-	loadBtn := widget.NewButtonWithIcon("Загрузить из БД", theme.DownloadIcon(), func() {
+	var loadBtn, saveBtn *widget.Button
+	listed := map[string]CarBrand{} // марки списка по названию
+	var edited *CarBrand            // марка, логотип которой загружен в редактор
+	loadBtn = widget.NewButtonWithIcon("Загрузить из БД", theme.DownloadIcon(), func() {
 		if brandSelect.Selected == "" {
 			d.showMessage("Ошибка", "Выберите бренд из списка")
 			return
 		}
 
-		brands, _ := d.getCarBrands()
-		var brandID int
-		for _, b := range brands {
-			if b.Name == brandSelect.Selected {
-				brandID = b.ID
-				break
-			}
-		}
-
-		imgData, err := d.getBrandImage(brandID)
-		if err != nil {
-			d.showMessage("Ошибка БД", fmt.Sprintf("%v", err))
+		brand, ok := listed[brandSelect.Selected]
+		if !ok {
+			d.showMessage("Ошибка", "Марка не найдена, обновите список")
 			return
 		}
+		var loaded *CarBrand
+		d.runInBackground("Загрузка логотипа", func(ctx context.Context) error {
+			var err error
+			loaded, err = d.repository().GetCarBrand(ctx, brand.ID)
+			return err
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка БД", fmt.Sprintf("%v", err))
+				return
+			}
+			// Версия строки, с которой начато редактирование, — для сохранения
+			edited = loaded
+			imgData := loaded.ImageData
+
+			if len(imgData) == 0 {
+				d.showMessage("Инфо", "Картинка в базе пустая, создан чистый лист.")
+			} else {
+				fmt.Printf("Загружено байт: %d\n", len(imgData))
+			}
 
-		if len(imgData) == 0 {
-			d.showMessage("Инфо", "Картинка в базе пустая, создан чистый лист.")
-		} else {
-			fmt.Printf("Загружено байт: %d\n", len(imgData))
-		}
-
-		// Загружаем (даже если пусто, создастся белый фон)
-		err = drawingArea.LoadImage(imgData)
-		if err != nil {
-			d.showMessage("Ошибка", "Не удалось прочитать формат картинки")
-		}
+			// Загружаем (даже если пусто, создастся белый фон)
+			if err := drawingArea.LoadImage(imgData); err != nil {
+				d.showMessage("Ошибка", "Не удалось прочитать формат картинки")
+			}
+		}, loadBtn, saveBtn)
 	})
 
 	importBtn := widget.NewButtonWithIcon("Импорт (ПК)", theme.FolderOpenIcon(), func() {
@@ -274,38 +283,80 @@ func (d *DatabaseApp) createPaintTab() *container.Scroll {
 		drawingArea.LoadImage(nil) // Сброс в белый
 	})
 
-	saveBtn := widget.NewButtonWithIcon("Сохранить в БД", theme.DocumentSaveIcon(), func() {
+	saveBtn = widget.NewButtonWithIcon("Сохранить в БД", theme.DocumentSaveIcon(), func() {
 		if brandSelect.Selected == "" {
 			d.showMessage("Ошибка", "Выберите бренд!")
 			return
 		}
 		data, _ := drawingArea.GetBytes()
 
-		brands, _ := d.getCarBrands()
-		for _, b := range brands {
-			if b.Name == brandSelect.Selected {
-				d.updateBrandImage(b.ID, data)
-				d.showMessage("Успех", "Логотип обновлен")
-				break
-			}
+		// Логотип сохраняется поверх той версии марки, что была загружена
+		// в редактор, а без загрузки — той, что была в списке
+		brand, ok := listed[brandSelect.Selected]
+		if !ok {
+			d.showMessage("Ошибка", "Марка не найдена, обновите список")
+			return
 		}
+		if edited != nil && edited.ID == brand.ID {
+			brand = *edited
+		}
+		var saved *CarBrand
+		d.runInBackground("Сохранение логотипа", func(ctx context.Context) error {
+			if err := d.repository().UpdateBrandImage(ctx, brand.ID, data, brand.RowVersion); err != nil {
+				return err
+			}
+			var err error
+			saved, err = d.repository().GetCarBrand(ctx, brand.ID)
+			return err
+		}, func(err error) {
+			if err != nil {
+				if errors.Is(err, errConcurrentUpdate) {
+					d.showMessage("Конфликт редактирования", "Логотип был изменён другим пользователем. Загрузите его из БД и повторите изменения.")
+				} else {
+					d.showMessage("Ошибка БД", fmt.Sprintf("%v", err))
+				}
+				return
+			}
+			edited = saved
+			d.showMessage("Успех", "Логотип обновлен")
+		}, loadBtn, saveBtn)
 	})
 	saveBtn.Importance = widget.HighImportance
 
-	// Обновление списка
-	updateBrands := func() {
-		brands, err := d.getCarBrands()
-		if err == nil {
-			opts := make([]string, 0)
-			for _, b := range brands {
-				opts = append(opts, b.Name)
+	refreshListBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), nil)
+
+	// Обновление списка (в фоне); then вызывается после загрузки
+	updateBrands := func(then func()) {
+		var brands []CarBrand
+		d.runInBackground("Загрузка марок", func(ctx context.Context) error {
+			var err error
+			brands, err = d.repository().GetCarBrands(ctx)
+			return err
+		}, func(err error) {
+			if err == nil {
+				opts := make([]string, 0)
+				listed = make(map[string]CarBrand, len(brands))
+				for _, b := range brands {
+					opts = append(opts, b.Name)
+					listed[b.Name] = b
+				}
+				brandSelect.Options = opts
+				brandSelect.Refresh()
 			}
-			brandSelect.Options = opts
-			brandSelect.Refresh()
-		}
+			if then != nil {
+				then()
+			}
+		}, brandSelect, refreshListBtn)
+	}
+	refreshListBtn.OnTapped = func() { updateBrands(nil) }
+	updateBrands(nil)
+
+	d.openBrandLogo = func(brandName string) {
+		updateBrands(func() {
+			brandSelect.SetSelected(brandName)
+			loadBtn.OnTapped()
+		})
 	}
-	refreshListBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), updateBrands)
-	updateBrands()
 
 	// 6. Компоновка
 	topControls := container.NewVBox(
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+func (d *DatabaseApp) createRecycleBinTab() fyne.CanvasObject {
+	titleLabel := widget.NewLabelWithStyle("Корзина", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+
+	infoLabel := widget.NewLabel("Удалённые владельцы и автомобили скрыты из остальных вкладок.\n" +
+		"Владелец восстанавливается вместе с автомобилями, удалёнными вместе с ним.")
+	infoLabel.Wrapping = fyne.TextWrapWord
+
+	content := container.NewVBox()
+
+	refreshBtn := widget.NewButtonWithIcon("Обновить", theme.ViewRefreshIcon(), nil)
+
+	var reload func()
+	reload = func() {
+		var records []DeletedRecord
+		d.runInBackground("Загрузка корзины", func(ctx context.Context) error {
+			var err error
+			records, err = d.repository().GetDeleted(ctx)
+			return err
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Не удалось загрузить корзину: %v", err))
+				return
+			}
+			content.Objects = d.recycleBinRows(records, reload)
+			content.Refresh()
+		}, refreshBtn)
+	}
+	d.reloadRecycleBin = reload
+	refreshBtn.OnTapped = reload
+
+	reload()
+
+	header := container.NewVBox(
+		titleLabel,
+		widget.NewSeparator(),
+		container.NewBorder(nil, nil, nil, refreshBtn, infoLabel),
+		widget.NewSeparator(),
+	)
+	return container.NewBorder(header, nil, nil, nil, container.NewVScroll(content))
+}
+
+// recycleBinRows groups the deleted cars under their deleted owners; reload rebuilds the tab
+func (d *DatabaseApp) recycleBinRows(records []DeletedRecord, reload func()) []fyne.CanvasObject {
+	deletedOwners := make(map[int]bool)
+	for _, rec := range records {
+		if rec.Table == "owners" {
+			deletedOwners[rec.ID] = true
+		}
+	}
+
+	var rows []fyne.CanvasObject
+	bold := fyne.TextStyle{Bold: true}
+
+	rows = append(rows, widget.NewLabelWithStyle("Владельцы:", fyne.TextAlignLeading, bold))
+	owners := 0
+	for _, rec := range records {
+		if rec.Table != "owners" {
+			continue
+		}
+		owners++
+		rows = append(rows, d.recycleBinRow(rec, fmt.Sprintf("👤 %s", rec.Title), true, reload))
+
+		// Автомобили удалённого владельца — под ним
+		for _, car := range records {
+			if car.Table == "cars" && car.OwnerID == rec.ID {
+				title := fmt.Sprintf("      🚗 %s", car.Title)
+				if car.WithOwner {
+					title += " — восстановится вместе с владельцем"
+				} else {
+					title += " — удалён отдельно, восстанавливается после владельца"
+				}
+				rows = append(rows, d.recycleBinRow(car, title, false, reload))
+			}
+		}
+		rows = append(rows, widget.NewSeparator())
+	}
+	if owners == 0 {
+		rows = append(rows, widget.NewLabel("Удалённых владельцев нет"))
+	}
+
+	rows = append(rows, widget.NewLabelWithStyle("Автомобили:", fyne.TextAlignLeading, bold))
+	cars := 0
+	for _, rec := range records {
+		if rec.Table == "cars" && !deletedOwners[rec.OwnerID] {
+			cars++
+			rows = append(rows, d.recycleBinRow(rec, fmt.Sprintf("🚗 %s", rec.Title), true, reload))
+		}
+	}
+	if cars == 0 {
+		rows = append(rows, widget.NewLabel("Удалённых автомобилей нет"))
+	}
+
+	return rows
+}
+
+// recycleBinRow shows one deleted record with restore and purge actions
+func (d *DatabaseApp) recycleBinRow(rec DeletedRecord, title string, canRestore bool, reload func()) fyne.CanvasObject {
+	label := widget.NewLabel(fmt.Sprintf("%s (ID %d) — удалён %s, %s",
+		title, rec.ID, rec.DeletedAt.Format("02.01.2006 15:04"), rec.DeletedBy))
+	label.Truncation = fyne.TextTruncateEllipsis
+
+	actions := []fyne.CanvasObject{layout.NewSpacer()}
+
+	if canRestore {
+		restoreBtn := widget.NewButtonWithIcon("Восстановить", theme.ContentUndoIcon(), nil)
+		restoreBtn.OnTapped = func() {
+			d.runInBackground("Восстановление записи", func(ctx context.Context) error {
+				return d.restoreRecord(ctx, rec.Table, rec.ID)
+			}, func(err error) {
+				if err != nil {
+					d.showMessage("Ошибка", fmt.Sprintf("Не удалось восстановить запись: %v", err))
+					return
+				}
+				d.showMessage("Успех", fmt.Sprintf("Запись с ID %d восстановлена", rec.ID))
+				reload()
+			}, restoreBtn)
+		}
+		actions = append(actions, restoreBtn)
+	}
+
+	var purgeBtn *widget.Button
+	purgeBtn = widget.NewButtonWithIcon("Удалить навсегда", theme.DeleteIcon(), func() {
+		message := fmt.Sprintf("Удалить %s (ID %d) навсегда?\nОперация необратима!", rec.Title, rec.ID)
+		if rec.Table == "owners" {
+			message = fmt.Sprintf("Удалить владельца %s (ID %d) и все его автомобили навсегда?\nОперация необратима!", rec.Title, rec.ID)
+		}
+		dialog.ShowConfirm("Окончательное удаление", message, func(ok bool) {
+			if !ok {
+				return
+			}
+
+			d.runInBackground("Окончательное удаление", func(ctx context.Context) error {
+				return d.purgeRecord(ctx, rec.Table, rec.ID)
+			}, func(err error) {
+				if err != nil {
+					d.showMessage("Ошибка", fmt.Sprintf("Не удалось удалить запись: %v", err))
+					return
+				}
+				d.showMessage("Успех", fmt.Sprintf("Запись с ID %d удалена навсегда", rec.ID))
+				reload()
+			}, purgeBtn)
+		}, d.window)
+	})
+	purgeBtn.Importance = widget.DangerImportance
+	actions = append(actions, purgeBtn)
+
+	return container.NewBorder(nil, nil, nil, container.NewHBox(actions...), label)
+}
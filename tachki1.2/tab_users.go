@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// roleOptions are the role titles of the role selects, in the order of privilege
+func roleOptions() []string {
+	options := make([]string, len(roles))
+	for i, role := range roles {
+		options[i] = roleTitles[role]
+	}
+	return options
+}
+
+// roleByTitle returns the role shown as title in a select
+func roleByTitle(title string) string {
+	for role, t := range roleTitles {
+		if t == title {
+			return role
+		}
+	}
+	return ""
+}
+
+func (d *DatabaseApp) createUsersTab() fyne.CanvasObject {
+	titleLabel := widget.NewLabelWithStyle("Пользователи приложения", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+
+	infoLabel := widget.NewLabel("Пользователь — имя входа в базу данных. Не внесённые в список пользователи " +
+		"только просматривают данные.\nОператор добавляет и изменяет владельцев и автомобили; " +
+		"администратор также удаляет записи, меняет цены и логотипы и управляет пользователями.")
+	infoLabel.Wrapping = fyne.TextWrapWord
+
+	content := container.NewVBox()
+	refreshBtn := widget.NewButtonWithIcon("Обновить", theme.ViewRefreshIcon(), nil)
+
+	// Добавление пользователя или смена роли
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Имя входа, например DOMAIN\\ivanov")
+	roleSelect := widget.NewSelect(roleOptions(), nil)
+	roleSelect.SetSelected(roleTitles[roleOperator])
+	saveBtn := widget.NewButtonWithIcon("Сохранить", theme.DocumentSaveIcon(), nil)
+	saveBtn.Importance = widget.HighImportance
+
+	var reload func()
+	reload = func() {
+		var users []AppUser
+		d.runInBackground("Загрузка пользователей", func(ctx context.Context) error {
+			var err error
+			users, err = d.repository().GetAppUsers(ctx)
+			return err
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Не удалось загрузить пользователей: %v", err))
+				return
+			}
+			content.Objects = d.userRows(users, reload)
+			content.Refresh()
+		}, refreshBtn)
+	}
+	d.reloadUsers = reload
+	refreshBtn.OnTapped = reload
+
+	saveBtn.OnTapped = func() {
+		name := strings.TrimSpace(nameEntry.Text)
+		role := roleByTitle(roleSelect.Selected)
+		if name == "" {
+			d.showMessage("Ошибка", "Введите имя пользователя")
+			return
+		}
+
+		d.runInBackground("Сохранение пользователя", func(ctx context.Context) error {
+			return d.repository().SetAppUser(ctx, name, role)
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Не удалось сохранить пользователя: %v", err))
+				return
+			}
+			nameEntry.SetText("")
+			reload()
+		}, nameEntry, roleSelect, saveBtn)
+	}
+
+	reload()
+
+	form := container.NewBorder(nil, nil, widget.NewLabel("Пользователь:"),
+		container.NewHBox(roleSelect, saveBtn), nameEntry)
+
+	header := container.NewVBox(
+		titleLabel,
+		widget.NewSeparator(),
+		container.NewBorder(nil, nil, nil, refreshBtn, infoLabel),
+		form,
+		widget.NewSeparator(),
+	)
+	return container.NewBorder(header, nil, nil, nil, container.NewVScroll(content))
+}
+
+// userRows shows one row per user with a role select and a delete button
+func (d *DatabaseApp) userRows(users []AppUser, reload func()) []fyne.CanvasObject {
+	if len(users) == 0 {
+		return []fyne.CanvasObject{widget.NewLabel("Пользователей нет")}
+	}
+
+	_, _, current := d.connection()
+	var rows []fyne.CanvasObject
+	for _, u := range users {
+		u := u
+		label := widget.NewLabel(u.Name)
+		if u.Name == current.Name {
+			label.SetText(u.Name + " (вы)")
+			label.TextStyle = fyne.TextStyle{Bold: true}
+		}
+
+		roleSelect := widget.NewSelect(roleOptions(), nil)
+		roleSelect.SetSelected(roleTitles[u.Role])
+		deleteBtn := widget.NewButtonWithIcon("Удалить", theme.DeleteIcon(), nil)
+		deleteBtn.Importance = widget.DangerImportance
+
+		roleSelect.OnChanged = func(title string) {
+			role := roleByTitle(title)
+			if role == u.Role {
+				return
+			}
+			d.runInBackground("Смена роли", func(ctx context.Context) error {
+				return d.repository().SetAppUser(ctx, u.Name, role)
+			}, func(err error) {
+				if err != nil {
+					d.showUserError(err)
+				}
+				reload()
+			}, roleSelect, deleteBtn)
+		}
+
+		deleteBtn.OnTapped = func() {
+			dialog.ShowConfirm("Удаление пользователя",
+				fmt.Sprintf("Удалить пользователя %s? Он сможет только просматривать данные.", u.Name),
+				func(ok bool) {
+					if !ok {
+						return
+					}
+					d.runInBackground("Удаление пользователя", func(ctx context.Context) error {
+						return d.repository().DeleteAppUser(ctx, u.Name)
+					}, func(err error) {
+						if err != nil {
+							d.showUserError(err)
+							return
+						}
+						reload()
+					}, roleSelect, deleteBtn)
+				}, d.window)
+		}
+
+		rows = append(rows, container.NewBorder(nil, nil, nil,
+			container.NewHBox(layout.NewSpacer(), roleSelect, deleteBtn), label))
+	}
+	return rows
+}
+
+func (d *DatabaseApp) showUserError(err error) {
+	if errors.Is(err, errLastAdmin) {
+		d.showMessage("Ошибка", "Нельзя лишить прав последнего администратора: "+
+			"сначала назначьте администратором другого пользователя.")
+		return
+	}
+	d.showMessage("Ошибка", fmt.Sprintf("Не удалось изменить пользователя: %v", err))
+}
@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
-
+	"log"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
@@ -17,22 +22,11 @@ func (d *DatabaseApp) createViewTab() *container.Scroll {
 	titleLabel := widget.NewLabelWithStyle("Просмотр таблиц базы данных", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
 
 	// Table Selector
-	tableSelect := widget.NewSelect([]string{
-		"Категории прав",
-		"Владельцы",
-		"Марки автомобилей",
-		"Автомобили",
-	}, nil)
+	tableMap := viewTableMap()
+	tableSelect := widget.NewSelect(viewTableTitles(), nil)
 	tableSelect.SetSelected("Владельцы")
 	tableSelect.PlaceHolder = "Выберите таблицу"
 
-	tableMap := map[string]string{
-		"Категории прав":    "driver_categories",
-		"Владельцы":         "owners",
-		"Марки автомобилей": "car_brands",
-		"Автомобили":        "cars",
-	}
-
 	// Data Table Area
 	dataTable := widget.NewTable(
 		func() (int, int) { return 0, 0 },
@@ -46,24 +40,182 @@ func (d *DatabaseApp) createViewTab() *container.Scroll {
 	dataTable.SetColumnWidth(0, 80)
 	dataTable.SetColumnWidth(1, 150)
 
+	// Sorting and filters of the current table
+	var query TableQuery
+
+	countLabel := widget.NewLabel("")
+
+	// Page Size
+	pageSizeSelect := widget.NewSelect([]string{"50", "100", "500", "1000"}, nil)
+	pageSizeSelect.SetSelected(strconv.Itoa(d.viewPageSize()))
+
+	var pager *tablePager
+
+	// Выбор строк для массовых действий (владельцы и автомобили)
+	selection := newRowSelection()
+	selectCheck := widget.NewCheck("Выбор строк (Shift+щелчок — диапазон)", nil)
+	selectedLabel := widget.NewLabel("")
+	deleteSelectedBtn := widget.NewButtonWithIcon("Удалить выбранные", theme.DeleteIcon(), nil)
+	deleteSelectedBtn.Importance = widget.DangerImportance
+	editSelectedBtn := widget.NewButtonWithIcon("Изменить выбранные…", theme.DocumentCreateIcon(), nil)
+	clearSelectionBtn := widget.NewButtonWithIcon("Снять выбор", theme.ContentClearIcon(), nil)
+
+	updateSelection := func() {
+		tableName := tableMap[tableSelect.Selected]
+		if bulkTables[tableName] && d.can(roleOperator) {
+			selectCheck.Enable()
+		} else {
+			selectCheck.SetChecked(false)
+			selectCheck.Disable()
+		}
+
+		selectedLabel.SetText(fmt.Sprintf("Выбрано: %d", selection.count()))
+		if !selectCheck.Checked || selection.count() == 0 {
+			deleteSelectedBtn.Disable()
+			editSelectedBtn.Disable()
+			clearSelectionBtn.Disable()
+			return
+		}
+		if d.can(roleAdmin) {
+			deleteSelectedBtn.Enable()
+		} else {
+			deleteSelectedBtn.Disable()
+		}
+		clearSelectionBtn.Enable()
+		if tableName == "cars" {
+			editSelectedBtn.Enable()
+		} else {
+			editSelectedBtn.Disable()
+		}
+	}
+
 	// Refresh Button
-	refreshBtn := widget.NewButtonWithIcon("Обновить данные", theme.ViewRefreshIcon(), func() {
-		if tableName, ok := tableMap[tableSelect.Selected]; ok {
-			d.loadTableData(tableName, dataTable)
+	refreshBtn := widget.NewButtonWithIcon("Обновить данные", theme.ViewRefreshIcon(), nil)
+	refreshBtn.Importance = widget.MediumImportance
+
+	// Большие таблицы загружаются в фоне; долгую загрузку можно отменить.
+	// Пока идёт загрузка, выбор таблицы и обновление недоступны
+	reload := func() {
+		tableName, ok := tableMap[tableSelect.Selected]
+		if !ok {
+			updateSelection()
+			return
 		}
+
+		var loaded *tablePager
+		d.runWithProgress("Загрузка таблицы", func(ctx context.Context) error {
+			var err error
+			loaded, err = d.loadTableData(ctx, tableName, query, d.viewPageSize())
+			return err
+		}, func(err error) {
+			if err != nil {
+				d.showMessage("Ошибка", fmt.Sprintf("Ошибка загрузки данных: %v", err))
+				return
+			}
+			pager = loaded
+			d.showTableData(pager, dataTable)
+			total := 0
+			if pager != nil {
+				total = pager.total
+				if selectCheck.Checked {
+					pager.selected = selection.has
+				}
+			}
+			countLabel.SetText(fmt.Sprintf("Всего строк: %d", total))
+			updateSelection()
+			dataTable.Refresh()
+		}, tableSelect, refreshBtn, pageSizeSelect)
+	}
+
+	// После массового действия выбор сбрасывается
+	afterBulk := func() {
+		selection.clear()
+		reload()
+	}
+
+	selectCheck.OnChanged = func(bool) {
+		selection.clear()
+		reload()
+	}
+	deleteSelectedBtn.OnTapped = func() {
+		d.confirmBulkDelete(tableMap[tableSelect.Selected], selection.sorted(), afterBulk)
+	}
+	editSelectedBtn.OnTapped = func() {
+		d.showBulkEditCarsDialog(selection.sorted(), afterBulk)
+	}
+	clearSelectionBtn.OnTapped = func() {
+		selection.clear()
+		updateSelection()
+		dataTable.Refresh()
+	}
+
+	pageSizeSelect.OnChanged = func(value string) {
+		size, _ := strconv.Atoi(value)
+		d.app.Preferences().SetInt(viewPageSizeKey, size)
+		reload()
+	}
+
+	refreshBtn.OnTapped = reload
+
+	// Export Button
+	exportBtn := widget.NewButtonWithIcon("Экспорт CSV", theme.DocumentSaveIcon(), func() {
+		d.exportTableCSV(tableMap[tableSelect.Selected], query)
 	})
-	refreshBtn.Importance = widget.MediumImportance
 
-	// Initial Load
-	if tableName, ok := tableMap[tableSelect.Selected]; ok {
-		d.loadTableData(tableName, dataTable)
+	exportXLSXBtn := widget.NewButtonWithIcon("Экспорт XLSX (все таблицы)", theme.DocumentSaveIcon(), func() {
+		d.exportWorkbookXLSX()
+	})
+
+	// Click on a header cell: ascending → descending → table order.
+	// Click on an owner row opens the owner card; in selection mode a click
+	// on a row toggles it.
+	dataTable.OnSelected = func(id widget.TableCellID) {
+		dataTable.UnselectAll()
+		tableName := tableMap[tableSelect.Selected]
+		if id.Row > 0 && selectCheck.Checked {
+			selection.click(pager, id.Row-1, shiftPressed())
+			updateSelection()
+			dataTable.Refresh()
+			return
+		}
+		if id.Row > 0 {
+			if tableName == "owners" && pager != nil {
+				if row, ok := pager.row(id.Row - 1); ok {
+					if ownerID, ok := cellNumber(row[0]); ok {
+						d.showOwnerCard(int(ownerID))
+					}
+				}
+			}
+			return
+		}
+
+		kinds := tableColumnKinds(tableName)
+		if id.Col >= len(kinds) || kinds[id.Col] == columnImage {
+			return
+		}
+		switch {
+		case query.Sort == nil || query.Sort.Column != id.Col:
+			query.Sort = &TableSort{Column: id.Col}
+		case !query.Sort.Desc:
+			query.Sort.Desc = true
+		default:
+			query.Sort = nil
+		}
+		reload()
 	}
 
+	filterRow, resetFilters := d.createFilterRow(func() string { return tableMap[tableSelect.Selected] }, &query, reload)
+
+	// Initial Load
+	resetFilters()
+	reload()
+
 	// Change Handler
-	tableSelect.OnChanged = func(table string) {
-		if tableName, ok := tableMap[table]; ok {
-			d.loadTableData(tableName, dataTable)
-		}
+	tableSelect.OnChanged = func(string) {
+		query = TableQuery{}
+		selection.clear()
+		resetFilters()
+		reload()
 	}
 
 	// Control Panel
@@ -72,100 +224,480 @@ func (d *DatabaseApp) createViewTab() *container.Scroll {
 		widget.NewSeparator(),
 		widget.NewLabel("Выберите таблицу для просмотра:"),
 		tableSelect,
-		container.NewGridWithColumns(1, refreshBtn),
+		container.NewGridWithColumns(3, refreshBtn, exportBtn, exportXLSXBtn),
+		container.NewHBox(countLabel, layout.NewSpacer(), widget.NewLabel("Строк на странице:"), pageSizeSelect),
+		container.NewHBox(selectCheck, selectedLabel, layout.NewSpacer(), clearSelectionBtn, editSelectedBtn, deleteSelectedBtn),
 		widget.NewSeparator(),
+		filterRow,
 	)
 
 	paddedControlPanel := container.NewPadded(controlPanel)
 	split := container.NewVSplit(paddedControlPanel, container.NewPadded(dataTable))
-	split.Offset = 0.15
+	split.Offset = 0.3
 	paddedContent := container.NewPadded(split)
 
 	return container.NewScroll(paddedContent)
 }
 
+// viewPageSizeKey stores the page size of the View tab in app preferences
+const viewPageSizeKey = "view.pageSize"
+
+// viewPageSize returns how many rows the View tab loads per query
+func (d *DatabaseApp) viewPageSize() int {
+	return d.app.Preferences().IntWithFallback(viewPageSizeKey, 100)
+}
+
+// createFilterRow builds the per-column filter controls. It edits query.Filters,
+// calls reload after a change and returns a function that resets the filters
+// for the current table.
+func (d *DatabaseApp) createFilterRow(currentTable func() string, query *TableQuery, reload func()) (fyne.CanvasObject, func()) {
+	columnSelect := widget.NewSelect(nil, nil)
+	columnSelect.PlaceHolder = "Колонка"
+
+	containsEntry := widget.NewEntry()
+	containsEntry.SetPlaceHolder("Содержит...")
+	fromEntry := widget.NewEntry()
+	toEntry := widget.NewEntry()
+
+	summaryLabel := widget.NewLabel("")
+	summaryLabel.Wrapping = fyne.TextWrapWord
+
+	// Индекс выбранной колонки в tableColumnNames
+	selectedColumn := func() (int, columnKind) {
+		names, kinds := tableColumnNames(currentTable()), tableColumnKinds(currentTable())
+		for i, name := range names {
+			if name == columnSelect.Selected {
+				return i, kinds[i]
+			}
+		}
+		return -1, columnText
+	}
+
+	columnSelect.OnChanged = func(string) {
+		_, kind := selectedColumn()
+		containsEntry.SetText("")
+		fromEntry.SetText("")
+		toEntry.SetText("")
+
+		if kind == columnText {
+			containsEntry.Enable()
+			fromEntry.Disable()
+			toEntry.Disable()
+			return
+		}
+		containsEntry.Disable()
+		fromEntry.Enable()
+		toEntry.Enable()
+		if kind == columnDate {
+			fromEntry.SetPlaceHolder("С (ДД.ММ.ГГГГ)")
+			toEntry.SetPlaceHolder("По (ДД.ММ.ГГГГ)")
+		} else {
+			fromEntry.SetPlaceHolder("От")
+			toEntry.SetPlaceHolder("До")
+		}
+	}
+
+	updateSummary := func() {
+		if len(query.Filters) == 0 {
+			summaryLabel.SetText("Фильтры не заданы. Нажмите на заголовок колонки для сортировки.")
+			return
+		}
+		names := tableColumnNames(currentTable())
+		parts := make([]string, 0, len(query.Filters))
+		for _, f := range query.Filters {
+			parts = append(parts, describeFilter(names[f.Column], f))
+		}
+		summaryLabel.SetText("Фильтры: " + strings.Join(parts, "; "))
+	}
+
+	applyBtn := widget.NewButtonWithIcon("Применить", theme.SearchIcon(), func() {
+		col, kind := selectedColumn()
+		if col < 0 {
+			d.showMessage("Ошибка", "Выберите колонку для фильтра")
+			return
+		}
+
+		filter := ColumnFilter{Column: col}
+		var err error
+		switch kind {
+		case columnText:
+			filter.Contains = strings.TrimSpace(containsEntry.Text)
+		case columnNumber:
+			if filter.Min, err = parseFilterNumber(fromEntry.Text); err == nil {
+				filter.Max, err = parseFilterNumber(toEntry.Text)
+			}
+		case columnDate:
+			if filter.From, err = parseFilterDate(fromEntry.Text); err == nil {
+				filter.To, err = parseFilterDate(toEntry.Text)
+			}
+		}
+		if err != nil {
+			d.showMessage("Ошибка", err.Error())
+			return
+		}
+
+		// Один фильтр на колонку; пустой фильтр снимает условие
+		filters := query.Filters[:0:0]
+		for _, f := range query.Filters {
+			if f.Column != col {
+				filters = append(filters, f)
+			}
+		}
+		if filter.Contains != "" || filter.Min != nil || filter.Max != nil || filter.From != nil || filter.To != nil {
+			filters = append(filters, filter)
+		}
+		query.Filters = filters
+
+		updateSummary()
+		reload()
+	})
+
+	reset := func() {
+		query.Filters = nil
+		var options []string
+		kinds := tableColumnKinds(currentTable())
+		for i, name := range tableColumnNames(currentTable()) {
+			if kinds[i] != columnImage {
+				options = append(options, name)
+			}
+		}
+		columnSelect.Options = options
+		columnSelect.ClearSelected()
+		columnSelect.OnChanged("")
+		updateSummary()
+	}
+
+	clearBtn := widget.NewButtonWithIcon("Сбросить фильтры", theme.ContentClearIcon(), func() {
+		reset()
+		reload()
+	})
+
+	row := container.NewVBox(
+		widget.NewLabel("Фильтр:"),
+		container.NewGridWithColumns(5, columnSelect, containsEntry, fromEntry, toEntry, applyBtn),
+		container.NewBorder(nil, nil, nil, clearBtn, summaryLabel),
+	)
+	return row, reset
+}
+
+// describeFilter returns a short text of a filter for the summary line
+func describeFilter(column string, f ColumnFilter) string {
+	if f.Contains != "" {
+		return fmt.Sprintf("%s содержит «%s»", column, f.Contains)
+	}
+
+	from, to := "", ""
+	if f.Min != nil {
+		from = strconv.FormatFloat(*f.Min, 'f', -1, 64)
+	}
+	if f.Max != nil {
+		to = strconv.FormatFloat(*f.Max, 'f', -1, 64)
+	}
+	if f.From != nil {
+		from = f.From.Format("02.01.2006")
+	}
+	if f.To != nil {
+		to = f.To.Format("02.01.2006")
+	}
+	return fmt.Sprintf("%s: %s…%s", column, from, to)
+}
+
+func parseFilterNumber(s string) (*float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", "."), 64)
+	if err != nil {
+		return nil, fmt.Errorf("«%s» — не число", s)
+	}
+	return &value, nil
+}
+
+func parseFilterDate(s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{"02.01.2006", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("«%s» — дата должна быть в формате ДД.ММ.ГГГГ", s)
+}
+
 func (d *DatabaseApp) refreshViewTab(split *container.Split) {
 	if table, ok := split.Trailing.(*widget.Table); ok {
 		selectWidget := split.Leading.(*fyne.Container).Objects[1].(*widget.Select)
 
-		tableMap := map[string]string{
-			"Категории прав":    "driver_categories",
-			"Владельцы":         "owners",
-			"Марки автомобилей": "car_brands",
-			"Автомобили":        "cars",
+		if tableName, ok := viewTableMap()[selectWidget.Selected]; ok {
+			var pager *tablePager
+			d.runInBackground("Загрузка таблицы", func(ctx context.Context) error {
+				var err error
+				pager, err = d.loadTableData(ctx, tableName, TableQuery{}, d.viewPageSize())
+				return err
+			}, func(err error) {
+				if err != nil {
+					d.showMessage("Ошибка", fmt.Sprintf("Ошибка загрузки данных: %v", err))
+					return
+				}
+				d.showTableData(pager, table)
+			}, selectWidget)
 		}
+	}
+}
 
-		if tableName, ok := tableMap[selectWidget.Selected]; ok {
-			d.loadTableData(tableName, table)
-		}
+// viewTables lists the tables of the View tab in display order
+var viewTables = []struct {
+	Title string
+	Name  string
+}{
+	{"Категории прав", "driver_categories"},
+	{"Владельцы", "owners"},
+	{"Марки автомобилей", "car_brands"},
+	{"Автомобили", "cars"},
+}
+
+func viewTableTitles() []string {
+	titles := make([]string, len(viewTables))
+	for i, t := range viewTables {
+		titles[i] = t.Title
 	}
+	return titles
 }
 
-func (d *DatabaseApp) loadTableData(tableName string, table *widget.Table) {
-	var query string
-	var columnNames []string
+// viewTableMap maps display titles to table names
+func viewTableMap() map[string]string {
+	tableMap := make(map[string]string, len(viewTables))
+	for _, t := range viewTables {
+		tableMap[t.Title] = t.Name
+	}
+	return tableMap
+}
 
+// tableColumnNames returns the headers for the rows of Repository.GetTableRows
+func tableColumnNames(tableName string) []string {
 	switch tableName {
 	case "driver_categories":
-		query = "SELECT category_id, category_code, category_name, description FROM driver_categories"
-		columnNames = []string{"ID", "Код категории", "Название", "Описание"}
+		return []string{"ID", "Код категории", "Название", "Описание"}
 	case "owners":
-		// Мы используем созданное представление v_owner_details
-		// Добавляем experience_years в выборку
-		query = `SELECT owner_id, first_name, last_name, phone, email, 
-                        category_code, registration_date, car_count, experience_years
-                 FROM v_owner_details`
-		columnNames = []string{"ID", "Имя", "Фамилия", "Телефон", "Email",
+		return []string{"ID", "Имя", "Фамилия", "Телефон", "Email",
 			"Категория", "Дата получения прав", "Кол-во авто", "Стаж (лет)"}
 	case "car_brands":
-		query = "SELECT brand_id, brand_name, country_origin, founded_year, image_data FROM car_brands"
-		columnNames = []string{"ID", "Марка", "Страна", "Год основания", "Логотип"}
+		return []string{"ID", "Марка", "Страна", "Год основания", "Логотип"}
 	case "cars":
-		query = `SELECT c.car_id, 
-                        o.first_name + ' ' + o.last_name, 
-                        b.brand_name, 
-                        c.model, 
-                        c.year, 
-                        c.color, 
-                        c.vin_code, 
-                        c.price, -- Цена покупки
-                        dbo.fn_GetCarDepreciatedValue(c.price, c.year), -- Текущая цена (функция)
-                        c.purchase_date
-                 FROM cars c 
-                 JOIN owners o ON c.owner_id = o.owner_id 
-                 JOIN car_brands b ON c.brand_id = b.brand_id`
-
 		// Добавляем заголовок "Тек. цена"
-		columnNames = []string{"ID", "Владелец", "Марка", "Модель", "Год",
+		return []string{"ID", "Владелец", "Марка", "Модель", "Год",
 			"Цвет", "VIN", "Цена покупки", "Тек. цена (~)", "Дата покупки"}
 	default:
-		return
+		return nil
 	}
+}
 
-	rows, err := d.db.Query(query)
-	if err != nil {
-		d.showMessage("Ошибка", fmt.Sprintf("Ошибка загрузки данных: %v", err))
-		return
+// columnKind selects the filter applicable to a column
+type columnKind int
+
+const (
+	columnText columnKind = iota
+	columnNumber
+	columnDate
+	columnImage
+)
+
+// tableColumnKinds returns the kind of every column of tableColumnNames
+func tableColumnKinds(tableName string) []columnKind {
+	switch tableName {
+	case "driver_categories":
+		return []columnKind{columnNumber, columnText, columnText, columnText}
+	case "owners":
+		return []columnKind{columnNumber, columnText, columnText, columnText, columnText,
+			columnText, columnDate, columnNumber, columnNumber}
+	case "car_brands":
+		return []columnKind{columnNumber, columnText, columnText, columnNumber, columnImage}
+	case "cars":
+		return []columnKind{columnNumber, columnText, columnText, columnText, columnNumber,
+			columnText, columnText, columnNumber, columnNumber, columnDate}
+	default:
+		return nil
 	}
-	defer rows.Close()
+}
 
-	columnCount := len(columnNames)
-	var data [][]interface{}
+// formatCellValue converts a scanned database value to the text shown in tables
+func formatCellValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
 
-	for rows.Next() {
-		values := make([]interface{}, columnCount)
-		valuePtrs := make([]interface{}, columnCount)
-		for i := range values {
-			valuePtrs[i] = &values[i]
+	// ПРОВЕРЯЕМ ТИП ДАННЫХ
+	switch v := value.(type) {
+	case []byte:
+		// 1. Превращаем байты в строку (получаем "50000.0000")
+		rawString := string(v)
+
+		// 2. Пробуем превратить строку в число (float64)
+		if floatVal, err := strconv.ParseFloat(rawString, 64); err == nil {
+			// 3. Если успешно — форматируем без дробной части (%.0f)
+			// %.0f означает "0 знаков после запятой"
+			// %.2f означало бы "2 знака" (например, 50000.00)
+			return fmt.Sprintf("%.0f", floatVal)
 		}
+		// Если не получилось (вдруг там не число), выводим как есть
+		return rawString
 
-		err := rows.Scan(valuePtrs...)
-		if err != nil {
-			d.showMessage("Ошибка", fmt.Sprintf("Ошибка чтения данных: %v", err))
-			return
+	case float64:
+		// Для обычных float тоже уберем хвосты, если нужно
+		return fmt.Sprintf("%.0f", v)
+
+	case int64, int, int32:
+		return fmt.Sprintf("%d", v)
+
+	case time.Time:
+		// DATE колонки: время суток не показываем
+		return v.Format("2006-01-02")
+
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// tablePager loads the rows of a View tab table page by page,
+// when they are first needed by the table
+type tablePager struct {
+	repo      func() Repository // текущее подключение: оно меняется при переподключении
+	tableName string
+	query     TableQuery
+	pageSize  int
+	total     int
+	onLoaded  func()
+	// onError reports the first failure of a page; the page is retried when
+	// it is scrolled into view again after pagerRetryDelay
+	onError func(err error)
+	timeout time.Duration // ограничение загрузки одной страницы
+	// selected marks the rows chosen for bulk actions; nil when selection is off
+	selected func(id int) bool
+
+	mu      sync.Mutex
+	pages   map[int][][]interface{}
+	loading map[int]bool
+	failed  map[int]time.Time // время последней ошибки загрузки страницы
+}
+
+// pagerRetryDelay keeps a failed page from being queried on every redraw
+const pagerRetryDelay = 5 * time.Second
+
+// row returns a loaded row; otherwise it starts loading its page and returns false
+func (p *tablePager) row(index int) ([]interface{}, bool) {
+	page := index / p.pageSize
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if rows, ok := p.pages[page]; ok {
+		if offset := index % p.pageSize; offset < len(rows) {
+			return rows[offset], true
 		}
-		data = append(data, values)
+		return nil, false
+	}
+	if failedAt, ok := p.failed[page]; ok && time.Since(failedAt) < pagerRetryDelay {
+		return nil, false
 	}
+	if !p.loading[page] {
+		p.loading[page] = true
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+			defer cancel()
+			p.load(ctx, page)
+		}()
+	}
+	return nil, false
+}
+
+func (p *tablePager) load(ctx context.Context, page int) error {
+	q := p.query
+	q.Offset = page * p.pageSize
+	q.Limit = p.pageSize
+
+	rows, err := p.repo().QueryTable(ctx, p.tableName, q)
+
+	p.mu.Lock()
+	delete(p.loading, page)
+	_, failedBefore := p.failed[page]
+	if err != nil {
+		// Неудачная страница не кэшируется, чтобы её можно было загрузить снова
+		p.failed[page] = time.Now()
+	} else {
+		p.pages[page] = rows
+		delete(p.failed, page)
+	}
+	p.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Ошибка загрузки страницы %d таблицы %s: %v", page, p.tableName, err)
+		if !failedBefore && p.onError != nil {
+			p.onError(err)
+		}
+	}
+	if p.onLoaded != nil {
+		p.onLoaded()
+	}
+	return err
+}
+
+// rowFailed reports whether the page of a row that is not loaded failed to load
+func (p *tablePager) rowFailed(index int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.failed[index/p.pageSize]
+	return ok
+}
+
+// loadTableData counts the rows of a table and loads its first page; ctx
+// limits both. The other pages are queried when they are scrolled into view.
+// It runs off the UI goroutine: showTableData binds the result to the widget.
+func (d *DatabaseApp) loadTableData(ctx context.Context, tableName string, query TableQuery, pageSize int) (*tablePager, error) {
+	if tableColumnNames(tableName) == nil {
+		return nil, nil
+	}
+
+	total, err := d.repository().CountTable(ctx, tableName, query.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	pager := &tablePager{
+		repo:      d.repository,
+		tableName: tableName,
+		query:     query,
+		pageSize:  pageSize,
+		total:     total,
+		timeout:   d.queryTimeout(),
+		pages:     make(map[int][][]interface{}),
+		loading:   make(map[int]bool),
+		failed:    make(map[int]time.Time),
+	}
+	// Первая страница загружается сразу, остальные — при прокрутке
+	if err := pager.load(ctx, 0); err != nil {
+		return nil, err
+	}
+	return pager, nil
+}
+
+// showTableData shows the rows of the pager in the table with lazy paging
+func (d *DatabaseApp) showTableData(pager *tablePager, table *widget.Table) {
+	if pager == nil {
+		return
+	}
+	tableName, query, total := pager.tableName, pager.query, pager.total
+	columnNames := tableColumnNames(tableName)
+	pager.onLoaded = func() { d.onUI(table.Refresh) }
+	pager.onError = func(err error) {
+		d.onUI(func() {
+			d.showMessage("Ошибка", fmt.Sprintf("Не удалось загрузить строки таблицы: %v\nПрокрутите таблицу, чтобы повторить загрузку.", err))
+		})
+	}
+
+	columnCount := len(columnNames)
 
 	imageCache := make(map[string]*canvas.Image)
 
@@ -179,7 +711,7 @@ func (d *DatabaseApp) loadTableData(tableName string, table *widget.Table) {
 	}
 
 	table.Length = func() (int, int) {
-		return len(data) + 1, columnCount
+		return total + 1, columnCount
 	}
 
 	table.CreateCell = func() fyne.CanvasObject {
@@ -215,13 +747,25 @@ func (d *DatabaseApp) loadTableData(tableName string, table *widget.Table) {
 			label.TextStyle = fyne.TextStyle{Bold: true}
 			label.Alignment = fyne.TextAlignCenter
 			if id.Col < len(columnNames) {
-				label.SetText(columnNames[id.Col])
+				header := columnNames[id.Col]
+				if query.Sort != nil && query.Sort.Column == id.Col {
+					if query.Sort.Desc {
+						header += " ▼"
+					} else {
+						header += " ▲"
+					}
+				}
+				label.SetText(header)
 			}
 		} else {
 			// Data
-			rowIndex := id.Row - 1
-			if rowIndex < len(data) && id.Col < len(data[rowIndex]) {
-				value := data[rowIndex][id.Col]
+			row, loaded := pager.row(id.Row - 1)
+			if !loaded && pager.rowFailed(id.Row-1) {
+				label.SetText("⚠ не загружено")
+			} else if !loaded {
+				label.SetText("…")
+			} else if id.Col < len(row) {
+				value := row[id.Col]
 
 				if tableName == "car_brands" && id.Col == 4 {
 					if imageData, ok := value.([]byte); ok && len(imageData) > 0 {
@@ -243,35 +787,15 @@ func (d *DatabaseApp) loadTableData(tableName string, table *widget.Table) {
 					}
 				} else {
 					// Обычный текст
-					if value != nil {
-						// ПРОВЕРЯЕМ ТИП ДАННЫХ
-						switch v := value.(type) {
-						case []byte:
-							// 1. Превращаем байты в строку (получаем "50000.0000")
-							rawString := string(v)
-
-							// 2. Пробуем превратить строку в число (float64)
-							if floatVal, err := strconv.ParseFloat(rawString, 64); err == nil {
-								// 3. Если успешно — форматируем без дробной части (%.0f)
-								// %.0f означает "0 знаков после запятой"
-								// %.2f означало бы "2 знака" (например, 50000.00)
-								label.SetText(fmt.Sprintf("%.0f", floatVal))
-							} else {
-								// Если не получилось (вдруг там не число), выводим как есть
-								label.SetText(rawString)
-							}
-
-						case float64:
-							// Для обычных float тоже уберем хвосты, если нужно
-							label.SetText(fmt.Sprintf("%.0f", v))
-
-						case int64, int, int32:
-							label.SetText(fmt.Sprintf("%d", v))
-
-						default:
-							label.SetText(fmt.Sprintf("%v", v))
+					text := formatCellValue(value)
+					if id.Col == 0 && pager.selected != nil {
+						if recordID, ok := cellNumber(value); ok && pager.selected(int(recordID)) {
+							text = "☑ " + text
+						} else {
+							text = "☐ " + text
 						}
 					}
+					label.SetText(text)
 				}
 			}
 		}
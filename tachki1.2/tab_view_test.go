@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// flakyRepository fails QueryTable while failing is set
+type flakyRepository struct {
+	Repository
+	failing bool
+}
+
+func (r *flakyRepository) QueryTable(ctx context.Context, tableName string, query TableQuery) ([][]interface{}, error) {
+	if r.failing {
+		return nil, errors.New("соединение разорвано")
+	}
+	return r.Repository.QueryTable(ctx, tableName, query)
+}
+
+// TestTablePagerRetry checks that a failed page is reported once, is not
+// cached and loads again after the retry delay
+func TestTablePagerRetry(t *testing.T) {
+	repo := &flakyRepository{Repository: newMemoryRepository(), failing: true}
+	var reported int
+	pager := &tablePager{
+		repo:      func() Repository { return repo },
+		tableName: "driver_categories",
+		pageSize:  2,
+		total:     5,
+		onError:   func(error) { reported++ },
+		pages:     make(map[int][][]interface{}),
+		loading:   make(map[int]bool),
+		failed:    make(map[int]time.Time),
+	}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := pager.load(ctx, 1); err == nil {
+			t.Fatal("ошибка загрузки не возвращена")
+		}
+	}
+	if reported != 1 {
+		t.Errorf("об ошибке сообщено %d раз, ожидался 1", reported)
+	}
+	if _, ok := pager.row(2); ok || !pager.rowFailed(2) {
+		t.Fatal("неудачная страница считается загруженной")
+	}
+	if pager.loading[1] {
+		t.Fatal("страница загружается повторно до истечения задержки")
+	}
+
+	repo.failing = false
+	if err := pager.load(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if row, ok := pager.row(2); !ok || row == nil || pager.rowFailed(2) {
+		t.Error("страница не загружена после восстановления")
+	}
+}
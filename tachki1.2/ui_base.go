@@ -1,72 +1,411 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"image/color" // Add this
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas" // Add this
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
 func (d *DatabaseApp) showLoginScreen() {
+	prefs := d.app.Preferences()
+	keys := d.keyring()
+
+	// Сброс хранилища удаляет все сохранённые пароли: об этом нужно сказать
+	notifyCleared := func() {
+		if cause := keys.Cleared(); cause != nil {
+			d.showMessage("Saved Passwords Cleared", fmt.Sprintf(
+				"Saved passwords could not be decrypted and were cleared:\n%v\n\nEnter the password of each profile again.", cause))
+		}
+	}
+
 	// 1. Create Input Fields
 	serverEntry := widget.NewEntry()
-	serverEntry.SetPlaceHolder("e.g., localhost, 1433")
-	serverEntry.SetText("localhost")
+	serverEntry.SetPlaceHolder("e.g., localhost")
 
 	dbEntry := widget.NewEntry()
 	dbEntry.SetPlaceHolder("e.g., master")
-	dbEntry.SetText("master")
 
 	userEntry := widget.NewEntry()
 	userEntry.SetPlaceHolder("User ID")
-	userEntry.SetText("sa")
 
 	passEntry := widget.NewPasswordEntry()
 	passEntry.SetPlaceHolder("Password")
 
-	// 2. Create the Form
+	// Saved profiles keep the password encrypted in the keyring
+	rememberCheck := widget.NewCheck("Remember password", nil)
+
+	// Advanced SQL Server options (go-mssqldb connection parameters)
+	portEntry := widget.NewEntry()
+	portEntry.SetPlaceHolder("1433")
+	portEntry.Validator = optionalNumberValidator("port", 1, 65535)
+
+	instanceEntry := widget.NewEntry()
+	instanceEntry.SetPlaceHolder("e.g., SQLEXPRESS")
+
+	encryptSelect := widget.NewSelect(encryptModes, nil)
+	trustCheck := widget.NewCheck("Trust server certificate", nil)
+
+	connectTimeoutEntry := widget.NewEntry()
+	connectTimeoutEntry.SetPlaceHolder("15")
+	connectTimeoutEntry.Validator = optionalNumberValidator("connection timeout", 1, 3600)
+
+	appNameEntry := widget.NewEntry()
+	appNameEntry.SetPlaceHolder(defaultAppName)
+
+	// SQLite: path to a local database file (created on first connect)
+	sqliteEntry := widget.NewEntry()
+	sqliteEntry.SetPlaceHolder("e.g., cars.db")
+
+	browseBtn := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err == nil && reader != nil {
+				sqliteEntry.SetText(reader.URI().Path())
+				reader.Close()
+			}
+		}, d.window)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".db", ".sqlite", ".sqlite3"}))
+		fd.Show()
+	})
+
+	// Query timeout (seconds), shared by both backends
+	timeoutSelect := widget.NewSelect([]string{"10", "30", "60", "120", "300"}, nil)
+	timeoutSelect.SetSelected(strconv.Itoa(int(d.queryTimeout() / time.Second)))
+
+	// Idle time before the window is locked (minutes)
+	idleSelect := widget.NewSelect([]string{"Never", "5", "15", "30", "60"}, nil)
+	idleSelect.SetSelected("Never")
+	if minutes := int(d.idleTimeout() / time.Minute); minutes > 0 {
+		idleSelect.SetSelected(strconv.Itoa(minutes))
+	}
+
+	timeoutForm := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "Query Timeout", Widget: timeoutSelect, HintText: "Seconds per database request"},
+			{Text: "Lock When Idle", Widget: idleSelect, HintText: "Minutes without activity"},
+		},
+	}
+
+	// 2. Create the Forms
 	// using HintText to help the user
-	form := &widget.Form{
+	advancedForm := &widget.Form{
 		Items: []*widget.FormItem{
-			{Text: "Server Address", Widget: serverEntry, HintText: "Host name or IP"},
-			{Text: "Database Name", Widget: dbEntry, HintText: "Target DB"},
-			{Text: "Username", Widget: userEntry},
-			{Text: "Password", Widget: passEntry},
+			{Text: "Port", Widget: portEntry, HintText: "Empty for a named instance"},
+			{Text: "Instance Name", Widget: instanceEntry, HintText: "Resolved through SQL Browser"},
+			{Text: "Encryption", Widget: encryptSelect, HintText: "false: only the login is encrypted"},
+			{Text: "", Widget: trustCheck},
+			{Text: "Connection Timeout", Widget: connectTimeoutEntry, HintText: "Seconds; empty for the driver default"},
+			{Text: "Application Name", Widget: appNameEntry, HintText: "Shown in the server's session list"},
 		},
 	}
 
-	// 3. Create 'Connect' Button with Icon
-	connectBtn := widget.NewButtonWithIcon("Connect to Database", theme.LoginIcon(), func() {
-		// Construct connection string
-		connStr := fmt.Sprintf("server=%s;user id=%s;password=%s;database=%s;",
-			serverEntry.Text,
-			userEntry.Text,
-			passEntry.Text,
-			dbEntry.Text,
-		)
+	mssqlForm := container.NewVBox(
+		&widget.Form{
+			Items: []*widget.FormItem{
+				{Text: "Server Address", Widget: serverEntry, HintText: "Host name or IP"},
+				{Text: "Database Name", Widget: dbEntry, HintText: "Target DB"},
+				{Text: "Username", Widget: userEntry},
+				{Text: "Password", Widget: passEntry},
+				{Text: "", Widget: rememberCheck},
+			},
+		},
+		widget.NewAccordion(widget.NewAccordionItem("Advanced", advancedForm)),
+	)
+
+	sqliteForm := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "Database File", Widget: container.NewBorder(nil, nil, nil, browseBtn, sqliteEntry), HintText: "Created if it does not exist"},
+		},
+	}
+	sqliteForm.Hide()
 
-		// Disable button to prevent double clicks during connection attempt
-		// (In a real app you might want to show a loading spinner here)
+	// Backend selector: SQL Server or a local SQLite file
+	var testBtn *widget.Button
+	backendRadio := widget.NewRadioGroup([]string{backendMSSQL, backendSQLite}, func(backend string) {
+		if backend == backendSQLite {
+			mssqlForm.Hide()
+			sqliteForm.Show()
+			if testBtn != nil {
+				testBtn.Hide()
+			}
+		} else {
+			sqliteForm.Hide()
+			mssqlForm.Show()
+			if testBtn != nil {
+				testBtn.Show()
+			}
+		}
+	})
+	backendRadio.Horizontal = true
+	backendRadio.Required = true
+
+	// formProfile collects the settings currently entered in the form
+	formProfile := func() connectionProfile {
+		port, _ := strconv.Atoi(strings.TrimSpace(portEntry.Text))
+		connectTimeout, _ := strconv.Atoi(strings.TrimSpace(connectTimeoutEntry.Text))
+		return connectionProfile{
+			Backend:                backendRadio.Selected,
+			Server:                 strings.TrimSpace(serverEntry.Text),
+			Port:                   port,
+			Instance:               strings.TrimSpace(instanceEntry.Text),
+			Database:               strings.TrimSpace(dbEntry.Text),
+			User:                   strings.TrimSpace(userEntry.Text),
+			Encrypt:                encryptSelect.Selected,
+			TrustServerCertificate: trustCheck.Checked,
+			ConnectTimeout:         connectTimeout,
+			AppName:                strings.TrimSpace(appNameEntry.Text),
+			SQLitePath:             strings.TrimSpace(sqliteEntry.Text),
+		}
+	}
+
+	// applyProfile fills the form with a profile and its saved password
+	applyProfile := func(p connectionProfile) {
+		backendRadio.SetSelected(p.Backend)
+		serverEntry.SetText(p.Server)
+		portEntry.SetText("")
+		if p.Port > 0 {
+			portEntry.SetText(strconv.Itoa(p.Port))
+		}
+		instanceEntry.SetText(p.Instance)
+		dbEntry.SetText(p.Database)
+		userEntry.SetText(p.User)
+		if p.Encrypt == "" {
+			p.Encrypt = encryptOptional
+		}
+		encryptSelect.SetSelected(p.Encrypt)
+		trustCheck.SetChecked(p.TrustServerCertificate)
+		connectTimeoutEntry.SetText("")
+		if p.ConnectTimeout > 0 {
+			connectTimeoutEntry.SetText(strconv.Itoa(p.ConnectTimeout))
+		}
+		appNameEntry.SetText(p.AppName)
+		if p.SQLitePath == "" {
+			p.SQLitePath = d.defaultSQLitePath()
+		}
+		sqliteEntry.SetText(p.SQLitePath)
 
-		err := d.connectDB(connStr)
+		passEntry.SetText("")
+		rememberCheck.SetChecked(false)
+		if p.Name == "" {
+			return
+		}
+		password, ok, err := keys.Get(p.Name)
+		notifyCleared()
 		if err != nil {
-			dialog.ShowError(fmt.Errorf("Connection Failed:\n%v", err), d.window)
+			dialog.ShowError(fmt.Errorf("Saved password is not available:\n%v", err), d.window)
 			return
 		}
+		if ok {
+			passEntry.SetText(password)
+			rememberCheck.SetChecked(true)
+		}
+	}
 
-		// If successful, load the main UI
-		d.createUI()
+	profileNames := func() []string {
+		var names []string
+		for _, p := range loadProfiles(prefs) {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+
+	profileSelect := widget.NewSelect(profileNames(), func(name string) {
+		if p, ok := findProfile(loadProfiles(prefs), name); ok {
+			applyProfile(p)
+		}
+	})
+	profileSelect.PlaceHolder = "New connection"
+
+	saveProfileBtn := widget.NewButtonWithIcon("", theme.DocumentSaveIcon(), func() {
+		nameEntry := widget.NewEntry()
+		nameEntry.SetText(profileSelect.Selected)
+		items := []*widget.FormItem{widget.NewFormItem("Profile Name", nameEntry)}
+		dialog.ShowForm("Save Connection Profile", "Save", "Cancel", items, func(ok bool) {
+			if !ok {
+				return
+			}
+			profile := formProfile()
+			profile.Name = strings.TrimSpace(nameEntry.Text)
+			if err := saveProfile(prefs, profile); err != nil {
+				dialog.ShowError(err, d.window)
+				return
+			}
+
+			var err error
+			if rememberCheck.Checked && profile.Backend == backendMSSQL {
+				err = keys.Set(profile.Name, passEntry.Text)
+			} else {
+				err = keys.Delete(profile.Name)
+			}
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("Profile saved, but the password was not:\n%v", err), d.window)
+			}
+
+			profileSelect.Options = profileNames()
+			profileSelect.SetSelected(profile.Name)
+		}, d.window)
+	})
+
+	deleteProfileBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		name := profileSelect.Selected
+		if name == "" {
+			return
+		}
+		dialog.ShowConfirm("Delete Profile", fmt.Sprintf("Delete the profile %q and its saved password?", name), func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := deleteProfile(prefs, name); err != nil {
+				dialog.ShowError(err, d.window)
+				return
+			}
+			if err := keys.Delete(name); err != nil {
+				dialog.ShowError(err, d.window)
+			}
+			profileSelect.Options = profileNames()
+			profileSelect.ClearSelected()
+			applyProfile(defaultMSSQLProfile())
+		}, d.window)
 	})
+
+	// Start with the last used profile, otherwise with the defaults
+	if p, ok := findProfile(loadProfiles(prefs), prefs.String(lastProfileKey)); ok {
+		profileSelect.SetSelected(p.Name)
+	} else {
+		applyProfile(defaultMSSQLProfile())
+	}
+
+	// 3. Create 'Connect' and 'Test Connection' Buttons with Icons
+	connectBtn := widget.NewButtonWithIcon("Connect to Database", theme.LoginIcon(), nil)
+	testBtn = widget.NewButtonWithIcon("Test Connection", theme.ConfirmIcon(), nil)
+	if backendRadio.Selected == backendSQLite {
+		testBtn.Hide()
+	}
+
+	// The form is disabled while a connection attempt runs
+	var busy []fyne.Disableable
+	busy = append(busy, connectBtn, testBtn, profileSelect, saveProfileBtn, deleteProfileBtn, backendRadio,
+		serverEntry, dbEntry, userEntry, passEntry, rememberCheck, portEntry, instanceEntry, encryptSelect,
+		trustCheck, connectTimeoutEntry, appNameEntry, sqliteEntry, browseBtn, timeoutSelect, idleSelect)
+
+	// validateAdvanced reports invalid numbers in the advanced options
+	validateAdvanced := func() bool {
+		if backendRadio.Selected != backendMSSQL {
+			return true
+		}
+		for _, entry := range []*widget.Entry{portEntry, connectTimeoutEntry} {
+			if err := entry.Validate(); err != nil {
+				dialog.ShowError(err, d.window)
+				return false
+			}
+		}
+		return true
+	}
+
+	testBtn.OnTapped = func() {
+		if !validateAdvanced() {
+			return
+		}
+		connStr := formProfile().mssqlConnString(passEntry.Text)
+
+		var result *connectionTest
+		d.runInBackground("Проверка подключения", func(ctx context.Context) error {
+			var err error
+			result, err = testMSSQL(ctx, connStr)
+			return err
+		}, func(err error) {
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("Connection Failed:\n%v", err), d.window)
+				return
+			}
+			dialog.ShowInformation("Connection Succeeded", fmt.Sprintf(
+				"Server: %s\nConnect and login: %v\nQuery round trip: %v",
+				result.Version, result.Connect.Round(time.Millisecond), result.RoundTrip.Round(time.Millisecond)), d.window)
+		}, busy...)
+	}
+
+	connectBtn.OnTapped = func() {
+		if !validateAdvanced() {
+			return
+		}
+		if seconds, err := strconv.Atoi(timeoutSelect.Selected); err == nil {
+			prefs.SetInt(queryTimeoutKey, seconds)
+		}
+		minutes, _ := strconv.Atoi(idleSelect.Selected) // Never — 0
+		prefs.SetInt(idleTimeoutKey, minutes)
+
+		profile := formProfile()
+		profile.Name = profileSelect.Selected
+		password := passEntry.Text
+		connStr := profile.connString(password)
+
+		// Connect in the background; the form stays disabled until it finishes
+		// and the spinner below the button shows the attempt
+		d.runInBackground("Подключение к базе данных", func(context.Context) error {
+			return d.connectDB(profile.Backend, connStr)
+		}, func(err error) {
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("Connection Failed:\n%v", err), d.window)
+				return
+			}
+
+			// The selected profile becomes the default of the next start;
+			// a changed password is remembered if asked to
+			if profile.Name != "" {
+				prefs.SetString(lastProfileKey, profile.Name)
+				if rememberCheck.Checked && profile.Backend == backendMSSQL {
+					if err := keys.Set(profile.Name, password); err != nil {
+						log.Printf("Не удалось сохранить пароль профиля %s: %v", profile.Name, err)
+					}
+				}
+			}
+
+			// If successful, bring the schema up to date, sign in and load the main UI
+			d.checkMigrations(d.startSession)
+		}, busy...)
+	}
 	connectBtn.Importance = widget.HighImportance
 
 	// 4. Layout & Styling
+	profileRow := container.NewBorder(nil, nil, widget.NewLabel("Profile"),
+		container.NewHBox(saveProfileBtn, deleteProfileBtn), profileSelect)
+
+	// Set a background image or gradient could go here,
+	// but for now, we set the centered card as content.
+	d.window.SetContent(d.loginCard("Please enter your credentials",
+		profileRow,
+		backendRadio,
+		mssqlForm,
+		sqliteForm,
+		timeoutForm,
+		layout.NewSpacer(), // Pushes button to bottom if resized (though Card fits content)
+		container.NewBorder(nil, nil, nil, testBtn, connectBtn),
+		container.NewCenter(d.activity.content),
+	))
+
+	if err := keys.Check(); err != nil {
+		dialog.ShowError(fmt.Errorf("Saved passwords are not available:\n%v", err), d.window)
+	}
+	notifyCleared()
+}
 
+// loginCard centers the card of the login screen, also used by the lock
+// screen, with a prompt line above the content
+func (d *DatabaseApp) loginCard(prompt string, content ...fyne.CanvasObject) fyne.CanvasObject {
 	// Create an invisible spacer to force the card to be at least 450px wide.
 	// This solves the "text boxes too narrow" issue.
 	widthSpacer := canvas.NewRectangle(color.Transparent)
@@ -75,38 +414,167 @@ func (d *DatabaseApp) showLoginScreen() {
 	// Group elements vertically
 	contentVBox := container.NewVBox(
 		widthSpacer,
-		widget.NewLabelWithStyle("Please enter your credentials", fyne.TextAlignCenter, fyne.TextStyle{Italic: true}),
+		widget.NewLabelWithStyle(prompt, fyne.TextAlignCenter, fyne.TextStyle{Italic: true}),
 		widget.NewSeparator(),
-		form,
-		layout.NewSpacer(), // Pushes button to bottom if resized (though Card fits content)
-		connectBtn,
 	)
+	for _, o := range content {
+		contentVBox.Add(o)
+	}
 
 	// Wrap inside a Card for a nice border and background look
 	loginCard := widget.NewCard(
-		"SQL Server Connection",
+		"Database Connection",
 		"Car Database Manager",
 		container.NewPadded(contentVBox),
 	)
 
 	// Center the card in the middle of the window
-	centeredLayout := container.NewCenter(loginCard)
+	return container.NewCenter(loginCard)
+}
 
-	// Set a background image or gradient could go here,
-	// but for now, we set the centered layout as content.
-	d.window.SetContent(centeredLayout)
+// optionalNumberValidator accepts an empty value or a whole number in [min, max]
+func optionalNumberValidator(name string, min, max int) fyne.StringValidator {
+	return func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if n, err := strconv.Atoi(s); err != nil || n < min || n > max {
+			return fmt.Errorf("%s must be a number from %d to %d", name, min, max)
+		}
+		return nil
+	}
+}
+
+// keyring returns the encrypted password store in the app storage folder
+func (d *DatabaseApp) keyring() *keyring {
+	return newKeyring(d.app.Storage().RootURI().Path())
+}
+
+// checkMigrations offers to apply pending schema migrations, then calls next.
+// The tabs and the sign-in need the current schema: if the user declines, is
+// not an admin or the migrations fail, the connection is closed and the login
+// screen stays.
+func (d *DatabaseApp) checkMigrations(next func()) {
+	migrator, ok := d.repository().(SchemaMigrator)
+	if !ok {
+		next()
+		return
+	}
+
+	var pending []Migration
+	d.runInBackground("Проверка схемы", func(ctx context.Context) error {
+		var err error
+		pending, err = migrator.PendingMigrations(ctx)
+		if err != nil || len(pending) == 0 {
+			return err
+		}
+		return migrator.CheckMigrationRights(ctx)
+	}, func(err error) {
+		if errors.Is(err, errPermissionDenied) {
+			d.closeConnection()
+			d.showMessage("Обновление схемы", "Схема базы данных устарела, а обновить её может только "+
+				"администратор приложения. Подключение закрыто.\n\nОбратитесь к администратору.")
+			return
+		}
+		if err != nil {
+			d.closeConnection()
+			dialog.ShowError(fmt.Errorf("Не удалось проверить версию схемы:\n%v", err), d.window)
+			return
+		}
+		if len(pending) == 0 {
+			next()
+			return
+		}
+		d.offerMigrations(migrator, pending, next)
+	})
+}
+
+// offerMigrations lists the pending migrations and applies them if the user agrees
+func (d *DatabaseApp) offerMigrations(migrator SchemaMigrator, pending []Migration, next func()) {
+
+	var list strings.Builder
+	for _, m := range pending {
+		list.WriteString(fmt.Sprintf("\n  %04d - %s", m.Version, m.Name))
+	}
+
+	message := fmt.Sprintf("Схема базы данных устарела. Будут применены миграции:%s\n\n"+
+		"Если в базе ещё нет пользователей приложения, вы станете её администратором.\n\nПрименить сейчас?", list.String())
+	dialog.ShowConfirm("Обновление схемы", message, func(apply bool) {
+		if !apply {
+			d.closeConnection()
+			d.showMessage("Обновление схемы", "Приложение не может работать со схемой базы данных "+
+				"без этих миграций. Подключение закрыто.\n\nПодключитесь снова и примените миграции "+
+				"или обратитесь к администратору базы данных.")
+			return
+		}
+
+		d.runInBackground("Обновление схемы", func(ctx context.Context) error {
+			return migrator.ApplyMigrations(ctx, pending)
+		}, func(err error) {
+			if err != nil {
+				d.closeConnection()
+				dialog.ShowError(fmt.Errorf("Ошибка обновления схемы:\n%v", err), d.window)
+				return
+			}
+			next()
+		})
+	}, d.window)
+}
+
+// closeConnection closes the database connection, if any
+func (d *DatabaseApp) closeConnection() {
+	if old := d.setRepository(nil); old != nil {
+		old.Close()
+	}
+}
+
+// startSession looks up the role of the user and opens the main window with
+// the tabs of that role
+func (d *DatabaseApp) startSession() {
+	d.runInBackground("Вход в приложение", d.signIn, func(err error) {
+		if err != nil {
+			d.closeConnection()
+			dialog.ShowError(err, d.window)
+			return
+		}
+		d.createUI()
+	})
+}
+
+// defaultSQLitePath suggests a database file inside the app's storage folder
+func (d *DatabaseApp) defaultSQLitePath() string {
+	return filepath.Join(d.app.Storage().RootURI().Path(), "cars.db")
 }
+
 func (d *DatabaseApp) createUI() {
 	// ...
+	searchBar := d.createSearchBar()
+
+	// Переходы задают только созданные вкладки: вкладок, недоступных роли
+	// пользователя, нет, и переходы к ним не выполняются
+	d.openEditForm, d.openBrandLogo, d.openAddCar = nil, nil, nil
+	d.reloadRecycleBin, d.reloadUsers = nil, nil
+
 	tabs := container.NewAppTabs(
 		container.NewTabItemWithIcon("📊 Просмотр", theme.VisibilityIcon(), d.createViewTab()),
-		container.NewTabItemWithIcon("👤 Добавить владельца", theme.ContentAddIcon(), d.createAddOwnerTab()),
-		container.NewTabItemWithIcon("🚗 Добавить автомобиль", theme.ContentAddIcon(), d.createAddCarTab()),
-		container.NewTabItemWithIcon("✏️ Редактирование", theme.DocumentCreateIcon(), d.createEditTab()),
-		container.NewTabItemWithIcon("⚙️ Операции", theme.SettingsIcon(), d.createOperationsTab()),
-		container.NewTabItemWithIcon("🎨 Логотипы", theme.ColorPaletteIcon(), d.createPaintTab()),
-		container.NewTabItemWithIcon("🗑️ Удаление", theme.DeleteIcon(), d.createDeleteTab()),
 	)
+	if d.can(roleOperator) {
+		tabs.Append(container.NewTabItemWithIcon("👤 Добавить владельца", theme.ContentAddIcon(), d.createAddOwnerTab()))
+		tabs.Append(container.NewTabItemWithIcon("🚗 Добавить автомобиль", theme.ContentAddIcon(), d.createAddCarTab()))
+		tabs.Append(container.NewTabItemWithIcon("✏️ Редактирование", theme.DocumentCreateIcon(), d.createEditTab()))
+	}
+	if d.can(roleAdmin) {
+		tabs.Append(container.NewTabItemWithIcon("⚙️ Операции", theme.SettingsIcon(), d.createOperationsTab()))
+		tabs.Append(container.NewTabItemWithIcon("🎨 Логотипы", theme.ColorPaletteIcon(), d.createPaintTab()))
+		tabs.Append(container.NewTabItemWithIcon("🗑️ Удаление", theme.DeleteIcon(), d.createDeleteTab()))
+		tabs.Append(container.NewTabItemWithIcon("♻️ Корзина", theme.ContentUndoIcon(), d.createRecycleBinTab()))
+	}
+	tabs.Append(container.NewTabItemWithIcon("📜 Журнал", theme.HistoryIcon(), d.createAuditTab()))
+	if d.can(roleAdmin) {
+		tabs.Append(container.NewTabItemWithIcon("👥 Пользователи", theme.AccountIcon(), d.createUsersTab()))
+	}
+
+	d.tabs = tabs
 
 	// Настраиваем стиль вкладок
 	tabs.SetTabLocation(container.TabLocationTop)
@@ -114,6 +582,7 @@ func (d *DatabaseApp) createUI() {
 
 	// Обработчик смены вкладок для обновления данных
 	tabs.OnSelected = func(tab *container.TabItem) {
+		d.touchSession()
 		switch tab.Text {
 		case "📊 Просмотр":
 			if scroll, ok := tab.Content.(*container.Scroll); ok {
@@ -140,22 +609,48 @@ func (d *DatabaseApp) createUI() {
 					d.refreshOperationsTab(content)
 				}
 			}
+		case "♻️ Корзина":
+			if d.reloadRecycleBin != nil {
+				d.reloadRecycleBin()
+			}
+		case "📜 Журнал":
+			if d.reloadAudit != nil {
+				d.reloadAudit()
+			}
+		case "👥 Пользователи":
+			if d.reloadUsers != nil {
+				d.reloadUsers()
+			}
 		}
 	}
 
 	// Создаем основной контейнер с отступами
 	mainContainer := container.NewPadded(container.NewMax(tabs))
 
-	// Добавляем футер с информацией
-	footer := widget.NewLabel("База данных автомобилей © 2026 | Подключено к SQL Server")
-	footer.Alignment = fyne.TextAlignCenter
+	// Добавляем футер с информацией и индикатором фоновых операций
+	_, _, user := d.connection()
+	footerLabel := widget.NewLabel(fmt.Sprintf("База данных автомобилей © 2026 | %s (%s)",
+		user.Name, roleTitles[user.Role]))
+	footerLabel.Alignment = fyne.TextAlignCenter
+
+	// Проверка связи с базой данных; состояние показывается слева в футере
+	health := newHealthMonitor(d)
+	if old := d.setMonitor(health); old != nil {
+		old.Stop()
+	}
+	health.Start()
+	footer := container.NewBorder(nil, nil, health.label, d.activity.content, footerLabel)
+
+	// Блокировка по бездействию, Ctrl+L и кнопки блокировки и смены пользователя
+	d.startIdleWatch()
+	searchBar = container.NewBorder(nil, nil, nil, d.lockButtons(), searchBar)
 
 	// Собираем окончательный интерфейс
 	finalContainer := container.NewBorder(
-		nil,    // Верхняя панель
-		footer, // Нижняя панель (футер)
-		nil,    // Левая панель
-		nil,    // Правая панель
+		container.NewPadded(searchBar), // Верхняя панель
+		footer,                         // Нижняя панель (футер)
+		nil,                            // Левая панель
+		nil,                            // Правая панель
 		mainContainer,
 	)
 
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Field validators shared by the add forms and the command-line mode.
+// Empty optional fields are accepted.
+
+func validateFirstName(s string) error {
+	if len(s) < 2 {
+		return fmt.Errorf("имя должно быть не менее 2 символов")
+	}
+	return nil
+}
+
+func validateLastName(s string) error {
+	if len(s) < 2 {
+		return fmt.Errorf("фамилия должна быть не менее 2 символов")
+	}
+	return nil
+}
+
+func validatePhone(s string) error {
+	if s == "" {
+		return nil
+	}
+	if len(s) < 5 {
+		return fmt.Errorf("телефон должен быть не менее 5 символов")
+	}
+	return nil
+}
+
+func validateEmail(s string) error {
+	if s == "" {
+		return nil
+	}
+	if !contains(s, "@") {
+		return fmt.Errorf("email должен содержать @")
+	}
+	return nil
+}
+
+func validateModel(s string) error {
+	if len(s) < 1 {
+		return fmt.Errorf("модель не может быть пустой")
+	}
+	return nil
+}
+
+// minCarYear is the lower bound of the CHECK on cars.year
+const minCarYear = 1900
+
+// validateYear accepts the range of the CHECK on cars.year in SQL Server:
+// from minCarYear to the current year
+func validateYear(s string) error {
+	if s == "" {
+		return nil
+	}
+	year, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("год должен быть числом")
+	}
+	currentYear := time.Now().Year()
+	if year < minCarYear || year > currentYear {
+		return fmt.Errorf("год должен быть между %d и %d", minCarYear, currentYear)
+	}
+	return nil
+}
+
+func validateVIN(s string) error {
+	if s == "" {
+		return nil
+	}
+	if len(s) < 10 {
+		return fmt.Errorf("VIN должен быть не менее 10 символов")
+	}
+	return nil
+}
+
+func validatePrice(s string) error {
+	if s == "" {
+		return nil
+	}
+	price, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("цена должна быть числом")
+	}
+	if price < 0 {
+		return fmt.Errorf("цена не может быть отрицательной")
+	}
+	return nil
+}
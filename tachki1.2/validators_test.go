@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestValidateYear checks the bounds of the CHECK on cars.year
+func TestValidateYear(t *testing.T) {
+	current := time.Now().Year()
+	for _, tt := range []struct {
+		year string
+		ok   bool
+	}{
+		{"", true},
+		{"1899", false},
+		{"1900", true},
+		{strconv.Itoa(current), true},
+		{strconv.Itoa(current + 1), false},
+		{"двадцать", false},
+	} {
+		if err := validateYear(tt.year); (err == nil) != tt.ok {
+			t.Errorf("validateYear(%q) = %v, ожидалось допустимо = %v", tt.year, err, tt.ok)
+		}
+	}
+}
+
+// TestValidateYearSchema checks that the bounds accepted by validateYear are
+// accepted by the schema too
+func TestValidateYearSchema(t *testing.T) {
+	repo := openTestSQLite(t)
+	ctx := context.Background()
+	ownerID, err := repo.AddOwner(ctx, "Иван", "Петров", "", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, year := range []int{minCarYear, time.Now().Year()} {
+		if _, err := repo.AddCar(ctx, ownerID, 1, "Model T", year, "", "VIN000000000000"+strconv.Itoa(i), 1000); err != nil {
+			t.Errorf("год %d: %v", year, err)
+		}
+	}
+	if _, err := repo.AddCar(ctx, ownerID, 1, "Model T", minCarYear-1, "", "VIN0000000000009", 1000); err == nil {
+		t.Errorf("схема приняла год %d", minCarYear-1)
+	}
+}